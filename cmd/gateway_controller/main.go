@@ -18,13 +18,18 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"os"
+	"slices"
+	"strings"
 
 	clusterv1 "open-cluster-management.io/api/cluster/v1"
 	clusterv1beta2 "open-cluster-management.io/api/cluster/v1beta1"
 	workv1 "open-cluster-management.io/api/work/v1"
+	workv1alpha1 "open-cluster-management.io/api/work/v1alpha1"
 
 	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/client-go/dynamic"
@@ -32,15 +37,19 @@ import (
 	"k8s.io/client-go/kubernetes/scheme"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
-	"k8s.io/client-go/tools/cache"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 	gatewayapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayapiv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	certmanv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	kuadrantdnsv1alpha1 "github.com/kuadrant/dns-operator/api/v1alpha1"
 
 	"github.com/Kuadrant/multicluster-gateway-controller/cmd/gateway_controller/ocm"
+	mgcv1alpha1 "github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
 	"github.com/Kuadrant/multicluster-gateway-controller/pkg/controllers/gateway"
 	"github.com/Kuadrant/multicluster-gateway-controller/pkg/placement"
 	"github.com/Kuadrant/multicluster-gateway-controller/pkg/policysync"
@@ -51,15 +60,31 @@ var (
 	metricsAddr          string
 	enableLeaderElection bool
 	probeAddr            string
+	placementStrategy    string
+	clusterSecretNS      string
+	secretDelivery       placement.SecretDelivery
+	workAgentSubject     = placement.DefaultWorkAgentSubject
+)
+
+const (
+	placementManifestWork           = "manifestwork"
+	placementManifestWorkReplicaSet = "manifestworkreplicaset"
+	placementClusterSecret          = "clustersecret"
 )
 
 func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme.Scheme))
 
 	utilruntime.Must(gatewayapiv1.AddToScheme(scheme.Scheme))
+	utilruntime.Must(gatewayapiv1beta1.AddToScheme(scheme.Scheme))
 	utilruntime.Must(clusterv1beta2.AddToScheme(scheme.Scheme))
 	utilruntime.Must(workv1.AddToScheme(scheme.Scheme))
+	utilruntime.Must(workv1alpha1.AddToScheme(scheme.Scheme))
 	utilruntime.Must(clusterv1.AddToScheme(scheme.Scheme))
+	utilruntime.Must(kuadrantdnsv1alpha1.AddToScheme(scheme.Scheme))
+	utilruntime.Must(mgcv1alpha1.AddToScheme(scheme.Scheme))
+	utilruntime.Must(apiextensionsv1.AddToScheme(scheme.Scheme))
+	utilruntime.Must(certmanv1.AddToScheme(scheme.Scheme))
 
 	//+kubebuilder:scaffold:scheme
 }
@@ -70,6 +95,26 @@ func main() {
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.StringVar(&placementStrategy, "placement", placementManifestWork,
+		"How gateways are placed on the spoke clusters. "+
+			"One of \""+placementManifestWork+"\" (a ManifestWork per cluster), \""+placementManifestWorkReplicaSet+"\" (a ManifestWorkReplicaSet per gateway) "+
+			"or \""+placementClusterSecret+"\" (applied directly using Argo CD cluster secrets, without OCM).")
+	flag.StringVar(&clusterSecretNS, "cluster-secret-namespace", "",
+		"The namespace of the Argo CD cluster secrets used by the \""+placementClusterSecret+"\" placement. Defaults to all namespaces.")
+	flag.StringVar((*string)(&secretDelivery.Mode), "secret-delivery", string(placement.SecretDeliveryInline),
+		"How the TLS secrets of the gateways are delivered by the \""+placementManifestWork+"\" and \""+placementManifestWorkReplicaSet+"\" placements. "+
+			"One of \""+string(placement.SecretDeliveryInline)+"\" (embedded in the ManifestWorks), \""+string(placement.SecretDeliveryExclude)+"\" (not delivered) "+
+			"or \""+string(placement.SecretDeliveryExternalSecret)+"\" (an ExternalSecret synced from a secret store on the spokes).")
+	flag.StringVar(&secretDelivery.SecretStoreName, "secret-store-name", "",
+		"The name of the store on the spokes the ExternalSecrets of the \""+string(placement.SecretDeliveryExternalSecret)+"\" secret delivery read from.")
+	flag.StringVar(&secretDelivery.SecretStoreKind, "secret-store-kind", placement.ClusterSecretStoreKind,
+		"The kind of the store the ExternalSecrets read from, "+placement.ClusterSecretStoreKind+" or "+placement.SecretStoreKind+".")
+	flag.StringVar(&secretDelivery.RemoteKeyPrefix, "secret-store-key-prefix", "",
+		"The prefix added to the namespace/name of a secret to get its key in the store the ExternalSecrets read from.")
+	flag.StringVar(&workAgentSubject.Namespace, "work-agent-namespace", workAgentSubject.Namespace,
+		"The namespace of the service account of the OCM work agent on the spokes, which is allowed to manage the placed objects.")
+	flag.StringVar(&workAgentSubject.Name, "work-agent-service-account", workAgentSubject.Name,
+		"The name of the service account of the OCM work agent on the spokes, which is allowed to manage the placed objects.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -94,7 +139,27 @@ func main() {
 		os.Exit(1)
 	}
 
-	placer := placement.NewOCMPlacer(mgr.GetClient())
+	if err := secretDelivery.Validate(); err != nil {
+		setupLog.Error(err, "invalid secret delivery")
+		os.Exit(1)
+	}
+	var placer gateway.GatewayPlacer
+	switch placementStrategy {
+	case placementManifestWork:
+		placer = placement.NewOCMPlacer(mgr.GetClient(), placement.WithSecretDelivery(secretDelivery), placement.WithWorkAgentSubject(workAgentSubject))
+	case placementManifestWorkReplicaSet:
+		placer = placement.NewOCMReplicaSetPlacer(mgr.GetClient(), placement.WithSecretDelivery(secretDelivery), placement.WithWorkAgentSubject(workAgentSubject))
+	case placementClusterSecret:
+		// the secrets are applied to the clusters along with the gateways, so can't be delivered another way
+		if set := setFlags("secret-delivery", "secret-store-name", "secret-store-kind", "secret-store-key-prefix"); len(set) > 0 {
+			setupLog.Error(fmt.Errorf("flags %s are not supported by the %q placement", strings.Join(set, ", "), placementClusterSecret), "invalid secret delivery")
+			os.Exit(1)
+		}
+		placer = placement.NewClusterSecretPlacer(mgr.GetClient(), clusterSecretNS)
+	default:
+		setupLog.Error(fmt.Errorf("unknown placement %q", placementStrategy), "unable to create gateway placer")
+		os.Exit(1)
+	}
 	if err = (&gateway.GatewayClassReconciler{
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
@@ -102,33 +167,65 @@ func main() {
 		setupLog.Error(err, "unable to create controller", "controller", "GatewayClass")
 		os.Exit(1)
 	}
-
-	dynamicClient := dynamic.NewForConfigOrDie(mgr.GetConfig())
-	dynamicInformerFactory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(
-		dynamicClient,
-		0,
-		corev1.NamespaceAll,
-		nil,
-	)
-
-	policyInformersManager := policysync.NewPolicyInformersManager(dynamicInformerFactory)
-	if err := policyInformersManager.SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to start policy informers manager")
+	if err = (&gateway.MultiClusterGatewayParametersReconciler{
+		Client: mgr.GetClient(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "MultiClusterGatewayParameters")
 		os.Exit(1)
 	}
 
+	// policies are synced with ManifestWork so are only synced with OCM placement
+	var policyInformersManager *policysync.PolicyInformersManager
+	var dynamicClient dynamic.Interface
+	if placementStrategy != placementClusterSecret {
+		dynamicClient = dynamic.NewForConfigOrDie(mgr.GetConfig())
+		dynamicInformerFactory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(
+			dynamicClient,
+			0,
+			corev1.NamespaceAll,
+			nil,
+		)
+
+		policyInformersManager = policysync.NewPolicyInformersManager(dynamicInformerFactory)
+		if err := policyInformersManager.SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to start policy informers manager")
+			os.Exit(1)
+		}
+
+		if err = (&policysync.StatusReconciler{
+			Client: mgr.GetClient(),
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "PolicySyncStatus")
+			os.Exit(1)
+		}
+	}
+
 	if err = (&gateway.GatewayReconciler{
 		Client:                 mgr.GetClient(),
 		Scheme:                 mgr.GetScheme(),
 		Placement:              placer,
 		PolicyInformersManager: policyInformersManager,
 		DynamicClient:          dynamicClient,
-		WatchedPolicies:        map[schema.GroupVersionResource]cache.ResourceEventHandlerRegistration{},
+		WatchedPolicies:        map[schema.GroupVersionResource]policysync.PolicyWatch{},
+		WorkAgentSubject:       workAgentSubject,
 	}).SetupWithManager(mgr, ctx); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Gateway")
 		os.Exit(1)
 	}
 
+	// routes are synced with ManifestWork so are only available with OCM placement
+	if placementStrategy != placementClusterSecret {
+		if err = (&gateway.HTTPRouteReconciler{
+			Client:           mgr.GetClient(),
+			Scheme:           mgr.GetScheme(),
+			Placement:        placer,
+			WorkAgentSubject: workAgentSubject,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "HTTPRoute")
+			os.Exit(1)
+		}
+	}
+
 	//+kubebuilder:scaffold:builder
 
 	if err = mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -155,3 +252,14 @@ func main() {
 
 	<-ctx.Done()
 }
+
+// setFlags returns the flags among names that are set on the command line
+func setFlags(names ...string) []string {
+	set := []string{}
+	flag.Visit(func(f *flag.Flag) {
+		if slices.Contains(names, f.Name) {
+			set = append(set, "--"+f.Name)
+		}
+	})
+	return set
+}
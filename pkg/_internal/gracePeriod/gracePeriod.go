@@ -6,6 +6,7 @@ import (
 	"strconv"
 	"time"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
@@ -14,15 +15,56 @@ import (
 
 const (
 	GraceTimestampAnnotation = "kuadrant.io/grace-timeout"
-	DefaultTTL               = 60 //The TTL value here needs to match the one used by the DNSPolicy. This value however will no longer be available to gateway controller packages directly.
-	DefaultGracePeriod       = time.Second * DefaultTTL * 10
+	// GracePeriodAnnotation overrides the grace period for an object, e.g. "10m"
+	GracePeriodAnnotation = "kuadrant.io/grace-period"
+	DefaultTTL            = 60 //The TTL value here needs to match the one used by the DNSPolicy. This value however will no longer be available to gateway controller packages directly.
+	// TTLMultiplier is the number of DNS TTLs to wait before removing an object, so clients have stopped resolving to it
+	TTLMultiplier      = 10
+	DefaultGracePeriod = time.Second * DefaultTTL * TTLMultiplier
 )
 
 var ErrGracePeriodNotExpired = fmt.Errorf("grace period has not yet expired")
 
+// GracePeriodNotExpiredError is returned while the grace period of an object is pending.
+// It is ErrGracePeriodNotExpired and carries the time remaining until the object is deleted
+type GracePeriodNotExpiredError struct {
+	Remaining time.Duration
+}
+
+func (e *GracePeriodNotExpiredError) Error() string {
+	return fmt.Sprintf("%s, %s remaining", ErrGracePeriodNotExpired, e.Remaining.Round(time.Second))
+}
+
+func (e *GracePeriodNotExpiredError) Is(target error) bool {
+	return target == ErrGracePeriodNotExpired
+}
+
+// FromTTL returns the grace period for DNS records with the given TTL in seconds
+func FromTTL(ttl int64) time.Duration {
+	return time.Duration(ttl) * time.Second * TTLMultiplier
+}
+
+// ForObject returns the grace period set by the GracePeriodAnnotation on the object, or the fallback if it is not set
+func ForObject(obj metav1.Object, fallback time.Duration) (time.Duration, error) {
+	value := metadata.GetAnnotation(obj, GracePeriodAnnotation)
+	if value == "" {
+		return fallback, nil
+	}
+	period, err := time.ParseDuration(value)
+	if err != nil || period < 0 {
+		return fallback, fmt.Errorf("invalid %s annotation %q: must be a positive duration", GracePeriodAnnotation, value)
+	}
+	return period, nil
+}
+
 func GracefulDelete(ctx context.Context, c client.Client, obj client.Object, ignoreGrace bool) error {
+	return GracefulDeleteAfter(ctx, c, obj, DefaultGracePeriod, ignoreGrace)
+}
+
+// GracefulDeleteAfter deletes the object once the grace period has passed since it was first called for the object
+func GracefulDeleteAfter(ctx context.Context, c client.Client, obj client.Object, gracePeriod time.Duration, ignoreGrace bool) error {
 	log := log.Log
-	at := time.Now().Add(DefaultGracePeriod)
+	at := time.Now().Add(gracePeriod)
 	if err := c.Get(ctx, client.ObjectKeyFromObject(obj), obj); err != nil {
 		log.V(3).Info("error finding object to graceful delete")
 		return err
@@ -40,7 +82,7 @@ func GracefulDelete(ctx context.Context, c client.Client, obj client.Object, ign
 		if err := c.Update(ctx, obj); err != nil {
 			return err
 		}
-		return ErrGracePeriodNotExpired
+		return &GracePeriodNotExpiredError{Remaining: gracePeriod}
 	}
 	deleteAt, err := strconv.Atoi(obj.GetAnnotations()[GraceTimestampAnnotation])
 	if err != nil {
@@ -49,7 +91,7 @@ func GracefulDelete(ctx context.Context, c client.Client, obj client.Object, ign
 		if err := c.Update(ctx, obj); err != nil {
 			return err
 		}
-		return ErrGracePeriodNotExpired
+		return &GracePeriodNotExpiredError{Remaining: gracePeriod}
 	}
 
 	//grace time reached, delete it
@@ -60,5 +102,5 @@ func GracefulDelete(ctx context.Context, c client.Client, obj client.Object, ign
 
 	log.V(3).Info("grace period still pending")
 
-	return ErrGracePeriodNotExpired
+	return &GracePeriodNotExpiredError{Remaining: time.Until(time.Unix(int64(deleteAt), 0))}
 }
@@ -11,6 +11,7 @@ import (
 
 	workv1 "open-cluster-management.io/api/work/v1"
 
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/scheme"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -70,3 +71,67 @@ func TestGracefulDelete(t *testing.T) {
 		})
 	}
 }
+
+func TestGracefulDeleteAfter(t *testing.T) {
+	work := &workv1.ManifestWork{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "gateway-test-test",
+			Namespace:   "test",
+			Annotations: map[string]string{GraceTimestampAnnotation: fmt.Sprint(time.Now().Add(time.Minute * 5).Unix())},
+		},
+	}
+	fc := fake.NewClientBuilder().WithObjects(work).Build()
+
+	err := GracefulDeleteAfter(context.TODO(), fc, work, time.Minute*10, false)
+	pending := &GracePeriodNotExpiredError{}
+	if !errors.As(err, &pending) {
+		t.Fatalf("expected grace period not expired error, got: %v", err)
+	}
+	if pending.Remaining <= time.Minute*4 || pending.Remaining > time.Minute*5 {
+		t.Fatalf("expected about 5m remaining, got %s", pending.Remaining)
+	}
+
+	if err := GracefulDeleteAfter(context.TODO(), fc, work, 0, false); err != nil {
+		t.Fatalf("expected zero grace period to delete, got: %v", err)
+	}
+	if err := fc.Get(context.TODO(), client.ObjectKeyFromObject(work), &workv1.ManifestWork{}); !k8serrors.IsNotFound(err) {
+		t.Fatalf("expected object to be deleted, got: %v", err)
+	}
+}
+
+func TestForObject(t *testing.T) {
+	testCases := []struct {
+		name        string
+		annotations map[string]string
+		expected    time.Duration
+		expectErr   bool
+	}{
+		{
+			name:     "no annotation uses fallback",
+			expected: DefaultGracePeriod,
+		},
+		{
+			name:        "annotation overrides fallback",
+			annotations: map[string]string{GracePeriodAnnotation: "90s"},
+			expected:    time.Second * 90,
+		},
+		{
+			name:        "invalid annotation",
+			annotations: map[string]string{GracePeriodAnnotation: "soon"},
+			expected:    DefaultGracePeriod,
+			expectErr:   true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			period, err := ForObject(&metav1.ObjectMeta{Annotations: testCase.annotations}, DefaultGracePeriod)
+			if (err != nil) != testCase.expectErr {
+				t.Fatalf("expected error %v, got: %v", testCase.expectErr, err)
+			}
+			if period != testCase.expected {
+				t.Fatalf("expected grace period %s, got %s", testCase.expected, period)
+			}
+		})
+	}
+}
@@ -99,6 +99,26 @@ func KeyPredicate(predicate func(key string) bool) AnnotationPredicate {
 	}
 }
 
+// PrefixPredicate returns a predicate fulfilled by the keys that start with one of
+// the allowed prefixes, or by any key when no prefixes are allowed, and do not
+// start with one of the denied prefixes
+func PrefixPredicate(allow, deny []string) AnnotationPredicate {
+	hasPrefix := func(key string, prefixes []string) bool {
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(key, prefix) {
+				return true
+			}
+		}
+		return false
+	}
+	return KeyPredicate(func(key string) bool {
+		if hasPrefix(key, deny) {
+			return false
+		}
+		return len(allow) == 0 || hasPrefix(key, allow)
+	})
+}
+
 // CopyAnnotation copies an annotation with key `key` from `fromObj` into `toObj`
 // Returns `true` if the annotation was found and copied, `false` otherwise
 func CopyAnnotation(fromObj, toObj metav1.Object, key string) bool {
@@ -63,3 +63,30 @@ func RemoveLabel(obj metav1.Object, key string) {
 		}
 	}
 }
+
+// CopyLabelsPredicate copies any label from fromObj into toObj labels that
+// fullfils the given predicate. Returns true if at least one label was copied
+func CopyLabelsPredicate(fromObj, toObj metav1.Object, predicate AnnotationPredicate) bool {
+	fromObjLabels := fromObj.GetLabels()
+	if fromObjLabels == nil {
+		return false
+	}
+
+	toObjLabels := toObj.GetLabels()
+	if toObjLabels == nil {
+		toObjLabels = map[string]string{}
+		toObj.SetLabels(toObjLabels)
+	}
+
+	copied := false
+	for key, value := range fromObjLabels {
+		if !predicate(key, value) {
+			continue
+		}
+
+		toObjLabels[key] = value
+		copied = true
+	}
+
+	return copied
+}
@@ -53,3 +53,61 @@ func Test_hasLabel(t *testing.T) {
 		})
 	}
 }
+
+func Test_copyLabelsPredicate(t *testing.T) {
+	testCases := []struct {
+		name      string
+		allow     []string
+		deny      []string
+		expect    map[string]string
+		expectAny bool
+	}{
+		{
+			name:      "no prefixes copies every label",
+			expect:    map[string]string{"app": "web", "kuadrant.io/managed": "true", "clusters.kuadrant.io/c1_lb": "x"},
+			expectAny: true,
+		},
+		{
+			name:      "denied prefixes are not copied",
+			deny:      []string{"clusters.kuadrant.io/"},
+			expect:    map[string]string{"app": "web", "kuadrant.io/managed": "true"},
+			expectAny: true,
+		},
+		{
+			name:      "only allowed prefixes are copied",
+			allow:     []string{"kuadrant.io/", "clusters.kuadrant.io/"},
+			deny:      []string{"clusters.kuadrant.io/"},
+			expect:    map[string]string{"kuadrant.io/managed": "true"},
+			expectAny: true,
+		},
+		{
+			name:   "nothing allowed",
+			allow:  []string{"example.com/"},
+			expect: map[string]string{},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			from := &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "from",
+					Labels: map[string]string{"app": "web", "kuadrant.io/managed": "true", "clusters.kuadrant.io/c1_lb": "x"},
+				},
+			}
+			to := &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "to"}}
+			copied := CopyLabelsPredicate(from, to, PrefixPredicate(testCase.allow, testCase.deny))
+			if copied != testCase.expectAny {
+				t.Errorf("expected copied to be '%v' got '%v'", testCase.expectAny, copied)
+			}
+			if len(to.Labels) != len(testCase.expect) {
+				t.Fatalf("expected labels %v got %v", testCase.expect, to.Labels)
+			}
+			for key, value := range testCase.expect {
+				if to.Labels[key] != value {
+					t.Errorf("expected label '%s' to be '%s' got '%s'", key, value, to.Labels[key])
+				}
+			}
+		})
+	}
+}
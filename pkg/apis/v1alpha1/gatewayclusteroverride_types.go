@@ -0,0 +1,90 @@
+/*
+Copyright 2022 The MultiCluster Traffic Controller Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gatewayapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// ListenerOverride overrides a listener of the gateway, matched by its name
+type ListenerOverride struct {
+	// Name of the listener to override
+	// +required
+	Name gatewayapiv1.SectionName `json:"name"`
+
+	// Hostname of the listener on the selected clusters
+	// +optional
+	Hostname *gatewayapiv1.Hostname `json:"hostname,omitempty"`
+}
+
+// GatewayClusterOverrideSpec defines the fields of a gateway overridden on the
+// clusters it is placed on
+type GatewayClusterOverrideSpec struct {
+	// GatewayName is the name of the gateway, in the namespace of the override,
+	// the overrides apply to
+	// +kubebuilder:validation:MinLength=1
+	// +required
+	GatewayName string `json:"gatewayName"`
+
+	// ClusterSelector selects the clusters the overrides apply to by the labels
+	// and ClusterClaims of their ManagedCluster. Every cluster is selected when
+	// not set
+	// +optional
+	ClusterSelector *metav1.LabelSelector `json:"clusterSelector,omitempty"`
+
+	// Addresses requested for the gateway on the selected clusters, replacing
+	// those of the gateway
+	// +optional
+	Addresses []gatewayapiv1.GatewayAddress `json:"addresses,omitempty"`
+
+	// Infrastructure labels and annotations added to the gateway on the
+	// selected clusters, such as the annotations of a cloud load balancer
+	// +optional
+	Infrastructure *gatewayapiv1.GatewayInfrastructure `json:"infrastructure,omitempty"`
+
+	// Listeners overridden on the selected clusters
+	// +optional
+	Listeners []ListenerOverride `json:"listeners,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:shortName=gwoverride
+//+kubebuilder:printcolumn:name="Gateway",type="string",JSONPath=".spec.gatewayName",description="Gateway the overrides apply to"
+
+// GatewayClusterOverride overrides fields of a gateway on the clusters it is
+// placed on. Overrides are applied in the order of their names, so later
+// overrides take precedence
+type GatewayClusterOverride struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec GatewayClusterOverrideSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// GatewayClusterOverrideList contains a list of GatewayClusterOverride
+type GatewayClusterOverrideList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []GatewayClusterOverride `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&GatewayClusterOverride{}, &GatewayClusterOverrideList{})
+}
@@ -0,0 +1,171 @@
+/*
+Copyright 2022 The MultiCluster Traffic Controller Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// NamespaceMappingStrategy is how the namespace of a gateway on the spokes is
+// derived from its namespace on the hub
+// +kubebuilder:validation:Enum=Same;Prefixed;Template
+type NamespaceMappingStrategy string
+
+const (
+	// NamespaceMappingSame places the gateway in the same namespace as on the hub
+	NamespaceMappingSame NamespaceMappingStrategy = "Same"
+	// NamespaceMappingPrefixed places the gateway in the hub namespace prefixed with the mapping prefix
+	NamespaceMappingPrefixed NamespaceMappingStrategy = "Prefixed"
+	// NamespaceMappingTemplate places the gateway in the namespace rendered from the mapping template
+	NamespaceMappingTemplate NamespaceMappingStrategy = "Template"
+)
+
+// NamespaceMapping configures the namespace the gateways of a class are placed
+// in on the spokes
+type NamespaceMapping struct {
+	// Strategy used to derive the spoke namespace from the hub namespace
+	// +kubebuilder:default=Prefixed
+	// +optional
+	Strategy NamespaceMappingStrategy `json:"strategy,omitempty"`
+
+	// Prefix added to the hub namespace by the Prefixed strategy, separated by a "-"
+	// +kubebuilder:default=kuadrant
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+
+	// Template rendered by the Template strategy with the Namespace and Name of the
+	// gateway on the hub, e.g. "{{ .Namespace }}-gateways"
+	// +optional
+	Template string `json:"template,omitempty"`
+}
+
+// MetadataPropagation filters the labels or annotations of the gateways that are
+// propagated to the spokes by their key
+type MetadataPropagation struct {
+	// AllowPrefixes are the prefixes of the keys that are propagated. Every key
+	// is propagated when empty
+	// +optional
+	AllowPrefixes []string `json:"allowPrefixes,omitempty"`
+
+	// DenyPrefixes are the prefixes of the keys that are not propagated, taking
+	// precedence over AllowPrefixes. When not set the keys used by the hub to
+	// place the gateways are not propagated
+	// +optional
+	DenyPrefixes []string `json:"denyPrefixes,omitempty"`
+}
+
+// PolicyGroupVersionResource identifies a policy resource synced to the spokes
+type PolicyGroupVersionResource struct {
+	// +required
+	Group string `json:"group"`
+	// +required
+	Version string `json:"version"`
+	// +required
+	Resource string `json:"resource"`
+}
+
+// MultiClusterGatewayParametersSpec defines the parameters of the gateways of the
+// GatewayClasses that reference it
+type MultiClusterGatewayParametersSpec struct {
+	// DownstreamClass is the GatewayClassName set on the gateways in the spokes
+	// +kubebuilder:default=istio
+	// +kubebuilder:validation:MinLength=1
+	// +optional
+	DownstreamClass string `json:"downstreamClass,omitempty"`
+
+	// PoliciesToSync are the policy resources watched in the hub and synced to
+	// the spokes the gateways they target are placed on
+	// +optional
+	PoliciesToSync []PolicyGroupVersionResource `json:"policiesToSync,omitempty"`
+
+	// RolloutBatch is the number, or percentage, of clusters gateway changes are
+	// rolled out to at a time. For example: 1 or "25%"
+	// +kubebuilder:validation:XIntOrString
+	// +kubebuilder:validation:Pattern=`^([1-9][0-9]*|[1-9][0-9]?%|100%)$`
+	// +optional
+	RolloutBatch *intstr.IntOrString `json:"rolloutBatch,omitempty"`
+
+	// GracePeriod is how long a gateway is kept on a spoke after it is no longer
+	// targeted. For example: "10m"
+	// +optional
+	GracePeriod *metav1.Duration `json:"gracePeriod,omitempty"`
+
+	// GracePeriodFromDNSTTL derives the grace period from the TTL of the
+	// DNSRecords of the gateway DNSPolicy
+	// +optional
+	GracePeriodFromDNSTTL bool `json:"gracePeriodFromDNSTTL,omitempty"`
+
+	// NamespaceMapping configures the namespace the gateways are placed in on the spokes
+	// +optional
+	NamespaceMapping *NamespaceMapping `json:"namespaceMapping,omitempty"`
+
+	// PropagatedLabels filters the labels of the gateways propagated to the spokes
+	// +optional
+	PropagatedLabels *MetadataPropagation `json:"propagatedLabels,omitempty"`
+
+	// PropagatedAnnotations filters the annotations of the gateways propagated to the spokes
+	// +optional
+	PropagatedAnnotations *MetadataPropagation `json:"propagatedAnnotations,omitempty"`
+
+	// ClusterIssuer is the name of the cert-manager ClusterIssuer that issues the
+	// certificates of the HTTPS listeners of the gateways. Gateways can override
+	// it with the kuadrant.io/gateway-cluster-issuer annotation
+	// +optional
+	ClusterIssuer string `json:"clusterIssuer,omitempty"`
+}
+
+// MultiClusterGatewayParametersStatus defines the observed state of MultiClusterGatewayParameters
+type MultiClusterGatewayParametersStatus struct {
+	// GatewayClasses are the names of the GatewayClasses that reference these parameters
+	// +optional
+	GatewayClasses []string `json:"gatewayClasses,omitempty"`
+
+	// observedGeneration is the most recently observed generation of the
+	// MultiClusterGatewayParameters.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:shortName=mgcparams
+//+kubebuilder:printcolumn:name="Downstream Class",type="string",JSONPath=".spec.downstreamClass",description="GatewayClassName of the gateways in the spokes"
+//+kubebuilder:printcolumn:name="Gateway Classes",type="string",JSONPath=".status.gatewayClasses",description="GatewayClasses using these parameters"
+
+// MultiClusterGatewayParameters is the Schema for the GatewayClass parameters of the multi-cluster gateway controller
+type MultiClusterGatewayParameters struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   MultiClusterGatewayParametersSpec   `json:"spec,omitempty"`
+	Status MultiClusterGatewayParametersStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// MultiClusterGatewayParametersList contains a list of MultiClusterGatewayParameters
+type MultiClusterGatewayParametersList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MultiClusterGatewayParameters `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&MultiClusterGatewayParameters{}, &MultiClusterGatewayParametersList{})
+}
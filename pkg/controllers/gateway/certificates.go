@@ -0,0 +1,167 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	crlog "sigs.k8s.io/controller-runtime/pkg/log"
+	gatewayapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	certmanv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/slice"
+)
+
+const (
+	// GatewayClusterIssuerAnnotation overrides the ClusterIssuer of the params that issues the
+	// certificates of the HTTPS listeners of the gateway
+	GatewayClusterIssuerAnnotation = LabelPrefix + "gateway-cluster-issuer"
+	// CertificateGatewayLabel labels the Certificates issued for the listeners of a gateway with
+	// the name of the gateway
+	CertificateGatewayLabel = LabelPrefix + "certificate-gateway"
+)
+
+// clusterIssuer returns the name of the ClusterIssuer that issues the certificates of the
+// gateway listeners, if any. The gateway annotation takes precedence over the params
+func clusterIssuer(gateway *gatewayapiv1.Gateway, params *Params) string {
+	if issuer, ok := gateway.GetAnnotations()[GatewayClusterIssuerAnnotation]; ok {
+		return issuer
+	}
+	if params == nil {
+		return ""
+	}
+	return params.ClusterIssuer
+}
+
+// listenerCertificates returns the Certificates issued by the ClusterIssuer for the HTTPS
+// listeners of the gateway. A Certificate is issued for each secret referenced by the
+// listeners in the namespace of the gateway, for the hostnames of the listeners referencing it
+func listenerCertificates(gateway *gatewayapiv1.Gateway, issuer string) []*certmanv1.Certificate {
+	certificates := map[string]*certmanv1.Certificate{}
+	for _, listener := range gateway.Spec.Listeners {
+		if listener.Protocol != gatewayapiv1.HTTPSProtocolType || listener.Hostname == nil || listener.TLS == nil {
+			continue
+		}
+		if listener.TLS.Mode != nil && *listener.TLS.Mode != gatewayapiv1.TLSModeTerminate {
+			continue
+		}
+		for _, secretRef := range listener.TLS.CertificateRefs {
+			// cert-manager writes the secret in the namespace of the certificate
+			if secretRef.Namespace != nil && string(*secretRef.Namespace) != gateway.Namespace {
+				continue
+			}
+			certificate, ok := certificates[string(secretRef.Name)]
+			if !ok {
+				certificate = &certmanv1.Certificate{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      string(secretRef.Name),
+						Namespace: gateway.Namespace,
+						Labels:    map[string]string{CertificateGatewayLabel: gateway.Name},
+					},
+					Spec: certmanv1.CertificateSpec{
+						SecretName: string(secretRef.Name),
+						IssuerRef: cmmeta.ObjectReference{
+							Name:  issuer,
+							Kind:  certmanv1.ClusterIssuerKind,
+							Group: certmanv1.SchemeGroupVersion.Group,
+						},
+					},
+				}
+				certificates[string(secretRef.Name)] = certificate
+			}
+			hostname := string(*listener.Hostname)
+			if !slice.ContainsString(certificate.Spec.DNSNames, hostname) {
+				certificate.Spec.DNSNames = append(certificate.Spec.DNSNames, hostname)
+			}
+		}
+	}
+
+	result := make([]*certmanv1.Certificate, 0, len(certificates))
+	for _, certificate := range certificates {
+		result = append(result, certificate)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// reconcileCertificates ensures the Certificates of the HTTPS listeners of the gateway are
+// issued by the ClusterIssuer configured for it, and removes the Certificates of the listeners
+// that no longer exist. It returns the names of the secrets that are not issued yet
+func (r *GatewayReconciler) reconcileCertificates(ctx context.Context, gateway *gatewayapiv1.Gateway, params *Params) ([]string, error) {
+	log := crlog.FromContext(ctx)
+	issuer := clusterIssuer(gateway, params)
+	desired := []*certmanv1.Certificate{}
+	if issuer != "" {
+		desired = listenerCertificates(gateway, issuer)
+	}
+
+	pending := []string{}
+	desiredNames := map[string]bool{}
+	for _, certificate := range desired {
+		desiredNames[certificate.Name] = true
+		if err := controllerutil.SetControllerReference(gateway, certificate, r.Scheme); err != nil {
+			return nil, err
+		}
+		existing := &certmanv1.Certificate{}
+		err := r.Client.Get(ctx, client.ObjectKeyFromObject(certificate), existing)
+		if apierrors.IsNotFound(err) {
+			log.Info("creating listener certificate", "certificate", certificate.Name, "issuer", issuer)
+			if err := r.Client.Create(ctx, certificate); err != nil {
+				return nil, err
+			}
+		} else if err != nil {
+			return nil, err
+		} else if existing.Labels[CertificateGatewayLabel] != gateway.Name {
+			return nil, fmt.Errorf("certificate %s/%s already exists and is not managed by gateway %s", existing.Namespace, existing.Name, gateway.Name)
+		} else if !equality.Semantic.DeepEqual(existing.Spec, certificate.Spec) || !equality.Semantic.DeepEqual(existing.OwnerReferences, certificate.OwnerReferences) {
+			existing.Spec = certificate.Spec
+			existing.OwnerReferences = certificate.OwnerReferences
+			if err := r.Client.Update(ctx, existing); err != nil {
+				return nil, err
+			}
+		}
+
+		// the secret is placed with the gateway once cert-manager has issued it
+		secret := &corev1.Secret{}
+		err = r.Client.Get(ctx, client.ObjectKey{Namespace: certificate.Namespace, Name: certificate.Spec.SecretName}, secret)
+		if apierrors.IsNotFound(err) || (err == nil && len(secret.Data[corev1.TLSCertKey]) == 0) {
+			pending = append(pending, certificate.Spec.SecretName)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// garbage collect the certificates of the listeners that were removed
+	existing := &certmanv1.CertificateList{}
+	err := r.Client.List(ctx, existing, client.InNamespace(gateway.Namespace), client.MatchingLabels{CertificateGatewayLabel: gateway.Name})
+	if (meta.IsNoMatchError(err) || runtime.IsNotRegisteredError(err)) && issuer == "" {
+		// cert-manager is not installed and not needed by the gateway
+		return pending, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for i := range existing.Items {
+		certificate := &existing.Items[i]
+		if desiredNames[certificate.Name] {
+			continue
+		}
+		log.Info("deleting listener certificate", "certificate", certificate.Name)
+		if err := r.Client.Delete(ctx, certificate); client.IgnoreNotFound(err) != nil {
+			return nil, err
+		}
+	}
+	return pending, nil
+}
@@ -0,0 +1,144 @@
+//go:build unit
+
+package gateway
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	gatewayapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	certmanv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+
+	testutil "github.com/Kuadrant/multicluster-gateway-controller/test/util"
+)
+
+func httpsListener(name, hostname, secret string) gatewayapiv1.Listener {
+	return gatewayapiv1.Listener{
+		Name:     gatewayapiv1.SectionName(name),
+		Hostname: testutil.Pointer(gatewayapiv1.Hostname(hostname)),
+		Port:     443,
+		Protocol: gatewayapiv1.HTTPSProtocolType,
+		TLS: &gatewayapiv1.GatewayTLSConfig{
+			Mode:            testutil.Pointer(gatewayapiv1.TLSModeTerminate),
+			CertificateRefs: []gatewayapiv1.SecretObjectReference{{Name: gatewayapiv1.ObjectName(secret)}},
+		},
+	}
+}
+
+func TestReconcileCertificates(t *testing.T) {
+	scheme := testutil.GetValidTestScheme()
+	gateway := &gatewayapiv1.Gateway{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-gw",
+			Namespace: testutil.Namespace,
+			UID:       "test-uid",
+		},
+		Spec: gatewayapiv1.GatewaySpec{
+			GatewayClassName: testutil.MultiClusterGatewayClassName,
+			Listeners: []gatewayapiv1.Listener{
+				httpsListener("api", "api.example.com", "api-tls"),
+				httpsListener("api-www", "www.example.com", "api-tls"),
+				httpsListener("other", "other.example.com", "other-tls"),
+				{Name: "http", Hostname: testutil.Pointer(gatewayapiv1.Hostname("api.example.com")), Port: 80, Protocol: gatewayapiv1.HTTPProtocolType},
+			},
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(gateway).Build()
+	r := &GatewayReconciler{Client: c, Scheme: scheme}
+	params := &Params{ClusterIssuer: "letsencrypt"}
+
+	// a certificate is issued for each secret, for the hostnames of the listeners referencing it
+	pending, err := r.reconcileCertificates(context.TODO(), gateway, params)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	if !reflect.DeepEqual(pending, []string{"api-tls", "other-tls"}) {
+		t.Fatalf("expected both secrets to be pending got %v", pending)
+	}
+	certificate := &certmanv1.Certificate{}
+	if err := c.Get(context.TODO(), client.ObjectKey{Namespace: testutil.Namespace, Name: "api-tls"}, certificate); err != nil {
+		t.Fatalf("expected certificate to be created: %s", err)
+	}
+	if !reflect.DeepEqual(certificate.Spec.DNSNames, []string{"api.example.com", "www.example.com"}) {
+		t.Fatalf("expected certificate for the listener hostnames got %v", certificate.Spec.DNSNames)
+	}
+	if certificate.Spec.SecretName != "api-tls" || certificate.Spec.IssuerRef.Name != "letsencrypt" || certificate.Spec.IssuerRef.Kind != certmanv1.ClusterIssuerKind {
+		t.Fatalf("expected certificate issued by the cluster issuer into the listener secret got %v", certificate.Spec)
+	}
+	if len(certificate.OwnerReferences) != 1 || certificate.OwnerReferences[0].UID != gateway.UID {
+		t.Fatalf("expected certificate to be owned by the gateway got %v", certificate.OwnerReferences)
+	}
+
+	// the secrets issued are no longer pending
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "api-tls", Namespace: testutil.Namespace},
+		Data:       map[string][]byte{corev1.TLSCertKey: []byte("cert"), corev1.TLSPrivateKeyKey: []byte("key")},
+	}
+	if err := c.Create(context.TODO(), secret); err != nil {
+		t.Fatal(err)
+	}
+	pending, err = r.reconcileCertificates(context.TODO(), gateway, params)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	if !reflect.DeepEqual(pending, []string{"other-tls"}) {
+		t.Fatalf("expected other-tls to be pending got %v", pending)
+	}
+
+	// the certificates of the listeners removed are deleted
+	gateway.Spec.Listeners = gateway.Spec.Listeners[:1]
+	pending, err = r.reconcileCertificates(context.TODO(), gateway, params)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	if len(pending) != 0 {
+		t.Fatalf("expected no pending secrets got %v", pending)
+	}
+	if err := c.Get(context.TODO(), client.ObjectKey{Namespace: testutil.Namespace, Name: "other-tls"}, certificate); err == nil {
+		t.Fatalf("expected certificate other-tls to be deleted")
+	}
+	if err := c.Get(context.TODO(), client.ObjectKey{Namespace: testutil.Namespace, Name: "api-tls"}, certificate); err != nil {
+		t.Fatalf("expected certificate api-tls to be kept: %s", err)
+	}
+	if !reflect.DeepEqual(certificate.Spec.DNSNames, []string{"api.example.com"}) {
+		t.Fatalf("expected certificate to be updated with the listener hostnames got %v", certificate.Spec.DNSNames)
+	}
+
+	// certificates not issued for the gateway are not taken over
+	gateway.Spec.Listeners = append(gateway.Spec.Listeners, httpsListener("user", "user.example.com", "user-tls"))
+	if err := c.Create(context.TODO(), &certmanv1.Certificate{ObjectMeta: metav1.ObjectMeta{Name: "user-tls", Namespace: testutil.Namespace}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.reconcileCertificates(context.TODO(), gateway, params); err == nil {
+		t.Fatalf("expected an error for a certificate not managed by the gateway")
+	}
+
+	// the certificates are deleted when no cluster issuer is configured
+	gateway.Spec.Listeners = gateway.Spec.Listeners[:1]
+	if _, err := r.reconcileCertificates(context.TODO(), gateway, &defaultParams); err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	if err := c.Get(context.TODO(), client.ObjectKey{Namespace: testutil.Namespace, Name: "api-tls"}, certificate); err == nil {
+		t.Fatalf("expected certificate api-tls to be deleted")
+	}
+}
+
+func TestClusterIssuer(t *testing.T) {
+	gateway := &gatewayapiv1.Gateway{ObjectMeta: metav1.ObjectMeta{Name: "test-gw", Namespace: testutil.Namespace}}
+	if issuer := clusterIssuer(gateway, nil); issuer != "" {
+		t.Fatalf("expected no issuer without params got %s", issuer)
+	}
+	if issuer := clusterIssuer(gateway, &Params{ClusterIssuer: "letsencrypt"}); issuer != "letsencrypt" {
+		t.Fatalf("expected issuer of the params got %s", issuer)
+	}
+	gateway.Annotations = map[string]string{GatewayClusterIssuerAnnotation: "staging"}
+	if issuer := clusterIssuer(gateway, &Params{ClusterIssuer: "letsencrypt"}); issuer != "staging" {
+		t.Fatalf("expected issuer of the annotation got %s", issuer)
+	}
+}
@@ -2,10 +2,12 @@ package gateway
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -15,7 +17,9 @@ import (
 	gatewayapiv1 "sigs.k8s.io/gateway-api/apis/v1"
 
 	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/clusterSecret"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/metadata"
 	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/slice"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/placement"
 )
 
 type ClusterEventHandler struct {
@@ -49,40 +53,38 @@ func (eh *ClusterEventHandler) enqueueForObject(ctx context.Context, obj v1.Obje
 		return
 	}
 
-	gateways, err := eh.getGatewaysFor(ctx, obj.(*corev1.Secret))
+	// gateways selecting, or placed on, the cluster may need to be placed or removed
+	selecting, err := eh.getGatewaysSelecting(ctx, obj.(*corev1.Secret))
 	if err != nil {
-		log.Log.Error(err, "failed to get gateways when enqueueing from cluster secret")
+		log.Log.Error(err, "failed to get gateways selecting cluster secret")
 		return
 	}
-
-	for _, gateway := range gateways {
-		log.Log.Info(fmt.Sprintf("Enqueing reconciliation from secret update to gateway/%s", gateway.Name))
+	for _, gateway := range selecting {
+		log.Log.Info(fmt.Sprintf("Enqueing reconciliation from cluster secret update to gateway/%s", gateway.Name))
 		q.Add(ctrl.Request{
 			NamespacedName: client.ObjectKeyFromObject(&gateway),
 		})
 	}
 }
 
-func (eh *ClusterEventHandler) getGatewaysFor(ctx context.Context, secret *corev1.Secret) ([]gatewayapiv1.Gateway, error) {
-
+func (eh *ClusterEventHandler) getGatewaysSelecting(ctx context.Context, secret *corev1.Secret) ([]gatewayapiv1.Gateway, error) {
 	gateways := &gatewayapiv1.GatewayList{}
-	if err := eh.client.List(ctx, gateways, &client.ListOptions{Namespace: secret.Namespace}); err != nil {
+	if err := eh.client.List(ctx, gateways); err != nil {
 		return nil, err
 	}
 
+	clusterName := string(secret.Data["name"])
 	return slice.Filter(gateways.Items, func(gateway gatewayapiv1.Gateway) bool {
-		for _, l := range gateway.Spec.Listeners {
-			if l.Protocol != gatewayapiv1.HTTPSProtocolType || l.TLS == nil {
-				continue
-			}
-
-			for _, ts := range l.TLS.CertificateRefs {
-				if ts.Name == gatewayapiv1.ObjectName(secret.Name) && *ts.Namespace == gatewayapiv1.Namespace(secret.Namespace) {
-					return true
-				}
+		if selector := metadata.GetAnnotation(&gateway, GatewayClusterLabelSelectorAnnotation); selector != "" {
+			labelSelector, err := placement.ParseClusterSelector(selector)
+			if err == nil && labelSelector.Matches(labels.Set(secret.GetLabels())) {
+				return true
 			}
-
 		}
-		return false
+		var clusters []string
+		if err := json.Unmarshal([]byte(metadata.GetAnnotation(&gateway, GatewayClustersAnnotation)), &clusters); err != nil {
+			return false
+		}
+		return clusterName != "" && slice.ContainsString(clusters, clusterName)
 	}), nil
 }
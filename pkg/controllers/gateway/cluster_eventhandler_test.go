@@ -82,6 +82,7 @@ func TestClusterEventHandler(t *testing.T) {
 				ObjectMeta: metav1.ObjectMeta{
 					Labels: map[string]string{
 						clusterSecret.CLUSTER_SECRET_LABEL: clusterSecret.CLUSTER_SECRET_LABEL_VALUE,
+						"type":                             "test",
 					},
 					Name:      testutil.ValidTestHostname,
 					Namespace: testutil.Namespace,
@@ -100,6 +101,67 @@ func TestClusterEventHandler(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:   "Queued gateway selecting the cluster",
+			scheme: testutil.GetValidTestScheme(),
+			gateways: []gatewayapiv1.Gateway{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-selecting-gateway",
+						Namespace: testutil.Namespace,
+						Annotations: map[string]string{
+							GatewayClusterLabelSelectorAnnotation: "region=eu",
+						},
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-placed-gateway",
+						Namespace: testutil.Namespace,
+						Annotations: map[string]string{
+							GatewayClustersAnnotation: `["` + testutil.Cluster + `"]`,
+						},
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-other-gateway",
+						Namespace: testutil.Namespace,
+						Annotations: map[string]string{
+							GatewayClusterLabelSelectorAnnotation: "region=us",
+						},
+					},
+				},
+			},
+			secret: corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						clusterSecret.CLUSTER_SECRET_LABEL: clusterSecret.CLUSTER_SECRET_LABEL_VALUE,
+						"region":                           "eu",
+					},
+					Name:      "cluster",
+					Namespace: testutil.Namespace,
+				},
+				Data: map[string][]byte{
+					"name":   []byte(testutil.Cluster),
+					"config": []byte(tlsConfig),
+				},
+			},
+			enqueuedGateways: []gatewayapiv1.Gateway{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-selecting-gateway",
+						Namespace: testutil.Namespace,
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-placed-gateway",
+						Namespace: testutil.Namespace,
+					},
+				},
+			},
+		},
 		{
 			name:     "Not enqueued. Not a cluster secret",
 			scheme:   testutil.GetValidTestScheme(),
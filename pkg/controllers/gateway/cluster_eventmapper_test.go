@@ -0,0 +1,93 @@
+//go:build unit
+
+package gateway
+
+import (
+	"context"
+	"testing"
+
+	clusterv1 "open-cluster-management.io/api/cluster/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	crlog "sigs.k8s.io/controller-runtime/pkg/log"
+	gatewayapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	testutil "github.com/Kuadrant/multicluster-gateway-controller/test/util"
+)
+
+func TestClusterEventMapper_MapToGateway(t *testing.T) {
+	gateway := func(name string, annotations map[string]string) gatewayapiv1.Gateway {
+		return gatewayapiv1.Gateway{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   testutil.Namespace,
+				Annotations: annotations,
+			},
+		}
+	}
+	gateways := []gatewayapiv1.Gateway{
+		gateway("selected", map[string]string{GatewayClusterLabelSelectorAnnotation: "region=eu"}),
+		gateway("not-selected", map[string]string{GatewayClusterLabelSelectorAnnotation: "region=us"}),
+		gateway("placed", map[string]string{
+			GatewayClusterLabelSelectorAnnotation: "region=us",
+			GatewayClustersAnnotation:             `["test-cluster"]`,
+		}),
+		gateway("invalid", map[string]string{GatewayClusterLabelSelectorAnnotation: "region in eu"}),
+	}
+
+	cases := []struct {
+		name     string
+		cluster  *clusterv1.ManagedCluster
+		expected []string
+	}{
+		{
+			name: "gateways selecting or placed on the cluster are enqueued",
+			cluster: &clusterv1.ManagedCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "test-cluster",
+					Labels: map[string]string{"region": "eu"},
+				},
+			},
+			expected: []string{"selected", "placed"},
+		},
+		{
+			name: "deleting cluster is ignored",
+			cluster: &clusterv1.ManagedCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "test-cluster",
+					Labels:            map[string]string{"region": "eu"},
+					DeletionTimestamp: testutil.GetTime(),
+				},
+			},
+			expected: []string{},
+		},
+	}
+
+	for _, testCase := range cases {
+		t.Run(testCase.name, func(t *testing.T) {
+			client := fake.NewClientBuilder().WithScheme(testutil.GetValidTestScheme()).WithLists(
+				&gatewayapiv1.GatewayList{
+					Items: gateways,
+				},
+			).Build()
+
+			mapper := NewClusterEventMapper(crlog.Log, client)
+			requests := mapper.MapToGateway(context.Background(), testCase.cluster)
+
+			got := map[types.NamespacedName]bool{}
+			for _, r := range requests {
+				got[r.NamespacedName] = true
+			}
+			if len(got) != len(testCase.expected) {
+				t.Fatalf("expected %v to be enqueued, got %v", testCase.expected, requests)
+			}
+			for _, name := range testCase.expected {
+				if !got[types.NamespacedName{Namespace: testutil.Namespace, Name: name}] {
+					t.Errorf("expected gateway %s to be enqueued, got %v", name, requests)
+				}
+			}
+		})
+	}
+}
@@ -28,10 +28,13 @@ import (
 	clusterv1 "open-cluster-management.io/api/cluster/v1"
 	clusterv1beta2 "open-cluster-management.io/api/cluster/v1beta1"
 	workv1 "open-cluster-management.io/api/work/v1"
+	workv1alpha1 "open-cluster-management.io/api/work/v1alpha1"
 
 	corev1 "k8s.io/api/core/v1"
+	rbac "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
@@ -47,33 +50,71 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	gatewayapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayapiv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
 
+	certmanv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	kuadrantdnsv1alpha1 "github.com/kuadrant/dns-operator/api/v1alpha1"
 	"github.com/kuadrant/kuadrant-operator/pkg/multicluster"
 
 	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/gracePeriod"
 	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/metadata"
 	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/slice"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/placement"
 	"github.com/Kuadrant/multicluster-gateway-controller/pkg/policysync"
 )
 
 const (
 	LabelPrefix                           = "kuadrant.io/"
-	GatewayClusterLabelSelectorAnnotation = LabelPrefix + "gateway-cluster-label-selector"
+	GatewayClusterLabelSelectorAnnotation = placement.ClusterLabelSelectorAnnotation
 	GatewayClustersAnnotation             = LabelPrefix + "gateway-clusters"
+	GatewayDownstreamNamespaceAnnotation  = LabelPrefix + "gateway-downstream-namespace"
 	GatewayFinalizer                      = LabelPrefix + "gateway"
 	ManagedLabel                          = LabelPrefix + "managed"
 )
 
+// GatewayConditionClustersDegraded lists the targeted clusters the gateway is not fully programmed on
+const GatewayConditionClustersDegraded gatewayapiv1.GatewayConditionType = LabelPrefix + "ClustersDegraded"
+
+// reasons of the Programmed condition describing the state of the gateway on its clusters
+const (
+	GatewayReasonNoTargetClusters         gatewayapiv1.GatewayConditionReason = "NoTargetClusters"
+	GatewayReasonPartiallyPlaced          gatewayapiv1.GatewayConditionReason = "PartiallyPlaced"
+	GatewayReasonPlacementDecisionMissing gatewayapiv1.GatewayConditionReason = "PlacementDecisionMissing"
+	GatewayReasonManifestApplyFailed      gatewayapiv1.GatewayConditionReason = "ManifestApplyFailed"
+	GatewayReasonAddressesPending         gatewayapiv1.GatewayConditionReason = "AddressesPending"
+	GatewayReasonGracePeriodPending       gatewayapiv1.GatewayConditionReason = "GracePeriodPending"
+	GatewayReasonClustersHealthy          gatewayapiv1.GatewayConditionReason = "ClustersHealthy"
+)
+
+// clusterStatus is the state of the gateway on each of the clusters it targets
+type clusterStatus struct {
+	Targeted         []string
+	Placed           []string
+	Failed           []string
+	AddressesPending []string
+}
+
+// labels set by the DNSPolicy on the DNSRecords of a gateway
+const (
+	DNSRecordGatewayLabel          = LabelPrefix + "gateway"
+	DNSRecordGatewayNamespaceLabel = LabelPrefix + "gateway-namespace"
+)
+
 type GatewayPlacer interface {
 	//Place will use the placement logic to create the needed resources and ensure the objects are synced to the targeted clusters
 	// it will return the set of clusters it has targeted
 	Place(ctx context.Context, upstream *gatewayapiv1.Gateway, downstream *gatewayapiv1.Gateway, children ...metav1.Object) (sets.Set[string], error)
 	// gets the clusters the gateway has actually been placed on
 	GetPlacedClusters(ctx context.Context, gateway *gatewayapiv1.Gateway) (sets.Set[string], error)
+	// GetFailedClusters returns the clusters where the gateway failed to be applied
+	GetFailedClusters(ctx context.Context, gateway *gatewayapiv1.Gateway) (sets.Set[string], error)
 	//GetClusters returns the clusters decided on by the placement logic
 	GetClusters(ctx context.Context, gateway *gatewayapiv1.Gateway) (sets.Set[string], error)
 	// ListenerTotalAttachedRoutes returns the total attached routes for a listener from the downstream gateways
 	ListenerTotalAttachedRoutes(ctx context.Context, gateway *gatewayapiv1.Gateway, listenerName string, downstream string) (int, error)
+	// GetListenerStatus returns the status of a listener, with its supported kinds and conditions, from the downstream gateway
+	GetListenerStatus(ctx context.Context, gateway *gatewayapiv1.Gateway, listenerName string, downstream string) (gatewayapiv1.ListenerStatus, error)
 	// GetAddresses will look at the downstream view of the gateway and return the LB addresses used for these gateways
 	GetAddresses(ctx context.Context, gateway *gatewayapiv1.Gateway, downstream string) ([]gatewayapiv1.GatewayAddress, error)
 }
@@ -86,6 +127,7 @@ type GatewayPlacer interface {
 // +kubebuilder:rbac:groups=certificates.k8s.io,resources=signers,verbs=approve
 // +kubebuilder:rbac:groups=cluster.open-cluster-management.io,resources=managedclusters,verbs=get;list;watch;update
 // +kubebuilder:rbac:groups=work.open-cluster-management.io,resources=manifestworks,verbs=get;list;watch;create;update;delete;deletecollection;patch
+// +kubebuilder:rbac:groups=work.open-cluster-management.io,resources=manifestworkreplicasets,verbs=get;list;watch;create;update;delete;patch
 // +kubebuilder:rbac:groups=addon.open-cluster-management.io,resources=managedclusteraddons/finalizers,verbs=update
 // +kubebuilder:rbac:groups=addon.open-cluster-management.io,resources=clustermanagementaddons/finalizers,verbs=update
 // +kubebuilder:rbac:groups=addon.open-cluster-management.io,resources=clustermanagementaddons,verbs=get;list;watch
@@ -96,11 +138,17 @@ type GatewayPlacer interface {
 // +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=gateways,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=gateways/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=gateways/finalizers,verbs=update
+// +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=referencegrants,verbs=get;list;watch
 // +kubebuilder:rbac:groups=cluster.open-cluster-management.io,resources=placementdecisions,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;delete
 // +kubebuilder:rbac:groups="cert-manager.io",resources=certificates,verbs=get;list;watch;create;update;patch;delete
 
-// +kubebuilder:rbac:groups="kuadrant.io",resources=authpolicies;ratelimitpolicies,verbs=get;list;watch
+// +kubebuilder:rbac:groups="kuadrant.io",resources=dnsrecords,verbs=get;list;watch
+// +kubebuilder:rbac:groups="kuadrant.io",resources=gatewayclusteroverrides,verbs=get;list;watch
+// the policies of other resources configured to sync in the params need the same access to be
+// granted to the controller, as described in docs/gateways/define-and-place-a-gateway.md
+// +kubebuilder:rbac:groups="kuadrant.io",resources=authpolicies;ratelimitpolicies,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups="kuadrant.io",resources=authpolicies/status;ratelimitpolicies/status,verbs=get;update;patch
 
 // GatewayReconciler reconciles a Gateway object
 type GatewayReconciler struct {
@@ -109,7 +157,10 @@ type GatewayReconciler struct {
 	Placement              GatewayPlacer
 	PolicyInformersManager *policysync.PolicyInformersManager
 	DynamicClient          dynamic.Interface
-	WatchedPolicies        map[schema.GroupVersionResource]cache.ResourceEventHandlerRegistration
+	WatchedPolicies        map[schema.GroupVersionResource]policysync.PolicyWatch
+	// WorkAgentSubject is the subject of the work agent on the spokes allowed to manage the
+	// synced policies. Defaults to the placement.DefaultWorkAgentSubject
+	WorkAgentSubject rbac.Subject
 }
 
 func isDeleting(g *gatewayapiv1.Gateway) bool {
@@ -130,7 +181,13 @@ func (r *GatewayReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 	log.V(3).Info("reconciling gateway", "classname", upstreamGateway.Spec.GatewayClassName)
 	if isDeleting(upstreamGateway) {
 		log.Info("gateway being deleted ", "gateway", upstreamGateway.Name, "namespace", upstreamGateway.Namespace)
-		if _, _, _, err := r.reconcileDownstreamFromUpstreamGateway(ctx, upstreamGateway, nil); client.IgnoreNotFound(err) != nil {
+		_, _, _, err := r.reconcileDownstreamFromUpstreamGateway(ctx, upstreamGateway, nil)
+		if errors.Is(err, placement.ErrWorkDeleting) {
+			// the finalizer is kept until the gateway is removed from the clusters, so the RBAC of the work agent is removed after it
+			log.V(3).Info("waiting for the gateway to be removed from the clusters", "gateway", upstreamGateway.Name, "namespace", upstreamGateway.Namespace, "reason", err)
+			return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+		}
+		if client.IgnoreNotFound(err) != nil {
 			return ctrl.Result{}, fmt.Errorf("failed to reconcile downstream gateway after upstream gateway deleted: %s ", err)
 		}
 		controllerutil.RemoveFinalizer(upstreamGateway, GatewayFinalizer)
@@ -178,7 +235,7 @@ func (r *GatewayReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 	if !meta.IsStatusConditionTrue(upstreamGateway.Status.Conditions, string(gatewayapiv1.GatewayConditionAccepted)) {
 		log.V(3).Info("gateway is accepted setting initial programmed and accepted status")
 		acceptedCondition := buildAcceptedCondition(upstreamGateway.Generation, metav1.ConditionTrue)
-		programmedCondition := buildProgrammedCondition(upstreamGateway.Generation, []string{}, metav1.ConditionUnknown, nil)
+		programmedCondition := buildProgrammedCondition(upstreamGateway.Generation, nil, metav1.ConditionUnknown, nil)
 		meta.SetStatusCondition(&upstreamGateway.Status.Conditions, acceptedCondition)
 		meta.SetStatusCondition(&upstreamGateway.Status.Conditions, programmedCondition)
 		return reconcile.Result{}, r.Status().Update(ctx, upstreamGateway)
@@ -192,15 +249,26 @@ func (r *GatewayReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 	if reconcileErr != nil {
 		//TODO (cbrookes) refactor how status is handled in this controller
 		if errors.Is(reconcileErr, gracePeriod.ErrGracePeriodNotExpired) || requeue {
+			// requeue when the grace period expires, if known
+			requeueAfter := 30 * time.Second
+			pending := &gracePeriod.GracePeriodNotExpiredError{}
+			if errors.As(reconcileErr, &pending) && pending.Remaining > 0 {
+				requeueAfter = pending.Remaining
+			}
 			log.V(3).Info("requeueing gateway ", "error", reconcileErr, "requeue", requeue)
-			programmedCondition := buildProgrammedCondition(upstreamGateway.Generation, clusters, metav1.ConditionUnknown, reconcileErr)
+			status, err := r.clusterStatus(ctx, upstreamGateway, clusters, nil)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+			programmedCondition := buildProgrammedCondition(upstreamGateway.Generation, status, metav1.ConditionUnknown, reconcileErr)
 			meta.SetStatusCondition(&upstreamGateway.Status.Conditions, programmedCondition)
+			meta.SetStatusCondition(&upstreamGateway.Status.Conditions, buildClustersDegradedCondition(upstreamGateway.Generation, status, programmedCondition))
 			if !isDeleting(upstreamGateway) && !reflect.DeepEqual(upstreamGateway.Status, previous.Status) {
 				return reconcile.Result{}, r.Status().Update(ctx, upstreamGateway)
 			}
 			return reconcile.Result{
 				Requeue:      true,
-				RequeueAfter: 30 * time.Second,
+				RequeueAfter: requeueAfter,
 			}, nil
 		}
 		log.Error(fmt.Errorf("gateway reconcile failed %s", reconcileErr), "gateway failed to reconcile", "gateway", upstreamGateway.Name)
@@ -223,14 +291,15 @@ func (r *GatewayReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		return reconcile.Result{}, r.Update(ctx, upstreamGateway)
 	}
 
-	var addressErr error
+	addressesPending := []string{}
 	allAddresses := []gatewayapiv1.GatewayStatusAddress{}
 	for _, cluster := range clusters {
 		log.V(3).Info("checking cluster for addresses", "cluster", cluster)
 		addresses, addressErr := r.Placement.GetAddresses(ctx, upstreamGateway, cluster)
 		log.V(3).Info("got addresses", "addresses,", addresses, "addressErr", addressErr)
-		if addressErr != nil {
-			break
+		if addressErr != nil || len(addresses) == 0 {
+			addressesPending = append(addressesPending, cluster)
+			continue
 		}
 		for _, address := range addresses {
 			log.V(3).Info("checking address type for mapping", "address.Type", address.Type)
@@ -244,37 +313,53 @@ func (r *GatewayReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 			})
 		}
 	}
-	if addressErr != nil {
-		return ctrl.Result{}, reconcileErr
-	}
 	log.V(3).Info("allAddresses", "allAddresses", allAddresses)
 	upstreamGateway.Status.Addresses = allAddresses
 
+	// listeners referencing secrets without a ReferenceGrant do not resolve their refs on any cluster
+	refsNotPermitted, err := listenersRefNotPermitted(ctx, r.Client, upstreamGateway)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
 	allListenerStatuses := []gatewayapiv1.ListenerStatus{}
 	specListeners := upstreamGateway.Spec.Listeners
 	for _, listener := range specListeners {
+		message, notPermitted := refsNotPermitted[listener.Name]
+		listenerStatuses := 0
 		for _, cluster := range clusters {
-			attachedRoutes, err := r.Placement.ListenerTotalAttachedRoutes(ctx, upstreamGateway, string(listener.Name), cluster)
+			listenerStatus, err := r.Placement.GetListenerStatus(ctx, upstreamGateway, string(listener.Name), cluster)
 			if err != nil {
 				// May not have the status yet, let's ignore, but output info logs about it
-				log.Info("AttachedRoutes unknown for listener. Ignoring", "listener", listener.Name, "cluster", cluster, "message", err)
+				log.Info("Status unknown for listener. Ignoring", "listener", listener.Name, "cluster", cluster, "message", err)
 				continue
 			}
+			status := buildListenerStatus(upstreamGateway.Generation, cluster, listenerStatus)
+			if notPermitted {
+				meta.SetStatusCondition(&status.Conditions, buildRefNotPermittedCondition(upstreamGateway.Generation, message))
+			}
+			allListenerStatuses = append(allListenerStatuses, status)
+			listenerStatuses++
+		}
+		if notPermitted && listenerStatuses == 0 {
 			allListenerStatuses = append(allListenerStatuses, gatewayapiv1.ListenerStatus{
-				Name:           gatewayapiv1.SectionName(fmt.Sprintf("%s.%s", cluster, string(listener.Name))),
-				AttachedRoutes: int32(attachedRoutes),
+				Name:           listener.Name,
 				SupportedKinds: []gatewayapiv1.RouteGroupKind{},
-				Conditions:     []metav1.Condition{},
+				Conditions:     []metav1.Condition{buildRefNotPermittedCondition(upstreamGateway.Generation, message)},
 			})
 		}
 	}
 	upstreamGateway.Status.Listeners = allListenerStatuses
 
+	status, err := r.clusterStatus(ctx, upstreamGateway, clusters, addressesPending)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
 	acceptedCondition := buildAcceptedCondition(upstreamGateway.Generation, metav1.ConditionTrue)
-	programmedCondition := buildProgrammedCondition(upstreamGateway.Generation, clusters, programmedStatus, err)
+	programmedCondition := buildProgrammedCondition(upstreamGateway.Generation, status, programmedStatus, reconcileErr)
 
 	meta.SetStatusCondition(&upstreamGateway.Status.Conditions, acceptedCondition)
 	meta.SetStatusCondition(&upstreamGateway.Status.Conditions, programmedCondition)
+	meta.SetStatusCondition(&upstreamGateway.Status.Conditions, buildClustersDegradedCondition(upstreamGateway.Generation, status, programmedCondition))
 
 	if !isDeleting(upstreamGateway) && !reflect.DeepEqual(upstreamGateway.Status, previous.Status) {
 		return reconcile.Result{}, r.Status().Update(ctx, upstreamGateway)
@@ -284,6 +369,10 @@ func (r *GatewayReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		log.V(3).Info("requeuing gateway in ", "namespace", upstreamGateway.Namespace, "with name", upstreamGateway.Name)
 		return ctrl.Result{Requeue: true, RequeueAfter: time.Second * 10}, reconcileErr
 	}
+	if errors.As(reconcileErr, new(*placement.ConstraintError)) || errors.As(reconcileErr, new(*placement.RolloutError)) {
+		// reported in the programmed condition
+		return ctrl.Result{}, nil
+	}
 	return ctrl.Result{}, reconcileErr
 }
 
@@ -319,16 +408,25 @@ func (r *GatewayReconciler) reconcileDownstreamFromUpstreamGateway(ctx context.C
 	log := crlog.FromContext(ctx)
 	clusters := []string{}
 	downstream := upstreamGateway.DeepCopy()
-	downstreamNS := fmt.Sprintf("%s-%s", "kuadrant", downstream.Namespace)
+	// the placed objects are found by name when the gateway is deleted, so the namespace is not needed
+	downstreamNS, err := downstreamNamespace(upstreamGateway, params)
+	if err != nil && !isDeleting(upstreamGateway) {
+		return false, metav1.ConditionFalse, clusters, err
+	}
 	downstream.Status = gatewayapiv1.GatewayStatus{}
 
 	// reset this for the sync as we don't want control plane level UID, creation etc etc
 	downstream.ObjectMeta = metav1.ObjectMeta{
-		Name:        upstreamGateway.Name,
-		Namespace:   downstreamNS,
-		Labels:      downstream.Labels,
-		Annotations: downstream.Annotations,
+		Name:      upstreamGateway.Name,
+		Namespace: downstreamNS,
+	}
+	// the labels and annotations the hub uses to place the gateway are not propagated by default
+	var propagatedLabels, propagatedAnnotations *MetadataPropagation
+	if params != nil {
+		propagatedLabels, propagatedAnnotations = params.PropagatedLabels, params.PropagatedAnnotations
 	}
+	metadata.CopyLabelsPredicate(upstreamGateway, downstream, propagatedLabels.predicate())
+	metadata.CopyAnnotationsPredicate(upstreamGateway, downstream, propagatedAnnotations.predicate())
 	if downstream.Labels == nil {
 		downstream.Labels = map[string]string{}
 	}
@@ -346,6 +444,15 @@ func (r *GatewayReconciler) reconcileDownstreamFromUpstreamGateway(ctx context.C
 		return false, metav1.ConditionFalse, clusters, fmt.Errorf("no managed listeners found")
 	}
 
+	// issue the certificates of the HTTPS listeners and wait for their secrets before placing them
+	pending, err := r.reconcileCertificates(ctx, upstreamGateway, params)
+	if err != nil {
+		return true, metav1.ConditionFalse, clusters, fmt.Errorf("failed to reconcile listener certificates : %w", err)
+	}
+	if len(pending) > 0 {
+		return true, metav1.ConditionUnknown, clusters, fmt.Errorf("waiting for tls secrets %s to be issued", strings.Join(pending, ", "))
+	}
+
 	// get tls secrets for all TLS listeners.
 	tlsSecrets, err := r.getTLSSecrets(ctx, upstreamGateway, downstream)
 	if err != nil {
@@ -360,7 +467,19 @@ func (r *GatewayReconciler) reconcileDownstreamFromUpstreamGateway(ctx context.C
 	}
 
 	// ensure the gateways are placed into the right target clusters and removed from any that are no longer targeted
-	targets, err := r.Placement.Place(ctx, upstreamGateway, downstream, tlsSecrets...)
+	placementGateway, err := r.placementGateway(ctx, upstreamGateway, params)
+	if err != nil {
+		return false, metav1.ConditionFalse, clusters, fmt.Errorf("failed to get placement params : %w", err)
+	}
+	targets, err := r.Placement.Place(ctx, placementGateway, downstream, tlsSecrets...)
+	if placement.IsRolloutInProgress(err) && !errors.As(err, new(*placement.ConstraintError)) {
+		// requeue to check on the clusters updated so far
+		return true, metav1.ConditionUnknown, sets.List(targets), fmt.Errorf("failed to place gateway : %w", err)
+	}
+	if errors.As(err, new(*placement.ConstraintError)) || errors.As(err, new(*placement.RolloutError)) {
+		// retrying will not help until the clusters or the gateway change, which will trigger a reconcile
+		return false, metav1.ConditionFalse, sets.List(targets), fmt.Errorf("failed to place gateway : %w", err)
+	}
 	if err != nil {
 		return true, metav1.ConditionFalse, clusters, fmt.Errorf("failed to place gateway : %w", err)
 	}
@@ -380,6 +499,63 @@ func (r *GatewayReconciler) reconcileDownstreamFromUpstreamGateway(ctx context.C
 	return false, metav1.ConditionUnknown, clusters, nil
 }
 
+// placementGateway returns the gateway to place, using the rollout batch and grace period from the
+// params unless the gateway sets its own
+func (r *GatewayReconciler) placementGateway(ctx context.Context, upstreamGateway *gatewayapiv1.Gateway, params *Params) (*gatewayapiv1.Gateway, error) {
+	if params == nil {
+		return upstreamGateway, nil
+	}
+	gateway := upstreamGateway.DeepCopy()
+	if params.RolloutBatch != "" && !metadata.HasAnnotation(gateway, placement.RolloutBatchAnnotation) {
+		metadata.AddAnnotation(gateway, placement.RolloutBatchAnnotation, params.RolloutBatch)
+	}
+	if !metadata.HasAnnotation(gateway, gracePeriod.GracePeriodAnnotation) {
+		period, err := r.gracePeriodFor(ctx, gateway, params)
+		if err != nil {
+			return nil, err
+		}
+		if period > 0 {
+			metadata.AddAnnotation(gateway, gracePeriod.GracePeriodAnnotation, period.String())
+		}
+	}
+	return gateway, nil
+}
+
+// gracePeriodFor returns the grace period of the gateway from the params. When the params derive
+// it from the DNS TTL, the highest TTL of the DNSRecords created for the gateway by its DNSPolicy
+// is used. Zero means the default grace period applies
+func (r *GatewayReconciler) gracePeriodFor(ctx context.Context, gateway *gatewayapiv1.Gateway, params *Params) (time.Duration, error) {
+	if params.GracePeriodFromDNSTTL {
+		records := &kuadrantdnsv1alpha1.DNSRecordList{}
+		err := r.Client.List(ctx, records, client.MatchingLabels{
+			DNSRecordGatewayLabel:          gateway.Name,
+			DNSRecordGatewayNamespaceLabel: gateway.Namespace,
+		})
+		if err != nil && !meta.IsNoMatchError(err) && !runtime.IsNotRegisteredError(err) {
+			return 0, err
+		}
+		var ttl int64
+		for _, record := range records.Items {
+			for _, endpoint := range record.Spec.Endpoints {
+				if int64(endpoint.RecordTTL) > ttl {
+					ttl = int64(endpoint.RecordTTL)
+				}
+			}
+		}
+		if ttl > 0 {
+			return gracePeriod.FromTTL(ttl), nil
+		}
+	}
+	if params.GracePeriod == "" {
+		return 0, nil
+	}
+	period, err := time.ParseDuration(params.GracePeriod)
+	if err != nil || period < 0 {
+		return 0, &InvalidParamsError{fmt.Sprintf("invalid gracePeriod %q: must be a positive duration", params.GracePeriod)}
+	}
+	return period, nil
+}
+
 func (r *GatewayReconciler) getTLSSecrets(ctx context.Context, upstreamGateway *gatewayapiv1.Gateway, downstreamGateway *gatewayapiv1.Gateway) ([]metav1.Object, error) {
 	log := crlog.FromContext(ctx)
 	tlsSecrets := []metav1.Object{}
@@ -391,13 +567,23 @@ func (r *GatewayReconciler) getTLSSecrets(ctx context.Context, upstreamGateway *
 				if secretRef.Namespace != nil {
 					ns = string(*secretRef.Namespace)
 				}
+				// secrets in other namespaces are only copied to the clusters when a ReferenceGrant permits it
+				permitted, err := secretRefPermitted(ctx, r.Client, upstreamGateway, secretRef)
+				if err != nil {
+					listenerTLSErr = errors.Join(listenerTLSErr, err)
+					continue
+				}
+				if !permitted {
+					log.Info("tls secret reference not permitted", "listener", listener.Name, "secret", secretRef.Name, "namespace", ns)
+					continue
+				}
 				tlsSecret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
 					Name:      string(secretRef.Name),
 					Namespace: ns,
 				}}
 				if err := r.Client.Get(ctx, client.ObjectKeyFromObject(tlsSecret), tlsSecret); err != nil {
 					log.Error(err, "cant find tls secret")
-					listenerTLSErr = errors.Join(fmt.Errorf("failed to find tls secret for listener %s %w", listener.Name, err))
+					listenerTLSErr = errors.Join(listenerTLSErr, fmt.Errorf("failed to find tls secret for listener %s %w", listener.Name, err))
 					continue
 				}
 
@@ -416,20 +602,66 @@ func (r *GatewayReconciler) getTLSSecrets(ctx context.Context, upstreamGateway *
 }
 
 func (r *GatewayReconciler) reconcileParams(ctx context.Context, gateway *gatewayapiv1.Gateway, params *Params) error {
-	log := crlog.FromContext(ctx)
-
 	downstreamClass := params.GetDownstreamClass()
 
 	// Set the annotations to sync the class name from the parameters
 
 	gateway.Spec.GatewayClassName = gatewayapiv1.ObjectName(downstreamClass)
 
-	policiesToSync := slice.Map(params.PoliciesToSync, ParamsGroupVersionResource.ToGroupVersionResource)
+	return r.reconcilePolicyWatches(ctx)
+}
+
+// policiesToSync returns the policies to sync of every class handled by this controller, as
+// each class can have its own params
+func (r *GatewayReconciler) policiesToSync(ctx context.Context) (sets.Set[schema.GroupVersionResource], error) {
+	log := crlog.FromContext(ctx)
+	policies := sets.New[schema.GroupVersionResource]()
 
-	for _, gvr := range policiesToSync {
+	gatewayClasses := &gatewayapiv1.GatewayClassList{}
+	if err := r.Client.List(ctx, gatewayClasses); err != nil {
+		return nil, err
+	}
+	for i := range gatewayClasses.Items {
+		if !isManagedClass(&gatewayClasses.Items[i]) {
+			continue
+		}
+		// the policies of a class are only synced once its params are valid, as
+		// checked by the class controller before the class is accepted
+		params, err := getParams(ctx, r.Client, gatewayClasses.Items[i].Name)
+		if err == nil {
+			err = validateParams(ctx, r.Client, params)
+		}
+		if IsInvalidParamsError(err) {
+			// the class reports its invalid params in its own status
+			log.V(3).Info("skipping policies to sync of class with invalid params", "class", gatewayClasses.Items[i].Name)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		policies.Insert(slice.Map(params.PoliciesToSync, ParamsGroupVersionResource.ToGroupVersionResource)...)
+	}
+	return policies, nil
+}
+
+// reconcilePolicyWatches ensures the policies to sync of every class are watched, and stops
+// watching those no class syncs anymore
+func (r *GatewayReconciler) reconcilePolicyWatches(ctx context.Context) error {
+	// policies are synced with ManifestWork, there is nothing to watch without OCM placement
+	if r.PolicyInformersManager == nil {
+		return nil
+	}
+	log := crlog.FromContext(ctx)
+
+	policiesToSync, err := r.policiesToSync(ctx)
+	if err != nil {
+		return err
+	}
+
+	for gvr := range policiesToSync {
 		// If it's already watched skip it
-		_, ok := r.WatchedPolicies[gvr]
-		if ok {
+		// a stopped watch is replaced, its policies were being removed from the clusters
+		if watch, ok := r.WatchedPolicies[gvr]; ok && !watch.Handler.Stopped() {
 			continue
 		}
 
@@ -437,16 +669,19 @@ func (r *GatewayReconciler) reconcileParams(ctx context.Context, gateway *gatewa
 
 		// Add the event handler for the policy
 		eventHandler := &policysync.ResourceEventHandler{
-			Log:           log,
+			Log:           crlog.Log.WithName("policysync").WithValues("gvr", gvr),
 			GVR:           gvr,
 			Client:        r.Client,
 			DynamicClient: r.DynamicClient,
-			Gateway:       gateway,
-			Syncer:        &policysync.FakeSyncer{},
+			Syncer:        r.policySyncer(gvr),
+		}
+		if err := r.PolicyInformersManager.AddHandler(eventHandler); err != nil {
+			return err
 		}
 		informer := r.PolicyInformersManager.InformerFactory.ForResource(gvr).Informer()
 		reg, err := informer.AddEventHandler(eventHandler)
 		if err != nil {
+			eventHandler.Stop()
 			return err
 		}
 
@@ -456,45 +691,143 @@ func (r *GatewayReconciler) reconcileParams(ctx context.Context, gateway *gatewa
 		}
 
 		// Keep track of the watched policy
-		r.WatchedPolicies[gvr] = reg
+		r.WatchedPolicies[gvr] = policysync.PolicyWatch{Handler: eventHandler, Registration: reg}
 	}
 
-	// Stop watching policies if they're removed from the params
-	policiesToUnwatch := []schema.GroupVersionResource{}
-	for gvr, reg := range r.WatchedPolicies {
-		if slice.Contains(policiesToSync, slice.EqualsTo(gvr)) {
+	// Stop watching policies if they're removed from the params of every class
+	for gvr, watch := range r.WatchedPolicies {
+		if policiesToSync.Has(gvr) {
 			continue
 		}
 
 		log.Info("Stopping watch for policy", "gvr", gvr)
 
-		if err := r.PolicyInformersManager.InformerFactory.ForResource(gvr).Informer().RemoveEventHandler(reg); err != nil {
+		// the watch is stopped before the policies are removed from the clusters, so no
+		// sync in flight adds them back. It's only dropped once they're removed, so a
+		// failure to remove them is retried on the next reconcile
+		if err := r.PolicyInformersManager.InformerFactory.ForResource(gvr).Informer().RemoveEventHandler(watch.Registration); err != nil {
 			return err
 		}
+		watch.Handler.Stop()
 
-		policiesToUnwatch = append(policiesToUnwatch, gvr)
-	}
+		if err := r.removeSyncedPolicies(ctx, gvr); err != nil {
+			return err
+		}
 
-	for _, gvr := range policiesToUnwatch {
 		delete(r.WatchedPolicies, gvr)
 	}
 
 	return nil
 }
 
-func buildProgrammedCondition(generation int64, placed []string, programmedStatus metav1.ConditionStatus, err error) metav1.Condition {
-	var reason = gatewayapiv1.GatewayReasonProgrammed
-	message := "waiting for gateway to placed on clusters %v"
-	if programmedStatus == metav1.ConditionTrue {
-		message = fmt.Sprintf("gateway placed on clusters %v", placed)
+// policySyncer returns the syncer of the policies of the given resource
+func (r *GatewayReconciler) policySyncer(gvr schema.GroupVersionResource) policysync.Syncer {
+	return &policysync.ManifestWorkSyncer{
+		Placer:              r.Placement,
+		GVR:                 gvr,
+		DownstreamNamespace: downstreamNamespaceFor,
+		WorkAgentSubject:    r.WorkAgentSubject,
 	}
-	if programmedStatus == metav1.ConditionFalse {
-		message = fmt.Sprintf("gateway failed to be placed on all clusters %v", placed)
-		reason = gatewayapiv1.GatewayReasonInvalid
+}
+
+// removeSyncedPolicies removes every policy of the given resource from the clusters it was
+// synced to, releasing their policy sync finalizer, as they are not synced by any class anymore
+func (r *GatewayReconciler) removeSyncedPolicies(ctx context.Context, gvr schema.GroupVersionResource) error {
+	objs, err := r.PolicyInformersManager.InformerFactory.ForResource(gvr).Lister().List(labels.Everything())
+	if err != nil {
+		return err
 	}
-	if programmedStatus == metav1.ConditionUnknown {
+	syncer := r.policySyncer(gvr)
+	for _, obj := range objs {
+		policy, err := policysync.NewPolicyFor(obj.DeepCopyObject())
+		if err != nil {
+			// only valid policies are synced
+			continue
+		}
+		if err := syncer.RemovePolicy(ctx, r.Client, policy); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// clusterStatus works out the state of the gateway on the clusters it targets
+func (r *GatewayReconciler) clusterStatus(ctx context.Context, gateway *gatewayapiv1.Gateway, placed []string, addressesPending []string) (*clusterStatus, error) {
+	// errors getting the targets, such as invalid constraints, are reported from the placement
+	targets, _ := r.Placement.GetClusters(ctx, gateway)
+	failed, err := r.Placement.GetFailedClusters(ctx, gateway)
+	if err != nil {
+		return nil, err
+	}
+	return &clusterStatus{
+		Targeted:         sets.List(targets),
+		Placed:           placed,
+		Failed:           sets.List(failed),
+		AddressesPending: addressesPending,
+	}, nil
+}
+
+// buildProgrammedCondition builds the Programmed condition of the gateway. The reason describes the state of
+// the gateway across its clusters, with the clusters in each state listed in the message. A nil cluster
+// status means the state of the gateway is not known yet
+func buildProgrammedCondition(generation int64, clusters *clusterStatus, programmedStatus metav1.ConditionStatus, err error) metav1.Condition {
+	if clusters == nil {
+		clusters = &clusterStatus{}
+	}
+	targeted, placed := sets.New(clusters.Targeted...), sets.New(clusters.Placed...)
+
+	var reason = gatewayapiv1.GatewayReasonProgrammed
+	var message string
+	rolloutErr := &placement.RolloutError{}
+	constraintErr := &placement.ConstraintError{}
+	switch {
+	// placement constraints that can not be met and rollouts are reported with their own reason
+	case errors.As(err, &rolloutErr):
+		if rolloutErr.Reason != placement.RolloutReasonInProgress {
+			programmedStatus = metav1.ConditionFalse
+		}
+		reason = gatewayapiv1.GatewayConditionReason(rolloutErr.Reason)
+		message = fmt.Sprintf("gateway placed on clusters %v", clusters.Placed)
+	case errors.As(err, &constraintErr):
+		programmedStatus = metav1.ConditionFalse
+		reason = gatewayapiv1.GatewayConditionReason(constraintErr.Reason)
+		message = fmt.Sprintf("gateway placed on clusters %v", clusters.Placed)
+	case errors.Is(err, placement.ErrPlacementDecisionMissing):
+		programmedStatus = metav1.ConditionFalse
+		reason = GatewayReasonPlacementDecisionMissing
+		message = "the placement of the gateway has no decision"
+	case errors.Is(err, gracePeriod.ErrGracePeriodNotExpired):
+		programmedStatus = metav1.ConditionUnknown
+		reason = GatewayReasonGracePeriodPending
+		message = fmt.Sprintf("gateway placed on clusters %v, waiting for the grace period to remove it from clusters %v", clusters.Placed, sets.List(placed.Difference(targeted)))
+	case len(clusters.Failed) > 0:
+		programmedStatus = metav1.ConditionFalse
+		reason = GatewayReasonManifestApplyFailed
+		message = fmt.Sprintf("gateway failed to apply on clusters %v, placed on clusters %v", clusters.Failed, clusters.Placed)
+	case programmedStatus == metav1.ConditionFalse:
+		reason = gatewayapiv1.GatewayReasonInvalid
+		message = fmt.Sprintf("gateway failed to be placed on all clusters %v", clusters.Placed)
+	case programmedStatus == metav1.ConditionTrue && len(clusters.AddressesPending) > 0:
+		programmedStatus = metav1.ConditionUnknown
+		reason = GatewayReasonAddressesPending
+		message = fmt.Sprintf("gateway placed on clusters %v, waiting for addresses from clusters %v", clusters.Placed, clusters.AddressesPending)
+	case programmedStatus == metav1.ConditionTrue:
+		message = fmt.Sprintf("gateway placed on clusters %v", clusters.Placed)
+	case clusters.Targeted == nil && clusters.Placed == nil:
+		reason = gatewayapiv1.GatewayReasonPending
 		message = "current state of the gateway is unknown"
+	case targeted.Len() == 0:
+		reason = GatewayReasonNoTargetClusters
+		message = "gateway is not targeted at any clusters"
+	case placed.Len() == 0:
+		reason = gatewayapiv1.GatewayReasonPending
+		message = fmt.Sprintf("waiting for gateway to be placed on clusters %v", clusters.Targeted)
+	case !placed.IsSuperset(targeted):
+		reason = GatewayReasonPartiallyPlaced
+		message = fmt.Sprintf("gateway placed on clusters %v, waiting for clusters %v", clusters.Placed, sets.List(targeted.Difference(placed)))
+	default:
 		reason = gatewayapiv1.GatewayReasonPending
+		message = "current state of the gateway is unknown"
 	}
 
 	if err != nil {
@@ -511,6 +844,63 @@ func buildProgrammedCondition(generation int64, placed []string, programmedStatu
 	return cond
 }
 
+// buildClustersDegradedCondition builds the condition listing the targeted clusters the gateway
+// is not programmed on, with the state of each cluster
+func buildClustersDegradedCondition(generation int64, clusters *clusterStatus, programmed metav1.Condition) metav1.Condition {
+	degraded := map[string]gatewayapiv1.GatewayConditionReason{}
+	placed := sets.New(clusters.Placed...)
+	for _, cluster := range clusters.Targeted {
+		if !placed.Has(cluster) {
+			degraded[cluster] = gatewayapiv1.GatewayReasonPending
+		}
+	}
+	for _, cluster := range clusters.AddressesPending {
+		degraded[cluster] = GatewayReasonAddressesPending
+	}
+	for _, cluster := range clusters.Failed {
+		degraded[cluster] = GatewayReasonManifestApplyFailed
+	}
+
+	cond := metav1.Condition{
+		Type:               string(GatewayConditionClustersDegraded),
+		Status:             metav1.ConditionFalse,
+		Reason:             string(GatewayReasonClustersHealthy),
+		Message:            fmt.Sprintf("gateway is programmed on clusters %v", clusters.Placed),
+		ObservedGeneration: generation,
+	}
+	if len(degraded) == 0 {
+		return cond
+	}
+	details := []string{}
+	for _, cluster := range sets.List(sets.KeySet(degraded)) {
+		details = append(details, fmt.Sprintf("%s: %s", cluster, degraded[cluster]))
+	}
+	cond.Status = metav1.ConditionTrue
+	cond.Reason = programmed.Reason
+	if programmed.Status == metav1.ConditionTrue {
+		cond.Reason = string(gatewayapiv1.GatewayReasonPending)
+	}
+	cond.Message = fmt.Sprintf("degraded clusters [%s]", strings.Join(details, ", "))
+	return cond
+}
+
+// buildListenerStatus builds the hub status of a listener from the status reported by the downstream gateway on the cluster
+func buildListenerStatus(generation int64, cluster string, downstream gatewayapiv1.ListenerStatus) gatewayapiv1.ListenerStatus {
+	status := gatewayapiv1.ListenerStatus{
+		Name:           gatewayapiv1.SectionName(fmt.Sprintf("%s.%s", cluster, string(downstream.Name))),
+		AttachedRoutes: downstream.AttachedRoutes,
+		SupportedKinds: []gatewayapiv1.RouteGroupKind{},
+		Conditions:     []metav1.Condition{},
+	}
+	status.SupportedKinds = append(status.SupportedKinds, downstream.SupportedKinds...)
+	for _, condition := range downstream.Conditions {
+		// the downstream generation is not meaningful on the hub
+		condition.ObservedGeneration = generation
+		status.Conditions = append(status.Conditions, condition)
+	}
+	return status
+}
+
 func buildAcceptedCondition(generation int64, acceptedStatus metav1.ConditionStatus) metav1.Condition {
 	cond := metav1.Condition{
 		Type:               string(gatewayapiv1.GatewayConditionAccepted),
@@ -526,18 +916,37 @@ func buildAcceptedCondition(generation int64, acceptedStatus metav1.ConditionSta
 func (r *GatewayReconciler) SetupWithManager(mgr ctrl.Manager, ctx context.Context) error {
 	log := crlog.FromContext(ctx)
 	clusterEventMapper := NewClusterEventMapper(log, mgr.GetClient())
-	//TODO need to trigger gateway reconcile when gatewayclass params changes
-	return ctrl.NewControllerManagedBy(mgr).
+	gatewayClassEventMapper := NewGatewayClassEventMapper(log, mgr.GetClient())
+	params, err := paramsObjects(mgr.GetScheme(), mgr.GetRESTMapper())
+	if err != nil {
+		return err
+	}
+
+	// the gateways referencing a tls secret are found with an index, to enqueue them when it changes
+	if err := IndexGatewaySecretRefs(ctx, mgr.GetFieldIndexer()); err != nil {
+		return err
+	}
+
+	controller := ctrl.NewControllerManagedBy(mgr).
 		For(&gatewayapiv1.Gateway{}).
 		Watches(&workv1.ManifestWork{}, handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, o client.Object) []reconcile.Request {
 			log.V(3).Info("enqueuing gateways based on manifest work change ", "work namespace", o.GetNamespace())
 			requests := []reconcile.Request{}
-			annotations := o.GetAnnotations()
-			if annotations == nil {
-				log.V(3).Info("no parent or annotations on manifest work ", "work ns", o.GetNamespace(), "name", o.GetName())
-				return requests
+			key := o.GetAnnotations()[placement.ParentAnnotation]
+			if key == "" {
+				// works created from a ManifestWorkReplicaSet only carry a label referencing the replica set
+				replicaSetKey, ok := placement.ReplicaSetForWork(o)
+				if !ok {
+					log.V(3).Info("no parent or annotations on manifest work ", "work ns", o.GetNamespace(), "name", o.GetName())
+					return requests
+				}
+				replicaSet := &workv1alpha1.ManifestWorkReplicaSet{}
+				if err := mgr.GetClient().Get(ctx, replicaSetKey, replicaSet); err != nil {
+					log.V(3).Info("unable to get manifest work replica set for manifest work ", "work ns", o.GetNamespace(), "name", o.GetName(), "error", err)
+					return requests
+				}
+				key = replicaSet.GetAnnotations()[placement.ParentAnnotation]
 			}
-			key := annotations["kuadrant.io/parent"]
 			ns, name, err := cache.SplitMetaNamespaceKey(key)
 			if err != nil {
 				log.Error(err, "failed to parse namespace and name from manifest work")
@@ -567,16 +976,58 @@ func (r *GatewayReconciler) SetupWithManager(mgr ctrl.Manager, ctx context.Conte
 			return req
 		})).
 		Watches(&corev1.Secret{}, &ClusterEventHandler{client: r.Client}).
+		Watches(&corev1.Secret{}, &TLSSecretEventHandler{client: r.Client}).
 		Watches(
 			&clusterv1.ManagedCluster{},
 			handler.EnqueueRequestsFromMapFunc(clusterEventMapper.MapToGateway),
 		).
+		Watches(
+			&gatewayapiv1.GatewayClass{},
+			handler.EnqueueRequestsFromMapFunc(gatewayClassEventMapper.MapToGateway),
+		)
+	// gateways are reconciled with the params of their class
+	for _, obj := range params {
+		controller = controller.Watches(obj, handler.EnqueueRequestsFromMapFunc(gatewayClassEventMapper.MapParamsToGateway))
+	}
+	// the overrides of the gateway are applied to the clusters they select when placing it
+	if _, err := mgr.GetRESTMapper().RESTMapping(v1alpha1.GroupVersion.WithKind("GatewayClusterOverride").GroupKind()); err == nil {
+		controller = controller.Watches(&v1alpha1.GatewayClusterOverride{}, handler.EnqueueRequestsFromMapFunc(func(_ context.Context, o client.Object) []reconcile.Request {
+			override, ok := o.(*v1alpha1.GatewayClusterOverride)
+			if !ok {
+				return []reconcile.Request{}
+			}
+			return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: override.Namespace, Name: override.Spec.GatewayName}}}
+		}))
+	} else if !meta.IsNoMatchError(err) {
+		return err
+	}
+	// secrets in other namespaces are placed once a ReferenceGrant permits the gateway to reference them
+	if _, err := mgr.GetRESTMapper().RESTMapping(gatewayapiv1beta1.SchemeGroupVersion.WithKind("ReferenceGrant").GroupKind()); err == nil {
+		controller = controller.Watches(&gatewayapiv1beta1.ReferenceGrant{}, handler.EnqueueRequestsFromMapFunc(mapReferenceGrantToGateways(mgr.GetClient())))
+	} else if !meta.IsNoMatchError(err) {
+		return err
+	}
+	// the gateway is placed once the certificates of its listeners are issued
+	if _, err := mgr.GetRESTMapper().RESTMapping(certmanv1.SchemeGroupVersion.WithKind(certmanv1.CertificateKind).GroupKind()); err == nil {
+		controller = controller.Owns(&certmanv1.Certificate{})
+	} else if !meta.IsNoMatchError(err) {
+		return err
+	}
+
+	return controller.
 		WithEventFilter(predicate.NewPredicateFuncs(func(object client.Object) bool {
 			gateway, ok := object.(*gatewayapiv1.Gateway)
 			if ok {
-				shouldReconcile := slice.ContainsString(getSupportedClasses(), string(gateway.Spec.GatewayClassName))
+				// gateways placed by the controller are cleaned up when deleted even if their class is gone
+				if isDeleting(gateway) && controllerutil.ContainsFinalizer(gateway, GatewayFinalizer) {
+					return true
+				}
+				shouldReconcile, err := isManagedGateway(ctx, mgr.GetClient(), gateway)
+				if err != nil {
+					log.Error(err, "failed to get gateway class", "gateway", gateway.Name, "class", gateway.Spec.GatewayClassName)
+				}
 				log.V(3).Info(" should reconcile", "gateway", gateway.Name, "with class ", gateway.Spec.GatewayClassName, "should ", shouldReconcile)
-				return slice.ContainsString(getSupportedClasses(), string(gateway.Spec.GatewayClassName))
+				return shouldReconcile
 			}
 			return true
 		})).
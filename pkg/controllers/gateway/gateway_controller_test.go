@@ -4,20 +4,37 @@ package gateway
 
 import (
 	"context"
+	"fmt"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
+
+	workv1 "open-cluster-management.io/api/work/v1"
 
 	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/tools/cache"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	gatewayapiv1 "sigs.k8s.io/gateway-api/apis/v1"
 
+	kuadrantdnsv1alpha1 "github.com/kuadrant/dns-operator/api/v1alpha1"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/gracePeriod"
 	"github.com/Kuadrant/multicluster-gateway-controller/pkg/placement"
 	fakeplacement "github.com/Kuadrant/multicluster-gateway-controller/pkg/placement/fake"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/policysync"
 	testutil "github.com/Kuadrant/multicluster-gateway-controller/test/util"
 )
 
@@ -447,6 +464,47 @@ func TestGatewayReconciler_getTLSSecrets(t *testing.T) {
 			want:    []v1.Object{},
 			wantErr: true,
 		},
+		{
+			name: "skips secret in another namespace without a ReferenceGrant",
+			fields: fields{
+				Client: testutil.GetValidTestClient(getValidTLSCertificateSecretList(testutil.TLSSecretName, "other-ns")),
+				Scheme: testutil.GetValidTestScheme(),
+			},
+			args: args{
+				upstreamGateway: &gatewayapiv1.Gateway{
+					ObjectMeta: v1.ObjectMeta{
+						Namespace: testutil.Namespace,
+						Name:      testutil.DummyCRName,
+					},
+					Spec: gatewayapiv1.GatewaySpec{
+						Listeners: []gatewayapiv1.Listener{
+							{
+								Name:     testutil.ValidTestHostname,
+								Hostname: testutil.Pointer(gatewayapiv1.Hostname(testutil.ValidTestHostname)),
+								Protocol: gatewayapiv1.HTTPSProtocolType,
+								TLS: &gatewayapiv1.GatewayTLSConfig{
+									Mode: testutil.Pointer(gatewayapiv1.TLSModeTerminate),
+									CertificateRefs: []gatewayapiv1.SecretObjectReference{
+										{
+											Name:      testutil.TLSSecretName,
+											Namespace: testutil.Pointer(gatewayapiv1.Namespace("other-ns")),
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+				downstreamGateway: &gatewayapiv1.Gateway{
+					ObjectMeta: v1.ObjectMeta{
+						Namespace: testutil.Namespace + "-downstream",
+						Name:      testutil.DummyCRName,
+					},
+				},
+			},
+			want:    []v1.Object{},
+			wantErr: false,
+		},
 		{
 			name: "returns empty list for HTTP listener",
 			fields: fields{
@@ -494,7 +552,7 @@ func Test_buildProgrammedStatus(t *testing.T) {
 	type args struct {
 		gatewayStatus    gatewayapiv1.GatewayStatus
 		generation       int64
-		clusters         []string
+		clusters         *clusterStatus
 		programmedStatus v1.ConditionStatus
 	}
 	testCases := []struct {
@@ -578,6 +636,91 @@ func Test_buildProgrammedStatus(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "No target clusters",
+			args: args{
+				generation:       1,
+				clusters:         &clusterStatus{Targeted: []string{}, Placed: []string{}},
+				programmedStatus: v1.ConditionUnknown,
+			},
+			want: []v1.Condition{
+				{
+					Type:               string(gatewayapiv1.GatewayConditionProgrammed),
+					Status:             v1.ConditionUnknown,
+					ObservedGeneration: 1,
+					Reason:             string(GatewayReasonNoTargetClusters),
+					Message:            "gateway is not targeted at any clusters",
+				},
+			},
+		},
+		{
+			name: "Waiting for placement",
+			args: args{
+				generation:       1,
+				clusters:         &clusterStatus{Targeted: []string{"c1"}, Placed: []string{}},
+				programmedStatus: v1.ConditionUnknown,
+			},
+			want: []v1.Condition{
+				{
+					Type:               string(gatewayapiv1.GatewayConditionProgrammed),
+					Status:             v1.ConditionUnknown,
+					ObservedGeneration: 1,
+					Reason:             string(gatewayapiv1.GatewayReasonPending),
+					Message:            "waiting for gateway to be placed on clusters [c1]",
+				},
+			},
+		},
+		{
+			name: "Partially placed",
+			args: args{
+				generation:       1,
+				clusters:         &clusterStatus{Targeted: []string{"c1", "c2"}, Placed: []string{"c1"}},
+				programmedStatus: v1.ConditionUnknown,
+			},
+			want: []v1.Condition{
+				{
+					Type:               string(gatewayapiv1.GatewayConditionProgrammed),
+					Status:             v1.ConditionUnknown,
+					ObservedGeneration: 1,
+					Reason:             string(GatewayReasonPartiallyPlaced),
+					Message:            "gateway placed on clusters [c1], waiting for clusters [c2]",
+				},
+			},
+		},
+		{
+			name: "Manifest failed to apply",
+			args: args{
+				generation:       1,
+				clusters:         &clusterStatus{Targeted: []string{"c1", "c2"}, Placed: []string{"c1"}, Failed: []string{"c2"}},
+				programmedStatus: v1.ConditionUnknown,
+			},
+			want: []v1.Condition{
+				{
+					Type:               string(gatewayapiv1.GatewayConditionProgrammed),
+					Status:             v1.ConditionFalse,
+					ObservedGeneration: 1,
+					Reason:             string(GatewayReasonManifestApplyFailed),
+					Message:            "gateway failed to apply on clusters [c2], placed on clusters [c1]",
+				},
+			},
+		},
+		{
+			name: "Addresses pending",
+			args: args{
+				generation:       1,
+				clusters:         &clusterStatus{Targeted: []string{"c1"}, Placed: []string{"c1"}, AddressesPending: []string{"c1"}},
+				programmedStatus: v1.ConditionTrue,
+			},
+			want: []v1.Condition{
+				{
+					Type:               string(gatewayapiv1.GatewayConditionProgrammed),
+					Status:             v1.ConditionUnknown,
+					ObservedGeneration: 1,
+					Reason:             string(GatewayReasonAddressesPending),
+					Message:            "waiting for addresses from clusters [c1]",
+				},
+			},
+		},
 	}
 	for _, testCase := range testCases {
 		t.Run(testCase.name, func(t *testing.T) {
@@ -588,8 +731,201 @@ func Test_buildProgrammedStatus(t *testing.T) {
 	}
 }
 
+func TestBuildProgrammedConditionConstraintError(t *testing.T) {
+	err := fmt.Errorf("failed to place gateway : %w", &placement.ConstraintError{
+		Reason:  placement.ConstraintReasonInsufficientClusters,
+		Message: "gateway requires at least 3 clusters",
+	})
+	got := buildProgrammedCondition(1, &clusterStatus{Targeted: []string{"c1"}, Placed: []string{"c1"}}, v1.ConditionTrue, err)
+	if got.Status != v1.ConditionFalse {
+		t.Errorf("expected programmed condition to be false got %s", got.Status)
+	}
+	if got.Reason != placement.ConstraintReasonInsufficientClusters {
+		t.Errorf("expected reason %s got %s", placement.ConstraintReasonInsufficientClusters, got.Reason)
+	}
+	if !strings.Contains(got.Message, "gateway requires at least 3 clusters") {
+		t.Errorf("expected message to explain the constraint got %s", got.Message)
+	}
+}
+
+func TestBuildProgrammedConditionErrors(t *testing.T) {
+	clusters := &clusterStatus{Targeted: []string{"c1"}, Placed: []string{"c1", "c2"}}
+	testCases := []struct {
+		name       string
+		err        error
+		wantStatus v1.ConditionStatus
+		wantReason gatewayapiv1.GatewayConditionReason
+		wantMsg    string
+	}{
+		{
+			name:       "placement decision missing",
+			err:        fmt.Errorf("failed to place gateway : %w", fmt.Errorf("%w for placement test", placement.ErrPlacementDecisionMissing)),
+			wantStatus: v1.ConditionFalse,
+			wantReason: GatewayReasonPlacementDecisionMissing,
+			wantMsg:    "the placement of the gateway has no decision",
+		},
+		{
+			name:       "grace period pending",
+			err:        fmt.Errorf("failed to place gateway : %w", &gracePeriod.GracePeriodNotExpiredError{Remaining: time.Minute}),
+			wantStatus: v1.ConditionUnknown,
+			wantReason: GatewayReasonGracePeriodPending,
+			wantMsg:    "waiting for the grace period to remove it from clusters [c2]",
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			got := buildProgrammedCondition(1, clusters, v1.ConditionUnknown, testCase.err)
+			if got.Status != testCase.wantStatus || got.Reason != string(testCase.wantReason) || !strings.Contains(got.Message, testCase.wantMsg) {
+				t.Errorf("buildProgrammedCondition() = %v, want status %s reason %s message %q", got, testCase.wantStatus, testCase.wantReason, testCase.wantMsg)
+			}
+		})
+	}
+}
+
+func TestBuildClustersDegradedCondition(t *testing.T) {
+	clusters := &clusterStatus{
+		Targeted:         []string{"c1", "c2", "c3", "c4"},
+		Placed:           []string{"c1", "c2"},
+		Failed:           []string{"c3"},
+		AddressesPending: []string{"c2"},
+	}
+	programmed := buildProgrammedCondition(1, clusters, v1.ConditionUnknown, nil)
+	got := buildClustersDegradedCondition(1, clusters, programmed)
+	if got.Status != v1.ConditionTrue || got.Reason != string(GatewayReasonManifestApplyFailed) {
+		t.Errorf("expected clusters to be degraded with reason %s got %v", GatewayReasonManifestApplyFailed, got)
+	}
+	if got.Message != "degraded clusters [c2: AddressesPending, c3: ManifestApplyFailed, c4: Pending]" {
+		t.Errorf("unexpected degraded clusters message %q", got.Message)
+	}
+
+	healthy := &clusterStatus{Targeted: []string{"c1"}, Placed: []string{"c1"}}
+	got = buildClustersDegradedCondition(1, healthy, buildProgrammedCondition(1, healthy, v1.ConditionTrue, nil))
+	if got.Status != v1.ConditionFalse || got.Reason != string(GatewayReasonClustersHealthy) {
+		t.Errorf("expected clusters to be healthy got %v", got)
+	}
+}
+
+func TestBuildListenerStatus(t *testing.T) {
+	downstream := gatewayapiv1.ListenerStatus{
+		Name:           "api",
+		AttachedRoutes: 3,
+		SupportedKinds: []gatewayapiv1.RouteGroupKind{{Kind: "HTTPRoute"}},
+		Conditions: []v1.Condition{
+			{
+				Type:               string(gatewayapiv1.ListenerConditionResolvedRefs),
+				Status:             v1.ConditionFalse,
+				Reason:             string(gatewayapiv1.ListenerReasonInvalidCertificateRef),
+				ObservedGeneration: 7,
+			},
+		},
+	}
+	got := buildListenerStatus(2, "c1", downstream)
+	if got.Name != "c1.api" || got.AttachedRoutes != 3 {
+		t.Fatalf("expected listener c1.api with 3 attached routes got %v", got)
+	}
+	if len(got.SupportedKinds) != 1 || got.SupportedKinds[0].Kind != "HTTPRoute" {
+		t.Fatalf("expected supported kinds from the cluster got %v", got.SupportedKinds)
+	}
+	if len(got.Conditions) != 1 || got.Conditions[0].Reason != string(gatewayapiv1.ListenerReasonInvalidCertificateRef) || got.Conditions[0].ObservedGeneration != 2 {
+		t.Fatalf("expected conditions from the cluster at the hub generation got %v", got.Conditions)
+	}
+	if downstream.Conditions[0].ObservedGeneration != 7 {
+		t.Fatalf("expected downstream status to be unchanged")
+	}
+}
+
+func TestGatewayReconciler_placementGateway(t *testing.T) {
+	scheme := testutil.GetValidTestScheme()
+	if err := kuadrantdnsv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	record := &kuadrantdnsv1alpha1.DNSRecord{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "test-gw-api",
+			Namespace: "default",
+			Labels: map[string]string{
+				DNSRecordGatewayLabel:          "test-gw",
+				DNSRecordGatewayNamespaceLabel: "default",
+			},
+		},
+		Spec: kuadrantdnsv1alpha1.DNSRecordSpec{
+			Endpoints: []*kuadrantdnsv1alpha1.Endpoint{{DNSName: "api.example.com", RecordTTL: 60}, {DNSName: "lb.example.com", RecordTTL: 300}},
+		},
+	}
+
+	testCases := []struct {
+		name        string
+		annotations map[string]string
+		params      *Params
+		records     []client.Object
+		want        string
+		wantErr     bool
+	}{
+		{
+			name:   "no grace period in params uses the default",
+			params: &Params{},
+		},
+		{
+			name:   "grace period from params",
+			params: &Params{GracePeriod: "5m"},
+			want:   "5m0s",
+		},
+		{
+			name:        "gateway annotation overrides params",
+			annotations: map[string]string{gracePeriod.GracePeriodAnnotation: "1m"},
+			params:      &Params{GracePeriod: "5m"},
+			want:        "1m",
+		},
+		{
+			name:    "grace period derived from the highest DNS record TTL",
+			params:  &Params{GracePeriod: "5m", GracePeriodFromDNSTTL: true},
+			records: []client.Object{record},
+			want:    "50m0s",
+		},
+		{
+			name:   "grace period from params when there are no DNS records",
+			params: &Params{GracePeriod: "5m", GracePeriodFromDNSTTL: true},
+			want:   "5m0s",
+		},
+		{
+			name:    "invalid grace period in params",
+			params:  &Params{GracePeriod: "soon"},
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			r := &GatewayReconciler{
+				Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(testCase.records...).Build(),
+				Scheme: scheme,
+			}
+			upstream := &gatewayapiv1.Gateway{
+				ObjectMeta: v1.ObjectMeta{
+					Name:        "test-gw",
+					Namespace:   "default",
+					Annotations: testCase.annotations,
+				},
+			}
+			got, err := r.placementGateway(context.TODO(), upstream, testCase.params)
+			if (err != nil) != testCase.wantErr {
+				t.Fatalf("placementGateway() error = %v, wantErr %v", err, testCase.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if period := got.GetAnnotations()[gracePeriod.GracePeriodAnnotation]; period != testCase.want {
+				t.Errorf("expected grace period %q got %q", testCase.want, period)
+			}
+		})
+	}
+}
+
 // helper functions
 func verifyTLSSecretTestResultsAsExpected(got []v1.Object, want []v1.Object, gateway *gatewayapiv1.Gateway) bool {
+	if len(got) != len(want) {
+		return false
+	}
 	for _, wantSecret := range want {
 		match := false
 		for _, gotSecret := range got {
@@ -646,3 +982,147 @@ func buildValidTestGatewaySpec() gatewayapiv1.GatewaySpec {
 		},
 	}
 }
+
+func TestGatewayReconciler_policiesToSync(t *testing.T) {
+	gatewayClass := func(name, controller, params string) *gatewayapiv1.GatewayClass {
+		return &gatewayapiv1.GatewayClass{
+			ObjectMeta: v1.ObjectMeta{Name: name},
+			Spec: gatewayapiv1.GatewayClassSpec{
+				ControllerName: gatewayapiv1.GatewayController(controller),
+				ParametersRef: &gatewayapiv1.ParametersReference{
+					Kind:      "ConfigMap",
+					Name:      params,
+					Namespace: testutil.Pointer(gatewayapiv1.Namespace(testutil.Namespace)),
+				},
+			},
+		}
+	}
+	paramsConfigMap := func(name, params string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: v1.ObjectMeta{Name: name, Namespace: testutil.Namespace},
+			Data:       map[string]string{"params": params},
+		}
+	}
+	policyCRD := func(name string) *apiextensionsv1.CustomResourceDefinition {
+		return &apiextensionsv1.CustomResourceDefinition{
+			ObjectMeta: v1.ObjectMeta{Name: name},
+			Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+				Versions: []apiextensionsv1.CustomResourceDefinitionVersion{{
+					Name: "v1beta2",
+					Schema: &apiextensionsv1.CustomResourceValidation{
+						OpenAPIV3Schema: &apiextensionsv1.JSONSchemaProps{
+							Properties: map[string]apiextensionsv1.JSONSchemaProps{
+								"spec": {Properties: map[string]apiextensionsv1.JSONSchemaProps{"targetRef": {}}},
+							},
+						},
+					},
+				}},
+			},
+		}
+	}
+	scheme := testutil.GetValidTestScheme()
+	if err := apiextensionsv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	mapper := meta.NewDefaultRESTMapper(nil)
+	for _, kind := range []string{"AuthPolicy", "RateLimitPolicy"} {
+		mapper.Add(schema.GroupVersionKind{Group: "kuadrant.io", Version: "v1beta2", Kind: kind}, meta.RESTScopeNamespace)
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithRESTMapper(mapper).WithObjects(
+		gatewayClass("class-a", ControllerName, "params-a"),
+		gatewayClass("class-b", ControllerName, "params-b"),
+		gatewayClass("class-invalid", ControllerName, "params-invalid"),
+		gatewayClass("class-unserved", ControllerName, "params-unserved"),
+		gatewayClass("other", "example.com/other-controller", "params-other"),
+		paramsConfigMap("params-a", `{"experimentalPolicySync": [{"group": "kuadrant.io", "version": "v1beta2", "resource": "authpolicies"}]}`),
+		paramsConfigMap("params-b", `{"experimentalPolicySync": [{"group": "kuadrant.io", "version": "v1beta2", "resource": "ratelimitpolicies"}, {"group": "kuadrant.io", "version": "v1beta2", "resource": "authpolicies"}]}`),
+		paramsConfigMap("params-invalid", `{"experimentalPolicySync": boop`),
+		paramsConfigMap("params-unserved", `{"experimentalPolicySync": [{"group": "example.com", "version": "v1", "resource": "unservedpolicies"}]}`),
+		paramsConfigMap("params-other", `{"experimentalPolicySync": [{"group": "example.com", "version": "v1", "resource": "otherpolicies"}]}`),
+		policyCRD("authpolicies.kuadrant.io"),
+		policyCRD("ratelimitpolicies.kuadrant.io"),
+	).Build()
+	r := &GatewayReconciler{Client: c}
+
+	// the policies of every managed class with valid params are synced, whichever gateway is reconciled
+	policies, err := r.policiesToSync(context.TODO())
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	expected := sets.New(
+		schema.GroupVersionResource{Group: "kuadrant.io", Version: "v1beta2", Resource: "authpolicies"},
+		schema.GroupVersionResource{Group: "kuadrant.io", Version: "v1beta2", Resource: "ratelimitpolicies"},
+	)
+	if !policies.Equal(expected) {
+		t.Fatalf("expected policies %v got %v", expected.UnsortedList(), policies.UnsortedList())
+	}
+}
+
+func TestGatewayReconciler_reconcilePolicyWatchesRemoved(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "kuadrant.io", Version: "v1beta2", Resource: "authpolicies"}
+	policy := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "kuadrant.io/v1beta2",
+		"kind":       "AuthPolicy",
+		"metadata": map[string]interface{}{
+			"name":            "test-policy",
+			"namespace":       testutil.Namespace,
+			"resourceVersion": "1",
+			"finalizers":      []interface{}{policysync.PolicySyncFinalizer},
+		},
+		"spec": map[string]interface{}{
+			"targetRef": map[string]interface{}{
+				"group": gatewayapiv1.GroupName,
+				"kind":  "Gateway",
+				"name":  testutil.DummyCRName,
+			},
+		},
+	}}
+	workname := placement.WorkName(policy)
+	work := &workv1.ManifestWork{ObjectMeta: v1.ObjectMeta{
+		Name:      workname,
+		Namespace: testutil.Cluster,
+		Labels:    map[string]string{placement.WorkManifestLabel: workname, policysync.PolicyWorkLabel: "true"},
+	}}
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{gvr: "AuthPolicyList"}, policy.DeepCopy())
+	informerFactory := dynamicinformer.NewDynamicSharedInformerFactory(dynamicClient, 0)
+	reg, err := informerFactory.ForResource(gvr).Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{})
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	informerFactory.Start(ctx.Done())
+	informerFactory.WaitForCacheSync(ctx.Done())
+
+	scheme := testutil.GetValidTestScheme()
+	if err := workv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(policy.DeepCopy(), work).Build()
+	r := &GatewayReconciler{
+		Client:                 c,
+		Placement:              fakeplacement.NewTestGatewayPlacer(),
+		PolicyInformersManager: policysync.NewPolicyInformersManager(informerFactory),
+		WatchedPolicies: map[schema.GroupVersionResource]policysync.PolicyWatch{
+			gvr: {Handler: &policysync.ResourceEventHandler{}, Registration: reg},
+		},
+	}
+
+	// no class syncs the policies anymore, so they are removed from the clusters before the watch is dropped
+	if err := r.reconcilePolicyWatches(ctx); err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	if _, ok := r.WatchedPolicies[gvr]; ok {
+		t.Fatalf("expected watch of %v to be removed", gvr)
+	}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(work), &workv1.ManifestWork{}); !apierrors.IsNotFound(err) {
+		t.Fatalf("expected policy work to be deleted got %v", err)
+	}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(policy), policy); err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	if len(policy.GetFinalizers()) != 0 {
+		t.Fatalf("expected policy sync finalizer to be released got %v", policy.GetFinalizers())
+	}
+}
@@ -19,26 +19,36 @@ package gateway
 import (
 	"context"
 	"fmt"
-	"strings"
 
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	gatewayapiv1 "sigs.k8s.io/gateway-api/apis/v1"
-
-	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/slice"
 )
 
 const (
 	ControllerName = "kuadrant.io/mgc-gw-controller"
 )
 
-func getSupportedClasses() []string {
-	return []string{"kuadrant-multi-cluster-gateway-instance-per-cluster"}
+// isManagedClass returns whether the class is handled by this controller
+func isManagedClass(gatewayClass *gatewayapiv1.GatewayClass) bool {
+	return gatewayClass.Spec.ControllerName == ControllerName
+}
+
+// isManagedGateway returns whether the class of the gateway is handled by this controller
+func isManagedGateway(ctx context.Context, c client.Client, gateway *gatewayapiv1.Gateway) (bool, error) {
+	gatewayClass := &gatewayapiv1.GatewayClass{}
+	if err := c.Get(ctx, client.ObjectKey{Name: string(gateway.Spec.GatewayClassName)}, gatewayClass); err != nil {
+		return false, client.IgnoreNotFound(err)
+	}
+	return isManagedClass(gatewayClass), nil
 }
 
 // GatewayClassReconciler reconciles a GatewayClass object
@@ -51,6 +61,7 @@ type GatewayClassReconciler struct {
 //+kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=gatewayclasses/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=gatewayclasses/finalizers,verbs=update
 //+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,verbs=get;list;watch
 
 func (r *GatewayClassReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	log := ctrllog.FromContext(ctx)
@@ -65,55 +76,40 @@ func (r *GatewayClassReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		return ctrl.Result{}, nil
 	}
 
-	if gatewayClassIsAccepted(previous) {
-		log.V(3).Info("GatewayClass already Accepted", "class", previous.Name)
-		return ctrl.Result{}, nil
+	// every class of this controller is supported, each with its own params.
+	// The class status is re-evaluated on every change to the class or its
+	// params, as params that were valid when the class was accepted may not be
+	gatewayclass := previous.DeepCopy()
+
+	params, err := getParams(ctx, r.Client, previous.Name)
+	if err == nil {
+		err = validateParams(ctx, r.Client, params)
 	}
 
-	gatewayclass := previous.DeepCopy()
-	supportedClasses := getSupportedClasses()
-
-	_, err = getParams(ctx, r.Client, previous.Name)
-
-	if !slice.ContainsString(supportedClasses, previous.Name) {
-		gatewayclass.Status = gatewayapiv1.GatewayClassStatus{
-			Conditions: []metav1.Condition{
-				{
-					LastTransitionTime: metav1.Now(),
-					Message:            fmt.Sprintf("Invalid Parameters - Unsupported class name %s. Must be one of [%v]", previous.Name, strings.Join(supportedClasses, ",")),
-					Reason:             string(gatewayapiv1.GatewayClassReasonInvalidParameters),
-					Status:             metav1.ConditionFalse,
-					Type:               string(gatewayapiv1.GatewayClassConditionStatusAccepted),
-					ObservedGeneration: previous.Generation,
-				},
-			},
-		}
-	} else if IsInvalidParamsError(err) {
-		gatewayclass.Status = gatewayapiv1.GatewayClassStatus{
-			Conditions: []metav1.Condition{
-				{
-					LastTransitionTime: metav1.Now(),
-					Message:            fmt.Sprintf("Invalid Parameters - %s", err.Error()),
-					Reason:             string(gatewayapiv1.GatewayClassReasonInvalidParameters),
-					Status:             metav1.ConditionFalse,
-					Type:               string(gatewayapiv1.GatewayClassConditionStatusAccepted),
-					ObservedGeneration: previous.Generation,
-				},
-			},
-		}
+	if IsInvalidParamsError(err) {
+		meta.SetStatusCondition(&gatewayclass.Status.Conditions, metav1.Condition{
+			Message:            fmt.Sprintf("Invalid Parameters - %s", err.Error()),
+			Reason:             string(gatewayapiv1.GatewayClassReasonInvalidParameters),
+			Status:             metav1.ConditionFalse,
+			Type:               string(gatewayapiv1.GatewayClassConditionStatusAccepted),
+			ObservedGeneration: previous.Generation,
+		})
+	} else if err != nil {
+		log.Error(err, "Unable to get GatewayClass params")
+		return ctrl.Result{}, err
 	} else {
-		gatewayclass.Status = gatewayapiv1.GatewayClassStatus{
-			Conditions: []metav1.Condition{
-				{
-					LastTransitionTime: metav1.Now(),
-					Message:            fmt.Sprintf("Handled by %s", ControllerName),
-					Reason:             string(gatewayapiv1.GatewayClassConditionStatusAccepted),
-					Status:             metav1.ConditionTrue,
-					Type:               string(gatewayapiv1.GatewayClassConditionStatusAccepted),
-					ObservedGeneration: previous.Generation,
-				},
-			},
-		}
+		meta.SetStatusCondition(&gatewayclass.Status.Conditions, metav1.Condition{
+			Message:            fmt.Sprintf("Handled by %s", ControllerName),
+			Reason:             string(gatewayapiv1.GatewayClassConditionStatusAccepted),
+			Status:             metav1.ConditionTrue,
+			Type:               string(gatewayapiv1.GatewayClassConditionStatusAccepted),
+			ObservedGeneration: previous.Generation,
+		})
+	}
+
+	if equality.Semantic.DeepEqual(previous.Status, gatewayclass.Status) {
+		log.V(3).Info("GatewayClass status unchanged", "class", previous.Name)
+		return ctrl.Result{}, nil
 	}
 
 	log.Info("Updating GatewayClass", "status", gatewayclass.Status)
@@ -125,19 +121,22 @@ func (r *GatewayClassReconciler) Reconcile(ctx context.Context, req ctrl.Request
 	return ctrl.Result{}, nil
 }
 
-func gatewayClassIsAccepted(gatewayClass *gatewayapiv1.GatewayClass) bool {
-	acceptedCondition := meta.FindStatusCondition(gatewayClass.Status.Conditions, string(gatewayapiv1.GatewayConditionAccepted))
-	return (acceptedCondition != nil && acceptedCondition.Status == metav1.ConditionTrue)
-}
-
 // SetupWithManager sets up the controller with the Manager.
 func (r *GatewayClassReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		// Uncomment the following line adding a pointer to an instance of the controlled resource as an argument
-		For(&gatewayapiv1.GatewayClass{}).
-		WithEventFilter(predicate.NewPredicateFuncs(func(object client.Object) bool {
-			gatewayClass := object.(*gatewayapiv1.GatewayClass)
-			return gatewayClass.Spec.ControllerName == ControllerName
-		})).
-		Complete(r)
+	paramsEventMapper := NewGatewayClassEventMapper(mgr.GetLogger(), mgr.GetClient())
+	controller := ctrl.NewControllerManagedBy(mgr).
+		For(&gatewayapiv1.GatewayClass{}, builder.WithPredicates(predicate.NewPredicateFuncs(func(object client.Object) bool {
+			return isManagedClass(object.(*gatewayapiv1.GatewayClass))
+		})))
+
+	// re-evaluate the classes when the params they reference change
+	params, err := paramsObjects(mgr.GetScheme(), mgr.GetRESTMapper())
+	if err != nil {
+		return err
+	}
+	for _, obj := range params {
+		controller = controller.Watches(obj, handler.EnqueueRequestsFromMapFunc(paramsEventMapper.MapParamsToGatewayClass))
+	}
+
+	return controller.Complete(r)
 }
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -37,7 +38,7 @@ func TestGatewayClassReconciler_Reconcile(t *testing.T) {
 						Items: []gatewayapiv1.GatewayClass{
 							{
 								ObjectMeta: v1.ObjectMeta{
-									Name: testutil.DummyCRName,
+									Name: testutil.MultiClusterGatewayClassName,
 								},
 								Status: gatewayapiv1.GatewayClassStatus{
 									Conditions: []v1.Condition{
@@ -53,9 +54,66 @@ func TestGatewayClassReconciler_Reconcile(t *testing.T) {
 				),
 			},
 			args: args{
-				req: buildGCTestRequest(),
+				req: ctrl.Request{
+					NamespacedName: types.NamespacedName{
+						Name: testutil.MultiClusterGatewayClassName,
+					},
+				},
 			},
-			verify: verifyGatewayClassAcceptance(testutil.DummyCRName, true),
+			verify: verifyGatewayClassAcceptance(testutil.MultiClusterGatewayClassName, true),
+		},
+		{
+			name: "Accepted gateway class re-evaluated after params become invalid",
+			fields: fields{
+				Client: testutil.GetValidTestClient(
+					&gatewayapiv1.GatewayClassList{
+						Items: []gatewayapiv1.GatewayClass{
+							{
+								ObjectMeta: v1.ObjectMeta{
+									Name: testutil.MultiClusterGatewayClassName,
+								},
+								Spec: gatewayapiv1.GatewayClassSpec{
+									ParametersRef: &gatewayapiv1.ParametersReference{
+										Group:     "",
+										Kind:      "ConfigMap",
+										Name:      "test-params",
+										Namespace: testutil.Pointer(gatewayapiv1.Namespace(testutil.Namespace)),
+									},
+								},
+								Status: gatewayapiv1.GatewayClassStatus{
+									Conditions: []v1.Condition{
+										{
+											Type:   string(gatewayapiv1.GatewayConditionAccepted),
+											Status: v1.ConditionTrue,
+										},
+									},
+								},
+							},
+						},
+					},
+					&corev1.ConfigMapList{
+						Items: []corev1.ConfigMap{
+							{
+								ObjectMeta: v1.ObjectMeta{
+									Name:      "test-params",
+									Namespace: testutil.Namespace,
+								},
+								Data: map[string]string{
+									"params": `{"downstreamClass": "istio" boop`,
+								},
+							},
+						},
+					},
+				),
+			},
+			args: args{
+				req: ctrl.Request{
+					NamespacedName: types.NamespacedName{
+						Name: testutil.MultiClusterGatewayClassName,
+					},
+				},
+			},
+			verify: verifyGatewayClassAcceptance(testutil.MultiClusterGatewayClassName, false),
 		},
 		{
 			name: "Gateway class being accepted",
@@ -65,7 +123,7 @@ func TestGatewayClassReconciler_Reconcile(t *testing.T) {
 						Items: []gatewayapiv1.GatewayClass{
 							{
 								ObjectMeta: v1.ObjectMeta{
-									Name: getSupportedClasses()[0],
+									Name: testutil.MultiClusterGatewayClassName,
 								},
 								Spec: gatewayapiv1.GatewayClassSpec{
 									ParametersRef: &gatewayapiv1.ParametersReference{
@@ -96,14 +154,14 @@ func TestGatewayClassReconciler_Reconcile(t *testing.T) {
 			args: args{
 				req: ctrl.Request{
 					NamespacedName: types.NamespacedName{
-						Name: getSupportedClasses()[0],
+						Name: testutil.MultiClusterGatewayClassName,
 					},
 				},
 			},
-			verify: verifyGatewayClassAcceptance(getSupportedClasses()[0], true),
+			verify: verifyGatewayClassAcceptance(testutil.MultiClusterGatewayClassName, true),
 		},
 		{
-			name: "Unsupported class name",
+			name: "Gateway class with any name accepted",
 			fields: fields{
 				Client: testutil.GetValidTestClient(
 					&gatewayapiv1.GatewayClassList{
@@ -112,6 +170,9 @@ func TestGatewayClassReconciler_Reconcile(t *testing.T) {
 								ObjectMeta: v1.ObjectMeta{
 									Name: testutil.DummyCRName,
 								},
+								Spec: gatewayapiv1.GatewayClassSpec{
+									ControllerName: ControllerName,
+								},
 							},
 						},
 					},
@@ -120,7 +181,7 @@ func TestGatewayClassReconciler_Reconcile(t *testing.T) {
 			args: args{
 				req: buildGCTestRequest(),
 			},
-			verify: verifyGatewayClassAcceptance(testutil.DummyCRName, false),
+			verify: verifyGatewayClassAcceptance(testutil.DummyCRName, true),
 		},
 		{
 			name: "Invalid Parameters in config map",
@@ -130,7 +191,7 @@ func TestGatewayClassReconciler_Reconcile(t *testing.T) {
 						Items: []gatewayapiv1.GatewayClass{
 							{
 								ObjectMeta: v1.ObjectMeta{
-									Name: getSupportedClasses()[0],
+									Name: testutil.MultiClusterGatewayClassName,
 								},
 								Spec: gatewayapiv1.GatewayClassSpec{
 									ParametersRef: &gatewayapiv1.ParametersReference{
@@ -161,11 +222,56 @@ func TestGatewayClassReconciler_Reconcile(t *testing.T) {
 			args: args{
 				req: ctrl.Request{
 					NamespacedName: types.NamespacedName{
-						Name: getSupportedClasses()[0],
+						Name: testutil.MultiClusterGatewayClassName,
+					},
+				},
+			},
+			verify: verifyGatewayClassAcceptance(testutil.MultiClusterGatewayClassName, false),
+		},
+		{
+			name: "Policy to sync not served by the hub",
+			fields: fields{
+				Client: testutil.GetValidTestClient(
+					&gatewayapiv1.GatewayClassList{
+						Items: []gatewayapiv1.GatewayClass{
+							{
+								ObjectMeta: v1.ObjectMeta{
+									Name: testutil.MultiClusterGatewayClassName,
+								},
+								Spec: gatewayapiv1.GatewayClassSpec{
+									ParametersRef: &gatewayapiv1.ParametersReference{
+										Group:     "",
+										Kind:      "ConfigMap",
+										Name:      "test-params",
+										Namespace: testutil.Pointer(gatewayapiv1.Namespace(testutil.Namespace)),
+									},
+								},
+							},
+						},
+					},
+					&corev1.ConfigMapList{
+						Items: []corev1.ConfigMap{
+							{
+								ObjectMeta: v1.ObjectMeta{
+									Name:      "test-params",
+									Namespace: testutil.Namespace,
+								},
+								Data: map[string]string{
+									"params": `{"experimentalPolicySync": [{"group": "kuadrant.io", "version": "v1beta1", "resource": "authpolicies"}]}`,
+								},
+							},
+						},
+					},
+				),
+			},
+			args: args{
+				req: ctrl.Request{
+					NamespacedName: types.NamespacedName{
+						Name: testutil.MultiClusterGatewayClassName,
 					},
 				},
 			},
-			verify: verifyGatewayClassAcceptance(getSupportedClasses()[0], false),
+			verify: verifyGatewayClassAcceptance(testutil.MultiClusterGatewayClassName, false),
 		},
 		{
 			name: "Gateway class not found",
@@ -175,7 +281,7 @@ func TestGatewayClassReconciler_Reconcile(t *testing.T) {
 						Items: []gatewayapiv1.GatewayClass{
 							{
 								ObjectMeta: v1.ObjectMeta{
-									Name: getSupportedClasses()[0],
+									Name: testutil.MultiClusterGatewayClassName,
 								},
 							},
 						},
@@ -222,8 +328,46 @@ func verifyGatewayClassAcceptance(name string, want bool) func(c client.Client,
 		if err != nil {
 			t.Fatalf("error getting gateway class from client: %s", err)
 		}
-		if want != gatewayClassIsAccepted(class) {
+		if want != meta.IsStatusConditionTrue(class.Status.Conditions, string(gatewayapiv1.GatewayClassConditionStatusAccepted)) {
 			t.Fatalf("controller ignored or not accepted gateway class")
 		}
 	}
 }
+
+func TestIsManagedGateway(t *testing.T) {
+	c := testutil.GetValidTestClient(
+		&gatewayapiv1.GatewayClassList{
+			Items: []gatewayapiv1.GatewayClass{
+				{
+					ObjectMeta: v1.ObjectMeta{Name: "mgc-istio"},
+					Spec:       gatewayapiv1.GatewayClassSpec{ControllerName: ControllerName},
+				},
+				{
+					ObjectMeta: v1.ObjectMeta{Name: "mgc-envoy-gateway"},
+					Spec:       gatewayapiv1.GatewayClassSpec{ControllerName: ControllerName},
+				},
+				{
+					ObjectMeta: v1.ObjectMeta{Name: "istio"},
+					Spec:       gatewayapiv1.GatewayClassSpec{ControllerName: "istio.io/gateway-controller"},
+				},
+			},
+		},
+	)
+
+	cases := map[string]bool{
+		"mgc-istio":         true,
+		"mgc-envoy-gateway": true,
+		"istio":             false,
+		"missing":           false,
+	}
+	for className, expected := range cases {
+		gateway := testutil.NewGatewayBuilder("gw", className, testutil.Namespace).Gateway
+		managed, err := isManagedGateway(context.TODO(), c, gateway)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if managed != expected {
+			t.Errorf("expected gateway of class %s managed to be %v, got %v", className, expected, managed)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package gateway
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	gatewayapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// GatewayClassEventMapper maps GatewayClass and GatewayClass params object events to the
+// classes and gateways affected by them.
+//
+// A params object is any object kind that can be resolved by the paramsResolvers (e.g. ConfigMap)
+type GatewayClassEventMapper struct {
+	Logger logr.Logger
+	Client client.Client
+}
+
+func NewGatewayClassEventMapper(logger logr.Logger, client client.Client) *GatewayClassEventMapper {
+	log := logger.WithName("GatewayClassEventMapper")
+	return &GatewayClassEventMapper{
+		Logger: log,
+		Client: client,
+	}
+}
+
+// MapParamsToGatewayClass enqueues the classes whose parametersRef references the params object
+func (m *GatewayClassEventMapper) MapParamsToGatewayClass(ctx context.Context, obj client.Object) []reconcile.Request {
+	requests := []reconcile.Request{}
+	for _, class := range m.classesForParams(ctx, obj) {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKey{Name: class}})
+	}
+	return requests
+}
+
+// MapParamsToGateway enqueues the gateways of the classes whose parametersRef references the params object
+func (m *GatewayClassEventMapper) MapParamsToGateway(ctx context.Context, obj client.Object) []reconcile.Request {
+	return m.gatewaysForClasses(ctx, m.classesForParams(ctx, obj)...)
+}
+
+// MapToGateway enqueues the gateways of the class
+func (m *GatewayClassEventMapper) MapToGateway(ctx context.Context, obj client.Object) []reconcile.Request {
+	class, ok := obj.(*gatewayapiv1.GatewayClass)
+	if !ok || !isManagedClass(class) {
+		return []reconcile.Request{}
+	}
+	return m.gatewaysForClasses(ctx, class.Name)
+}
+
+func (m *GatewayClassEventMapper) classesForParams(ctx context.Context, obj client.Object) []string {
+	logger := m.Logger.V(1).WithValues("object", client.ObjectKeyFromObject(obj))
+
+	gvk, err := apiutil.GVKForObject(obj, m.Client.Scheme())
+	if err != nil {
+		logger.Info("classesForParams:", "error", "failed to get kind of params object")
+		return []string{}
+	}
+
+	classList := &gatewayapiv1.GatewayClassList{}
+	if err := m.Client.List(ctx, classList); err != nil {
+		logger.Info("classesForParams:", "error", "failed to get gateway classes")
+		return []string{}
+	}
+
+	classes := []string{}
+	for _, class := range classList.Items {
+		if !isManagedClass(&class) || !paramsRefersTo(class.Spec.ParametersRef, gvk.GroupKind(), obj) {
+			continue
+		}
+		classes = append(classes, class.Name)
+	}
+	return classes
+}
+
+func (m *GatewayClassEventMapper) gatewaysForClasses(ctx context.Context, classes ...string) []reconcile.Request {
+	requests := []reconcile.Request{}
+	if len(classes) == 0 {
+		return requests
+	}
+
+	gatewayList := &gatewayapiv1.GatewayList{}
+	if err := m.Client.List(ctx, gatewayList); err != nil {
+		m.Logger.V(1).Info("gatewaysForClasses:", "error", "failed to get gateways")
+		return requests
+	}
+
+	for _, gw := range gatewayList.Items {
+		for _, class := range classes {
+			if string(gw.Spec.GatewayClassName) == class {
+				requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&gw)})
+				break
+			}
+		}
+	}
+	return requests
+}
+
+// paramsRefersTo returns whether the parametersRef of a class references the object
+func paramsRefersTo(paramsRef *gatewayapiv1.ParametersReference, groupKind schema.GroupKind, obj client.Object) bool {
+	if paramsRef == nil {
+		return false
+	}
+	if string(paramsRef.Group) != groupKind.Group || string(paramsRef.Kind) != groupKind.Kind || paramsRef.Name != obj.GetName() {
+		return false
+	}
+	namespace := ""
+	if paramsRef.Namespace != nil {
+		namespace = string(*paramsRef.Namespace)
+	}
+	return namespace == obj.GetNamespace()
+}
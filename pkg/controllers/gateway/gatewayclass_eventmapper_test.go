@@ -0,0 +1,150 @@
+//go:build unit
+
+package gateway
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	crlog "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	gatewayapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
+	testutil "github.com/Kuadrant/multicluster-gateway-controller/test/util"
+)
+
+func TestGatewayClassEventMapper(t *testing.T) {
+	class := func(name, controllerName, paramsName string) gatewayapiv1.GatewayClass {
+		return gatewayapiv1.GatewayClass{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: gatewayapiv1.GatewayClassSpec{
+				ControllerName: gatewayapiv1.GatewayController(controllerName),
+				ParametersRef: &gatewayapiv1.ParametersReference{
+					Group:     "",
+					Kind:      "ConfigMap",
+					Name:      paramsName,
+					Namespace: testutil.Pointer(gatewayapiv1.Namespace(testutil.Namespace)),
+				},
+			},
+		}
+	}
+	gateway := func(name, className string) gatewayapiv1.Gateway {
+		return gatewayapiv1.Gateway{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testutil.Namespace},
+			Spec:       gatewayapiv1.GatewaySpec{GatewayClassName: gatewayapiv1.ObjectName(className)},
+		}
+	}
+	params := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "params", Namespace: testutil.Namespace},
+	}
+
+	c := fake.NewClientBuilder().WithScheme(testutil.GetValidTestScheme()).WithLists(
+		&gatewayapiv1.GatewayClassList{
+			Items: []gatewayapiv1.GatewayClass{
+				class("mgc", ControllerName, "params"),
+				class("mgc-other-params", ControllerName, "other-params"),
+				class("other-controller", "example.com/gateway-controller", "params"),
+			},
+		},
+		&gatewayapiv1.GatewayList{
+			Items: []gatewayapiv1.Gateway{
+				gateway("mgc-gw", "mgc"),
+				gateway("mgc-other-params-gw", "mgc-other-params"),
+				gateway("other-controller-gw", "other-controller"),
+			},
+		},
+	).Build()
+	mapper := NewGatewayClassEventMapper(crlog.Log, c)
+
+	cases := []struct {
+		name     string
+		mapFunc  func(context.Context, client.Object) []reconcile.Request
+		obj      client.Object
+		expected []types.NamespacedName
+	}{
+		{
+			name:     "params change enqueues the classes referencing them",
+			mapFunc:  mapper.MapParamsToGatewayClass,
+			obj:      params,
+			expected: []types.NamespacedName{{Name: "mgc"}},
+		},
+		{
+			name:     "params change enqueues the gateways of the classes referencing them",
+			mapFunc:  mapper.MapParamsToGateway,
+			obj:      params,
+			expected: []types.NamespacedName{{Namespace: testutil.Namespace, Name: "mgc-gw"}},
+		},
+		{
+			name:    "unreferenced params change is ignored",
+			mapFunc: mapper.MapParamsToGateway,
+			obj: &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "params", Namespace: "other-namespace"},
+			},
+			expected: []types.NamespacedName{},
+		},
+		{
+			name:     "class change enqueues the gateways of the class",
+			mapFunc:  mapper.MapToGateway,
+			obj:      testutil.Pointer(class("mgc-other-params", ControllerName, "other-params")),
+			expected: []types.NamespacedName{{Namespace: testutil.Namespace, Name: "mgc-other-params-gw"}},
+		},
+		{
+			name:     "class of another controller is ignored",
+			mapFunc:  mapper.MapToGateway,
+			obj:      testutil.Pointer(class("other-controller", "example.com/gateway-controller", "params")),
+			expected: []types.NamespacedName{},
+		},
+	}
+
+	for _, testCase := range cases {
+		t.Run(testCase.name, func(t *testing.T) {
+			requests := testCase.mapFunc(context.Background(), testCase.obj)
+			if len(requests) != len(testCase.expected) {
+				t.Fatalf("expected %v to be enqueued, got %v", testCase.expected, requests)
+			}
+			for i, key := range testCase.expected {
+				if requests[i].NamespacedName != key {
+					t.Errorf("expected %v to be enqueued, got %v", key, requests)
+				}
+			}
+		})
+	}
+}
+
+func TestParamsObjects(t *testing.T) {
+	scheme := testutil.GetValidTestScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{corev1.SchemeGroupVersion, v1alpha1.GroupVersion})
+	mapper.Add(corev1.SchemeGroupVersion.WithKind("ConfigMap"), meta.RESTScopeNamespace)
+
+	// params kinds without a CRD installed are not watched
+	objects, err := paramsObjects(scheme, mapper)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(objects) != 1 {
+		t.Fatalf("expected only the ConfigMap params object, got %v", objects)
+	}
+	if _, ok := objects[0].(*corev1.ConfigMap); !ok {
+		t.Fatalf("expected a ConfigMap params object, got %T", objects[0])
+	}
+
+	mapper.Add(v1alpha1.GroupVersion.WithKind("MultiClusterGatewayParameters"), meta.RESTScopeNamespace)
+	objects, err = paramsObjects(scheme, mapper)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(objects) != len(paramsResolvers) {
+		t.Fatalf("expected an object for each params resolver, got %v", objects)
+	}
+}
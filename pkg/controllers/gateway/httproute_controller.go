@@ -0,0 +1,663 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	workv1 "open-cluster-management.io/api/work/v1"
+
+	rbac "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/cache"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	crlog "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	gatewayapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayapiv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/metadata"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/placement"
+)
+
+const (
+	RouteWorkLabel        = LabelPrefix + "route"
+	RouteParentAnnotation = LabelPrefix + "route-parent"
+	RouteSyncFinalizer    = LabelPrefix + "route-sync"
+
+	routeParentsFeedback = "parents"
+	routeRBACName        = "open-cluster-management:klusterlet-work:httproute"
+	routeRBACWork        = "httproute-rbac"
+	// routeReferenceGrantName is the ReferenceGrant placed in the namespace of the route
+	routeReferenceGrantName = "httproute-%s"
+)
+
+// routeParent is a parentRef of a hub route to a multicluster gateway
+type routeParent struct {
+	Ref      gatewayapiv1.ParentReference
+	Gateway  *gatewayapiv1.Gateway
+	Clusters sets.Set[string]
+	// DownstreamNamespace is the namespace of the downstream gateway in the clusters
+	DownstreamNamespace string
+	// Params are the params of the class of the gateway
+	Params *Params
+}
+
+// HTTPRouteReconciler syncs HTTPRoutes attached to multicluster gateways into each of
+// the clusters those gateways are placed on via OCM ManifestWork. The parentRefs of the
+// synced route are rewritten to point to the downstream gateways, and the route status
+// reported by each cluster is aggregated back onto the hub route
+type HTTPRouteReconciler struct {
+	client.Client
+	Scheme    *runtime.Scheme
+	Placement GatewayPlacer
+	// WorkAgentSubject is the subject of the work agent on the spokes allowed to manage the
+	// routes. Defaults to the placement.DefaultWorkAgentSubject
+	WorkAgentSubject rbac.Subject
+}
+
+// +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=httproutes,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=httproutes/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=httproutes/finalizers,verbs=update
+
+func (r *HTTPRouteReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := crlog.FromContext(ctx)
+
+	route := &gatewayapiv1.HTTPRoute{}
+	if err := r.Client.Get(ctx, req.NamespacedName, route); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	route.SetGroupVersionKind(gatewayapiv1.SchemeGroupVersion.WithKind("HTTPRoute"))
+	workname := placement.WorkName(route)
+
+	if route.GetDeletionTimestamp() != nil {
+		log.V(3).Info("removing deleted route from clusters", "route", req.NamespacedName)
+		if err := r.removeWorks(ctx, workname, sets.New[string]()); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, r.ensureFinalizer(ctx, route, false)
+	}
+
+	parents, err := r.multiClusterParents(ctx, route)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// the parentRefs synced into each cluster are those of the gateways placed on it
+	clusterParents := map[string][]routeParent{}
+	for _, parent := range parents {
+		for _, cluster := range sets.List(parent.Clusters) {
+			clusterParents[cluster] = append(clusterParents[cluster], parent)
+		}
+	}
+
+	if len(clusterParents) > 0 {
+		// track the synced copies so they are removed before the route is deleted
+		if err := r.ensureFinalizer(ctx, route, true); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	synced := sets.New[string]()
+	for cluster, clusterRouteParents := range clusterParents {
+		log.V(3).Info("syncing route to cluster", "route", req.NamespacedName, "cluster", cluster)
+		if err := r.routeRBAC(ctx, cluster); err != nil {
+			return ctrl.Result{}, err
+		}
+		work, err := buildRouteWork(workname, cluster, route, clusterRouteParents)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if err := r.createUpdateWork(ctx, work); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to sync route %s to cluster %s: %w", req.NamespacedName, cluster, err)
+		}
+		synced.Insert(cluster)
+	}
+
+	// remove the route from any cluster its gateways are no longer placed on
+	if err := r.removeWorks(ctx, workname, synced); err != nil {
+		return ctrl.Result{}, err
+	}
+	if synced.Len() == 0 {
+		if err := r.ensureFinalizer(ctx, route, false); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	works := &workv1.ManifestWorkList{}
+	if err := r.Client.List(ctx, works, client.MatchingLabels{placement.WorkManifestLabel: workname}); err != nil {
+		return ctrl.Result{}, err
+	}
+	previous := route.Status.DeepCopy()
+	route.Status.Parents = aggregateRouteParents(route, parents, works.Items)
+	if equality.Semantic.DeepEqual(previous, &route.Status) {
+		return ctrl.Result{}, nil
+	}
+	log.V(3).Info("updating route status", "route", req.NamespacedName, "parents", route.Status.Parents)
+	return ctrl.Result{}, r.Client.Status().Update(ctx, route)
+}
+
+// multiClusterParents returns the parentRefs of the route to multicluster gateways,
+// along with the clusters each gateway has been placed on
+func (r *HTTPRouteReconciler) multiClusterParents(ctx context.Context, route *gatewayapiv1.HTTPRoute) ([]routeParent, error) {
+	parents := []routeParent{}
+	for _, ref := range route.Spec.ParentRefs {
+		if !isGatewayParentRef(ref) {
+			continue
+		}
+		gateway := &gatewayapiv1.Gateway{}
+		if err := r.Client.Get(ctx, client.ObjectKey{Name: string(ref.Name), Namespace: parentNamespace(route, ref)}, gateway); err != nil {
+			if k8serrors.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		managed, err := isManagedGateway(ctx, r.Client, gateway)
+		if err != nil {
+			return nil, err
+		}
+		if !managed {
+			continue
+		}
+		clusters := sets.New[string]()
+		downstreamNS := ""
+		var params *Params
+		if gateway.GetDeletionTimestamp() == nil {
+			placed, err := r.Placement.GetPlacedClusters(ctx, gateway)
+			if err != nil {
+				return nil, err
+			}
+			clusters = placed
+			if params, err = getParams(ctx, r.Client, string(gateway.Spec.GatewayClassName)); err != nil {
+				return nil, err
+			}
+			if downstreamNS, err = downstreamNamespace(gateway, params); err != nil {
+				return nil, err
+			}
+		}
+		parents = append(parents, routeParent{Ref: ref, Gateway: gateway, Clusters: clusters, DownstreamNamespace: downstreamNS, Params: params})
+	}
+	return parents, nil
+}
+
+// removeWorks deletes the ManifestWork syncing the route from every cluster except those to keep
+func (r *HTTPRouteReconciler) removeWorks(ctx context.Context, workname string, keep sets.Set[string]) error {
+	works := &workv1.ManifestWorkList{}
+	if err := r.Client.List(ctx, works, client.MatchingLabels{placement.WorkManifestLabel: workname}); err != nil {
+		return err
+	}
+	for i := range works.Items {
+		if keep.Has(works.Items[i].Namespace) {
+			continue
+		}
+		if err := r.Client.Delete(ctx, &works.Items[i]); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+		if err := r.removeRouteRBAC(ctx, works.Items[i].Namespace, workname); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeRouteRBAC removes the RBAC of the work agent from the cluster once no other route is synced to it
+func (r *HTTPRouteReconciler) removeRouteRBAC(ctx context.Context, cluster, workname string) error {
+	works := &workv1.ManifestWorkList{}
+	if err := r.Client.List(ctx, works, client.InNamespace(cluster), client.MatchingLabels{RouteWorkLabel: "true"}); err != nil {
+		return err
+	}
+	for _, work := range works.Items {
+		if work.Name != workname && work.DeletionTimestamp == nil {
+			return nil
+		}
+	}
+	rbacWork := &workv1.ManifestWork{ObjectMeta: metav1.ObjectMeta{Name: routeRBACWork, Namespace: cluster}}
+	return client.IgnoreNotFound(r.Client.Delete(ctx, rbacWork))
+}
+
+// ensureFinalizer adds or removes the route sync finalizer from the hub route
+func (r *HTTPRouteReconciler) ensureFinalizer(ctx context.Context, route *gatewayapiv1.HTTPRoute, present bool) error {
+	if controllerutil.ContainsFinalizer(route, RouteSyncFinalizer) == present {
+		return nil
+	}
+	if present {
+		controllerutil.AddFinalizer(route, RouteSyncFinalizer)
+	} else {
+		controllerutil.RemoveFinalizer(route, RouteSyncFinalizer)
+	}
+	return client.IgnoreNotFound(r.Client.Update(ctx, route))
+}
+
+// buildRouteWork builds the ManifestWork syncing the route into the cluster, attached to
+// the downstream gateways placed on it. A copy of the route is placed in the namespace of
+// each downstream gateway, so it is allowed by the default allowedRoutes of the listeners.
+// The backends keep being referenced in the namespace of the route, which grants the copies
+// access to them. The labels and annotations of the route are propagated as they are for the
+// first of its gateways in the namespace
+func buildRouteWork(workname, cluster string, route *gatewayapiv1.HTTPRoute, parents []routeParent) (workv1.ManifestWork, error) {
+	namespaceRefs := map[string][]gatewayapiv1.ParentReference{}
+	namespaceParams := map[string]*Params{}
+	for _, parent := range parents {
+		namespace := parent.DownstreamNamespace
+		if _, ok := namespaceRefs[namespace]; !ok {
+			namespaceParams[namespace] = parent.Params
+		}
+		namespaceRefs[namespace] = append(namespaceRefs[namespace], downstreamParentRef(parent))
+	}
+
+	manifests := []workv1.Manifest{}
+	manifestConfigs := []workv1.ManifestConfigOption{}
+	grant := &gatewayapiv1beta1.ReferenceGrant{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: gatewayapiv1beta1.GroupVersion.String(),
+			Kind:       "ReferenceGrant",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf(routeReferenceGrantName, route.Name),
+			Namespace: route.Namespace,
+		},
+	}
+	for _, namespace := range sets.List(sets.KeySet(namespaceRefs)) {
+		downstream := &gatewayapiv1.HTTPRoute{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: gatewayapiv1.GroupVersion.String(),
+				Kind:       "HTTPRoute",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      route.Name,
+				Namespace: namespace,
+			},
+			Spec: *route.Spec.DeepCopy(),
+		}
+		// the labels and annotations the hub uses are not propagated by default
+		var propagatedLabels, propagatedAnnotations *MetadataPropagation
+		if params := namespaceParams[namespace]; params != nil {
+			propagatedLabels, propagatedAnnotations = params.PropagatedLabels, params.PropagatedAnnotations
+		}
+		metadata.CopyLabelsPredicate(route, downstream, propagatedLabels.predicate())
+		metadata.CopyAnnotationsPredicate(route, downstream, propagatedAnnotations.predicate())
+		downstream.Spec.ParentRefs = namespaceRefs[namespace]
+		if namespace != route.Namespace {
+			if backends := qualifyBackendRefs(&downstream.Spec, route.Namespace); len(backends) > 0 {
+				grant.Spec.From = append(grant.Spec.From, gatewayapiv1beta1.ReferenceGrantFrom{
+					Group:     gatewayapiv1.GroupName,
+					Kind:      "HTTPRoute",
+					Namespace: gatewayapiv1.Namespace(namespace),
+				})
+				grant.Spec.To = backends
+			}
+		}
+
+		raw, err := json.Marshal(downstream)
+		if err != nil {
+			return workv1.ManifestWork{}, err
+		}
+		manifests = append(manifests, workv1.Manifest{RawExtension: runtime.RawExtension{Raw: raw}})
+		manifestConfigs = append(manifestConfigs, workv1.ManifestConfigOption{
+			ResourceIdentifier: workv1.ResourceIdentifier{
+				Group:     gatewayapiv1.GroupName,
+				Resource:  "httproutes",
+				Name:      downstream.Name,
+				Namespace: downstream.Namespace,
+			},
+			FeedbackRules: []workv1.FeedbackRule{
+				{
+					Type: workv1.JSONPathsType,
+					JsonPaths: []workv1.JsonPath{
+						{
+							Name: routeParentsFeedback,
+							Path: ".status.parents",
+						},
+					},
+				},
+			},
+		})
+	}
+
+	if len(grant.Spec.From) > 0 {
+		raw, err := json.Marshal(grant)
+		if err != nil {
+			return workv1.ManifestWork{}, err
+		}
+		manifests = append(manifests, workv1.Manifest{RawExtension: runtime.RawExtension{Raw: raw}})
+	}
+
+	return workv1.ManifestWork{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      workname,
+			Namespace: cluster,
+			Labels: map[string]string{
+				"kuadrant.io":               "managed",
+				placement.WorkManifestLabel: workname,
+				RouteWorkLabel:              "true",
+			},
+			Annotations: map[string]string{
+				RouteParentAnnotation: fmt.Sprintf("%s/%s", route.Namespace, route.Name),
+			},
+		},
+		Spec: workv1.ManifestWorkSpec{
+			Workload: workv1.ManifestsTemplate{
+				Manifests: manifests,
+			},
+			ManifestConfigs: manifestConfigs,
+		},
+	}, nil
+}
+
+// qualifyBackendRefs sets the namespace on the backendRefs of the route spec that don't have one, so they
+// keep referencing the backends in that namespace once the route is placed in another namespace. It returns
+// the backends referenced in the namespace, sorted
+func qualifyBackendRefs(spec *gatewayapiv1.HTTPRouteSpec, namespace string) []gatewayapiv1beta1.ReferenceGrantTo {
+	backends := map[string]gatewayapiv1beta1.ReferenceGrantTo{}
+	qualify := func(ref *gatewayapiv1.BackendObjectReference) {
+		if ref.Namespace != nil && *ref.Namespace != "" {
+			return
+		}
+		refNamespace := gatewayapiv1.Namespace(namespace)
+		ref.Namespace = &refNamespace
+		name := ref.Name
+		to := gatewayapiv1beta1.ReferenceGrantTo{Group: "", Kind: "Service", Name: &name}
+		if ref.Group != nil {
+			to.Group = *ref.Group
+		}
+		if ref.Kind != nil {
+			to.Kind = *ref.Kind
+		}
+		backends[fmt.Sprintf("%s/%s/%s", to.Group, to.Kind, name)] = to
+	}
+	qualifyFilters := func(filters []gatewayapiv1.HTTPRouteFilter) {
+		for i := range filters {
+			if filters[i].RequestMirror != nil {
+				qualify(&filters[i].RequestMirror.BackendRef)
+			}
+		}
+	}
+	for i := range spec.Rules {
+		rule := &spec.Rules[i]
+		qualifyFilters(rule.Filters)
+		for j := range rule.BackendRefs {
+			qualify(&rule.BackendRefs[j].BackendObjectReference)
+			qualifyFilters(rule.BackendRefs[j].Filters)
+		}
+	}
+
+	grants := []gatewayapiv1beta1.ReferenceGrantTo{}
+	for _, key := range sets.List(sets.KeySet(backends)) {
+		grants = append(grants, backends[key])
+	}
+	return grants
+}
+
+// routeRBAC ensures the work agent on the spoke is allowed to manage HTTPRoutes
+func (r *HTTPRouteReconciler) routeRBAC(ctx context.Context, cluster string) error {
+	subject := r.WorkAgentSubject
+	if subject.Name == "" {
+		subject = placement.DefaultWorkAgentSubject
+	}
+	cr := &rbac.ClusterRole{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "rbac.authorization.k8s.io/v1",
+			Kind:       "ClusterRole",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: routeRBACName,
+		},
+		Rules: []rbac.PolicyRule{
+			{
+				Verbs:     []string{"get", "list", "watch", "create", "update", "patch", "delete"},
+				APIGroups: []string{gatewayapiv1.GroupName},
+				Resources: []string{"httproutes"},
+			},
+			{
+				// the copies of the routes are granted access to the backends in the namespace of the route
+				Verbs:     []string{"get", "list", "watch", "create", "update", "patch", "delete"},
+				APIGroups: []string{gatewayapiv1beta1.GroupName},
+				Resources: []string{"referencegrants"},
+			},
+		},
+	}
+	crb := &rbac.ClusterRoleBinding{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "rbac.authorization.k8s.io/v1",
+			Kind:       "ClusterRoleBinding",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: routeRBACName,
+		},
+		RoleRef: rbac.RoleRef{
+			APIGroup: "rbac.authorization.k8s.io",
+			Kind:     "ClusterRole",
+			Name:     routeRBACName,
+		},
+		Subjects: []rbac.Subject{subject},
+	}
+
+	manifests := []workv1.Manifest{}
+	for _, obj := range []runtime.Object{cr, crb} {
+		raw, err := json.Marshal(obj)
+		if err != nil {
+			return err
+		}
+		manifests = append(manifests, workv1.Manifest{RawExtension: runtime.RawExtension{Raw: raw}})
+	}
+	return r.createUpdateWork(ctx, workv1.ManifestWork{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      routeRBACWork,
+			Namespace: cluster,
+		},
+		Spec: workv1.ManifestWorkSpec{
+			Workload: workv1.ManifestsTemplate{Manifests: manifests},
+		},
+	})
+}
+
+func (r *HTTPRouteReconciler) createUpdateWork(ctx context.Context, work workv1.ManifestWork) error {
+	existing := &workv1.ManifestWork{}
+	if err := r.Client.Get(ctx, client.ObjectKeyFromObject(&work), existing); err != nil {
+		if !k8serrors.IsNotFound(err) {
+			return err
+		}
+		return r.Client.Create(ctx, &work)
+	}
+
+	if placement.ManifestWorkSpecEqual(existing.Spec, work.Spec) && equality.Semantic.DeepEqual(existing.Labels, work.Labels) {
+		return nil
+	}
+	existing.Spec = work.Spec
+	existing.Labels = work.Labels
+	existing.Annotations = work.Annotations
+	return r.Client.Update(ctx, existing)
+}
+
+// aggregateRouteParents builds the status of the hub route for each of its multicluster
+// parents from the route status reported by the clusters the route is synced to. The
+// status set by other controllers is kept
+func aggregateRouteParents(route *gatewayapiv1.HTTPRoute, parents []routeParent, works []workv1.ManifestWork) []gatewayapiv1.RouteParentStatus {
+	reported := map[string][]gatewayapiv1.RouteParentStatus{}
+	for _, work := range works {
+		if work.DeletionTimestamp != nil {
+			continue
+		}
+		for _, m := range work.Status.ResourceStatus.Manifests {
+			for _, value := range m.StatusFeedbacks.Values {
+				if value.Name != routeParentsFeedback || value.Value.JsonRaw == nil {
+					continue
+				}
+				// each copy of the route in the cluster reports the status of its own parents
+				statuses := []gatewayapiv1.RouteParentStatus{}
+				if err := json.Unmarshal([]byte(*value.Value.JsonRaw), &statuses); err == nil {
+					reported[work.Namespace] = append(reported[work.Namespace], statuses...)
+				}
+			}
+		}
+	}
+
+	statuses := []gatewayapiv1.RouteParentStatus{}
+	for _, status := range route.Status.Parents {
+		if status.ControllerName != ControllerName {
+			statuses = append(statuses, status)
+		}
+	}
+	for _, parent := range parents {
+		existing := []metav1.Condition{}
+		for _, status := range route.Status.Parents {
+			if status.ControllerName == ControllerName && parentRefEqual(route, status.ParentRef, parent.Ref) {
+				existing = status.Conditions
+			}
+		}
+		downstreamRef := downstreamParentRef(parent)
+		conditions := make([]metav1.Condition, len(existing))
+		copy(conditions, existing)
+		for _, conditionType := range []gatewayapiv1.RouteConditionType{gatewayapiv1.RouteConditionAccepted, gatewayapiv1.RouteConditionResolvedRefs} {
+			clusterConditions := map[string]*metav1.Condition{}
+			for _, cluster := range sets.List(parent.Clusters) {
+				clusterConditions[cluster] = nil
+				for _, status := range reported[cluster] {
+					if parentRefEqual(route, status.ParentRef, downstreamRef) {
+						clusterConditions[cluster] = meta.FindStatusCondition(status.Conditions, string(conditionType))
+					}
+				}
+			}
+			meta.SetStatusCondition(&conditions, buildRouteParentCondition(route.Generation, conditionType, clusterConditions))
+		}
+		statuses = append(statuses, gatewayapiv1.RouteParentStatus{
+			ParentRef:      parent.Ref,
+			ControllerName: ControllerName,
+			Conditions:     conditions,
+		})
+	}
+	return statuses
+}
+
+// buildRouteParentCondition builds a route parent condition from the conditions reported by
+// each cluster. It is only true once it is true in every cluster
+func buildRouteParentCondition(generation int64, conditionType gatewayapiv1.RouteConditionType, clusterConditions map[string]*metav1.Condition) metav1.Condition {
+	condition := metav1.Condition{
+		Type:               string(conditionType),
+		Status:             metav1.ConditionTrue,
+		Reason:             string(conditionType),
+		ObservedGeneration: generation,
+	}
+	if len(clusterConditions) == 0 {
+		condition.Status = metav1.ConditionUnknown
+		condition.Reason = string(gatewayapiv1.RouteReasonPending)
+		condition.Message = "parent gateway is not placed on any clusters"
+		return condition
+	}
+
+	succeeded, failed, pending := sets.New[string](), sets.New[string](), sets.New[string]()
+	for cluster, clusterCondition := range clusterConditions {
+		switch {
+		case clusterCondition == nil || clusterCondition.Status == metav1.ConditionUnknown:
+			pending.Insert(cluster)
+		case clusterCondition.Status == metav1.ConditionFalse:
+			failed.Insert(cluster)
+		default:
+			succeeded.Insert(cluster)
+		}
+	}
+	switch {
+	case failed.Len() > 0:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = clusterConditions[sets.List(failed)[0]].Reason
+	case pending.Len() > 0:
+		condition.Status = metav1.ConditionUnknown
+		condition.Reason = string(gatewayapiv1.RouteReasonPending)
+	}
+	condition.Message = fmt.Sprintf("true in clusters %v, false in clusters %v, pending in clusters %v",
+		sets.List(succeeded), sets.List(failed), sets.List(pending))
+	return condition
+}
+
+// downstreamParentRef returns the parentRef pointing to the downstream gateway. The
+// downstream gateway keeps the upstream name, only the namespace changes
+func downstreamParentRef(parent routeParent) gatewayapiv1.ParentReference {
+	ref := *parent.Ref.DeepCopy()
+	namespace := gatewayapiv1.Namespace(parent.DownstreamNamespace)
+	ref.Namespace = &namespace
+	return ref
+}
+
+func isGatewayParentRef(ref gatewayapiv1.ParentReference) bool {
+	return (ref.Group == nil || *ref.Group == gatewayapiv1.GroupName) &&
+		(ref.Kind == nil || *ref.Kind == "Gateway")
+}
+
+func parentNamespace(route *gatewayapiv1.HTTPRoute, ref gatewayapiv1.ParentReference) string {
+	if ref.Namespace != nil && *ref.Namespace != "" {
+		return string(*ref.Namespace)
+	}
+	return route.Namespace
+}
+
+// parentRefEqual compares parentRefs of the route, defaulting the namespace to the route namespace
+func parentRefEqual(route *gatewayapiv1.HTTPRoute, a, b gatewayapiv1.ParentReference) bool {
+	return a.Name == b.Name &&
+		parentNamespace(route, a) == parentNamespace(route, b) &&
+		equality.Semantic.DeepEqual(a.SectionName, b.SectionName) &&
+		equality.Semantic.DeepEqual(a.Port, b.Port)
+}
+
+// HTTPRouteParentRefIndex indexes the routes by the namespace/name of the gateways referenced by their parentRefs
+const HTTPRouteParentRefIndex = "spec.parentRefs.gateway"
+
+// routeParentRefs returns the namespace/name of the gateways referenced by the parentRefs of the
+// route, defaulting their namespace to the namespace of the route
+func routeParentRefs(obj client.Object) []string {
+	route, ok := obj.(*gatewayapiv1.HTTPRoute)
+	if !ok {
+		return nil
+	}
+	refs := sets.New[string]()
+	for _, ref := range route.Spec.ParentRefs {
+		if isGatewayParentRef(ref) {
+			refs.Insert(types.NamespacedName{Namespace: parentNamespace(route, ref), Name: string(ref.Name)}.String())
+		}
+	}
+	return sets.List(refs)
+}
+
+// routesForGateway returns the routes with a parentRef to the gateway
+func (r *HTTPRouteReconciler) routesForGateway(ctx context.Context, o client.Object) []reconcile.Request {
+	requests := []reconcile.Request{}
+	routes := &gatewayapiv1.HTTPRouteList{}
+	if err := r.Client.List(ctx, routes, client.MatchingFields{HTTPRouteParentRefIndex: client.ObjectKeyFromObject(o).String()}); err != nil {
+		crlog.FromContext(ctx).Error(err, "failed to list routes to requeue")
+		return requests
+	}
+	for i := range routes.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&routes.Items[i])})
+	}
+	return requests
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *HTTPRouteReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// the routes attached to a gateway are found with an index, to enqueue them when it changes
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &gatewayapiv1.HTTPRoute{}, HTTPRouteParentRefIndex, routeParentRefs); err != nil {
+		return err
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&gatewayapiv1.HTTPRoute{}).
+		Watches(&gatewayapiv1.Gateway{}, handler.EnqueueRequestsFromMapFunc(r.routesForGateway)).
+		Watches(&workv1.ManifestWork{}, handler.EnqueueRequestsFromMapFunc(func(_ context.Context, o client.Object) []reconcile.Request {
+			ns, name, err := cache.SplitMetaNamespaceKey(o.GetAnnotations()[RouteParentAnnotation])
+			if err != nil || name == "" {
+				return []reconcile.Request{}
+			}
+			return []reconcile.Request{{NamespacedName: client.ObjectKey{Namespace: ns, Name: name}}}
+		}), builder.WithPredicates(predicate.NewPredicateFuncs(func(o client.Object) bool {
+			return o.GetLabels()[RouteWorkLabel] == "true"
+		}))).
+		Complete(r)
+}
@@ -0,0 +1,210 @@
+//go:build unit
+
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	workv1 "open-cluster-management.io/api/work/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	gatewayapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayapiv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	fakeplacement "github.com/Kuadrant/multicluster-gateway-controller/pkg/placement/fake"
+	testutil "github.com/Kuadrant/multicluster-gateway-controller/test/util"
+)
+
+func TestHTTPRouteReconciler_Reconcile(t *testing.T) {
+	scheme := testutil.GetValidTestScheme()
+	if err := workv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	multiClusterGatewayClass := &gatewayapiv1.GatewayClass{
+		ObjectMeta: v1.ObjectMeta{Name: testutil.MultiClusterGatewayClassName},
+		Spec:       gatewayapiv1.GatewayClassSpec{ControllerName: ControllerName},
+	}
+	multiClusterGateway := &gatewayapiv1.Gateway{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "mgc-gw",
+			Namespace: testutil.Namespace,
+			Labels:    getTestGatewayLabels(),
+		},
+		Spec: gatewayapiv1.GatewaySpec{GatewayClassName: gatewayapiv1.ObjectName(testutil.MultiClusterGatewayClassName)},
+	}
+	localGateway := &gatewayapiv1.Gateway{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "local-gw",
+			Namespace: testutil.Namespace,
+			Labels:    getTestGatewayLabels(),
+		},
+		Spec: gatewayapiv1.GatewaySpec{GatewayClassName: "istio"},
+	}
+	route := &gatewayapiv1.HTTPRoute{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "api",
+			Namespace: testutil.Namespace,
+			Labels:    map[string]string{"app": "api"},
+			Annotations: map[string]string{
+				corev1.LastAppliedConfigAnnotation: "{}",
+				"example.com/owner":                "team-a",
+			},
+		},
+		Spec: gatewayapiv1.HTTPRouteSpec{
+			CommonRouteSpec: gatewayapiv1.CommonRouteSpec{
+				ParentRefs: []gatewayapiv1.ParentReference{{Name: "mgc-gw"}, {Name: "local-gw"}},
+			},
+			Hostnames: []gatewayapiv1.Hostname{testutil.ValidTestHostname},
+			Rules: []gatewayapiv1.HTTPRouteRule{{
+				BackendRefs: []gatewayapiv1.HTTPBackendRef{{BackendRef: gatewayapiv1.BackendRef{
+					BackendObjectReference: gatewayapiv1.BackendObjectReference{Name: "api"},
+				}}},
+			}},
+		},
+	}
+
+	c := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(multiClusterGatewayClass, multiClusterGateway, localGateway, route).
+		WithStatusSubresource(&gatewayapiv1.HTTPRoute{}).
+		WithIndex(&gatewayapiv1.HTTPRoute{}, HTTPRouteParentRefIndex, routeParentRefs).
+		Build()
+	r := &HTTPRouteReconciler{
+		Client:    c,
+		Scheme:    scheme,
+		Placement: fakeplacement.NewTestGatewayPlacer(),
+	}
+	req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(route)}
+
+	// the route is enqueued by the changes to the gateways it is attached to
+	if requests := r.routesForGateway(context.TODO(), multiClusterGateway); len(requests) != 1 || requests[0].NamespacedName != req.NamespacedName {
+		t.Fatalf("expected route to be enqueued for its gateway got %v", requests)
+	}
+	otherGateway := &gatewayapiv1.Gateway{ObjectMeta: v1.ObjectMeta{Name: "mgc-gw", Namespace: "other"}}
+	if requests := r.routesForGateway(context.TODO(), otherGateway); len(requests) != 0 {
+		t.Fatalf("expected route not to be enqueued for a gateway in another namespace got %v", requests)
+	}
+	workKey := client.ObjectKey{Name: "httproute-" + testutil.Namespace + "-api", Namespace: testutil.Cluster}
+
+	if _, err := r.Reconcile(context.TODO(), req); err != nil {
+		t.Fatalf("did not expect an error syncing route but got %s", err)
+	}
+	if err := c.Get(context.TODO(), client.ObjectKey{Name: routeRBACWork, Namespace: testutil.Cluster}, &workv1.ManifestWork{}); err != nil {
+		t.Fatalf("expected route rbac to be placed on cluster %s: %s", testutil.Cluster, err)
+	}
+
+	// the route is synced attached to the downstream gateway only
+	work := &workv1.ManifestWork{}
+	if err := c.Get(context.TODO(), workKey, work); err != nil {
+		t.Fatalf("expected route to be synced to cluster %s: %s", testutil.Cluster, err)
+	}
+	downstream := &gatewayapiv1.HTTPRoute{}
+	if err := json.Unmarshal(work.Spec.Workload.Manifests[0].Raw, downstream); err != nil {
+		t.Fatal(err)
+	}
+	if downstream.Namespace != "kuadrant-"+testutil.Namespace {
+		t.Fatalf("expected route to be synced to the namespace of the downstream gateway got %s", downstream.Namespace)
+	}
+	if len(downstream.Spec.ParentRefs) != 1 {
+		t.Fatalf("expected one parentRef got %v", downstream.Spec.ParentRefs)
+	}
+	if ref := downstream.Spec.ParentRefs[0]; ref.Name != "mgc-gw" || ref.Namespace == nil || string(*ref.Namespace) != "kuadrant-"+testutil.Namespace {
+		t.Fatalf("expected parentRef to the downstream gateway got %v", ref)
+	}
+
+	// the metadata of the route is propagated as for its gateway
+	if downstream.Labels["app"] != "api" || downstream.Annotations["example.com/owner"] != "team-a" {
+		t.Fatalf("expected route labels and annotations to be propagated got %v %v", downstream.Labels, downstream.Annotations)
+	}
+	if _, ok := downstream.Annotations[corev1.LastAppliedConfigAnnotation]; ok {
+		t.Fatalf("expected hub annotations not to be propagated got %v", downstream.Annotations)
+	}
+
+	// the backends are still referenced in the namespace of the route, which grants the copy access to them
+	if ref := downstream.Spec.Rules[0].BackendRefs[0]; ref.Namespace == nil || string(*ref.Namespace) != testutil.Namespace {
+		t.Fatalf("expected backendRef in the namespace of the route got %v", ref)
+	}
+	if len(work.Spec.Workload.Manifests) != 2 {
+		t.Fatalf("expected route and reference grant manifests got %d", len(work.Spec.Workload.Manifests))
+	}
+	grant := &gatewayapiv1beta1.ReferenceGrant{}
+	if err := json.Unmarshal(work.Spec.Workload.Manifests[1].Raw, grant); err != nil {
+		t.Fatal(err)
+	}
+	if grant.Namespace != testutil.Namespace || len(grant.Spec.From) != 1 || string(grant.Spec.From[0].Namespace) != "kuadrant-"+testutil.Namespace {
+		t.Fatalf("expected reference grant from the namespace of the copy got %v", grant)
+	}
+	if len(grant.Spec.To) != 1 || grant.Spec.To[0].Kind != "Service" || grant.Spec.To[0].Name == nil || *grant.Spec.To[0].Name != "api" {
+		t.Fatalf("expected reference grant to the api service got %v", grant.Spec.To)
+	}
+
+	hubRoute := &gatewayapiv1.HTTPRoute{}
+	if err := c.Get(context.TODO(), req.NamespacedName, hubRoute); err != nil {
+		t.Fatal(err)
+	}
+	if !controllerutil.ContainsFinalizer(hubRoute, RouteSyncFinalizer) {
+		t.Fatalf("expected route sync finalizer got %v", hubRoute.Finalizers)
+	}
+	if len(hubRoute.Status.Parents) != 1 || !meta.IsStatusConditionPresentAndEqual(hubRoute.Status.Parents[0].Conditions, string(gatewayapiv1.RouteConditionAccepted), v1.ConditionUnknown) {
+		t.Fatalf("expected route to be pending in the hub status got %v", hubRoute.Status.Parents)
+	}
+
+	// the route status reported by the cluster is aggregated onto the hub route
+	reported, err := json.Marshal([]gatewayapiv1.RouteParentStatus{{
+		ParentRef:      downstream.Spec.ParentRefs[0],
+		ControllerName: "istio.io/gateway-controller",
+		Conditions: []v1.Condition{
+			{Type: string(gatewayapiv1.RouteConditionAccepted), Status: v1.ConditionTrue, Reason: "Accepted"},
+			{Type: string(gatewayapiv1.RouteConditionResolvedRefs), Status: v1.ConditionFalse, Reason: "BackendNotFound"},
+		},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw := string(reported)
+	work.Status.ResourceStatus.Manifests = []workv1.ManifestCondition{{
+		StatusFeedbacks: workv1.StatusFeedbackResult{
+			Values: []workv1.FeedbackValue{{Name: routeParentsFeedback, Value: workv1.FieldValue{Type: workv1.JsonRaw, JsonRaw: &raw}}},
+		},
+	}}
+	if err := c.Update(context.TODO(), work); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Reconcile(context.TODO(), req); err != nil {
+		t.Fatalf("did not expect an error syncing route but got %s", err)
+	}
+	if err := c.Get(context.TODO(), req.NamespacedName, hubRoute); err != nil {
+		t.Fatal(err)
+	}
+	conditions := hubRoute.Status.Parents[0].Conditions
+	if !meta.IsStatusConditionTrue(conditions, string(gatewayapiv1.RouteConditionAccepted)) {
+		t.Fatalf("expected route to be accepted got %v", conditions)
+	}
+	if resolved := meta.FindStatusCondition(conditions, string(gatewayapiv1.RouteConditionResolvedRefs)); resolved == nil || resolved.Reason != "BackendNotFound" {
+		t.Fatalf("expected refs not to be resolved got %v", resolved)
+	}
+
+	// deleting the route removes it from the cluster
+	if err := c.Delete(context.TODO(), hubRoute); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Reconcile(context.TODO(), req); err != nil {
+		t.Fatalf("did not expect an error removing route but got %s", err)
+	}
+	if err := c.Get(context.TODO(), workKey, work); err == nil {
+		t.Fatalf("expected route to be removed from cluster %s", testutil.Cluster)
+	}
+	// along with the rbac, as no other route is synced to the cluster
+	if err := c.Get(context.TODO(), client.ObjectKey{Name: routeRBACWork, Namespace: testutil.Cluster}, &workv1.ManifestWork{}); err == nil {
+		t.Fatalf("expected route rbac to be removed from cluster %s", testutil.Cluster)
+	}
+}
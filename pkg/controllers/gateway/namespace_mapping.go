@@ -0,0 +1,104 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
+)
+
+const defaultNamespacePrefix = "kuadrant"
+
+// namespaceTemplateData is the data the namespace mapping template is rendered with
+type namespaceTemplateData struct {
+	// Namespace of the gateway on the hub
+	Namespace string
+	// Name of the gateway on the hub
+	Name string
+}
+
+// validateNamespaceMapping validates the namespace mapping of the params, rendering the
+// template of the Template strategy for an example gateway
+func validateNamespaceMapping(mapping *NamespaceMapping) error {
+	if mapping == nil {
+		return nil
+	}
+	switch mapping.Strategy {
+	case "", v1alpha1.NamespaceMappingPrefixed:
+		if errs := validation.IsDNS1123Label(mapping.Prefix); mapping.Prefix != "" && len(errs) > 0 {
+			return &InvalidParamsError{fmt.Sprintf("invalid namespace mapping prefix %q: %s", mapping.Prefix, strings.Join(errs, ", "))}
+		}
+	case v1alpha1.NamespaceMappingSame:
+	case v1alpha1.NamespaceMappingTemplate:
+		if mapping.Template == "" {
+			return &InvalidParamsError{"namespace mapping template must be set for the Template strategy"}
+		}
+		if _, err := mapNamespace(mapping, namespaceTemplateData{Namespace: "namespace", Name: "name"}); err != nil {
+			return err
+		}
+	default:
+		return &InvalidParamsError{fmt.Sprintf("unknown namespace mapping strategy %q: must be one of Same, Prefixed or Template", mapping.Strategy)}
+	}
+	return nil
+}
+
+// downstreamNamespace returns the namespace the downstream gateway is placed into in the spokes.
+// The gateway annotation takes precedence over the namespace mapping of the params, which
+// defaults to the hub namespace prefixed with "kuadrant"
+func downstreamNamespace(upstreamGateway *gatewayapiv1.Gateway, params *Params) (string, error) {
+	if namespace, ok := upstreamGateway.GetAnnotations()[GatewayDownstreamNamespaceAnnotation]; ok {
+		if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+			return "", fmt.Errorf("invalid %s annotation %q: %s", GatewayDownstreamNamespaceAnnotation, namespace, strings.Join(errs, ", "))
+		}
+		return namespace, nil
+	}
+	mapping := &NamespaceMapping{}
+	if params != nil && params.NamespaceMapping != nil {
+		mapping = params.NamespaceMapping
+	}
+	return mapNamespace(mapping, namespaceTemplateData{Namespace: upstreamGateway.Namespace, Name: upstreamGateway.Name})
+}
+
+// downstreamNamespaceFor returns the namespace the downstream gateway is placed into in the
+// spokes, using the params of the class of the gateway
+func downstreamNamespaceFor(ctx context.Context, c client.Client, upstreamGateway *gatewayapiv1.Gateway) (string, error) {
+	params, err := getParams(ctx, c, string(upstreamGateway.Spec.GatewayClassName))
+	if err != nil {
+		return "", err
+	}
+	return downstreamNamespace(upstreamGateway, params)
+}
+
+func mapNamespace(mapping *NamespaceMapping, data namespaceTemplateData) (string, error) {
+	var namespace string
+	switch mapping.Strategy {
+	case v1alpha1.NamespaceMappingSame:
+		namespace = data.Namespace
+	case v1alpha1.NamespaceMappingTemplate:
+		tmpl, err := template.New("namespace").Option("missingkey=error").Parse(mapping.Template)
+		if err != nil {
+			return "", &InvalidParamsError{fmt.Sprintf("invalid namespace mapping template %q: %s", mapping.Template, err)}
+		}
+		rendered := &strings.Builder{}
+		if err := tmpl.Execute(rendered, data); err != nil {
+			return "", &InvalidParamsError{fmt.Sprintf("invalid namespace mapping template %q: %s", mapping.Template, err)}
+		}
+		namespace = strings.TrimSpace(rendered.String())
+	default:
+		prefix := mapping.Prefix
+		if prefix == "" {
+			prefix = defaultNamespacePrefix
+		}
+		namespace = fmt.Sprintf("%s-%s", prefix, data.Namespace)
+	}
+	if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+		return "", &InvalidParamsError{fmt.Sprintf("namespace %q mapped for gateway %s/%s is invalid: %s", namespace, data.Namespace, data.Name, strings.Join(errs, ", "))}
+	}
+	return namespace, nil
+}
@@ -0,0 +1,88 @@
+//go:build unit
+
+package gateway
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gatewayapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
+)
+
+func TestDownstreamNamespace(t *testing.T) {
+	cases := []struct {
+		name        string
+		annotations map[string]string
+		params      *Params
+		expected    string
+		expectErr   bool
+	}{
+		{
+			name:     "default params prefix the namespace",
+			params:   &defaultParams,
+			expected: "kuadrant-test-ns",
+		},
+		{
+			name:     "no params prefix the namespace",
+			expected: "kuadrant-test-ns",
+		},
+		{
+			name:     "same namespace",
+			params:   &Params{NamespaceMapping: &NamespaceMapping{Strategy: v1alpha1.NamespaceMappingSame}},
+			expected: "test-ns",
+		},
+		{
+			name:     "custom prefix",
+			params:   &Params{NamespaceMapping: &NamespaceMapping{Strategy: v1alpha1.NamespaceMappingPrefixed, Prefix: "edge"}},
+			expected: "edge-test-ns",
+		},
+		{
+			name:     "template",
+			params:   &Params{NamespaceMapping: &NamespaceMapping{Strategy: v1alpha1.NamespaceMappingTemplate, Template: "{{.Namespace}}-{{.Name}}-edge"}},
+			expected: "test-ns-test-gw-edge",
+		},
+		{
+			name:      "template rendering an invalid namespace",
+			params:    &Params{NamespaceMapping: &NamespaceMapping{Strategy: v1alpha1.NamespaceMappingTemplate, Template: "{{.Namespace}}.edge"}},
+			expectErr: true,
+		},
+		{
+			name:        "annotation overrides the params",
+			annotations: map[string]string{GatewayDownstreamNamespaceAnnotation: "gateways"},
+			params:      &Params{NamespaceMapping: &NamespaceMapping{Strategy: v1alpha1.NamespaceMappingSame}},
+			expected:    "gateways",
+		},
+		{
+			name:        "invalid annotation",
+			annotations: map[string]string{GatewayDownstreamNamespaceAnnotation: "Gateways"},
+			expectErr:   true,
+		},
+	}
+
+	for _, testCase := range cases {
+		t.Run(testCase.name, func(t *testing.T) {
+			gateway := &gatewayapiv1.Gateway{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "test-gw",
+					Namespace:   "test-ns",
+					Annotations: testCase.annotations,
+				},
+			}
+			namespace, err := downstreamNamespace(gateway, testCase.params)
+			if testCase.expectErr {
+				if err == nil {
+					t.Fatalf("expected an error but got namespace %s", namespace)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %s", err)
+			}
+			if namespace != testCase.expected {
+				t.Fatalf("expected namespace %s got %s", testCase.expected, namespace)
+			}
+		})
+	}
+}
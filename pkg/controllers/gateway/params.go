@@ -2,15 +2,27 @@ package gateway
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"reflect"
 
 	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	gatewayapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+	k8sjson "sigs.k8s.io/json"
+
+	"github.com/kuadrant/kuadrant-operator/pkg/multicluster"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/gracePeriod"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/metadata"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/placement"
 )
 
 type Params struct {
@@ -21,6 +33,83 @@ type Params struct {
 	// PoliciesToSync specifies a listof Policy GVRs that will be watched
 	// in the hub and synced to the spokes
 	PoliciesToSync []ParamsGroupVersionResource `json:"experimentalPolicySync,omitempty"`
+
+	// RolloutBatch enables a progressive rollout of gateway changes to the
+	// spokes for the gateways of the class, updating this number, or
+	// percentage, of clusters at a time. For example: "1" or "25%"
+	RolloutBatch string `json:"rolloutBatch,omitempty"`
+
+	// GracePeriod is how long a gateway is kept on a spoke after it is no
+	// longer targeted, so DNS clients stop resolving to it before it is
+	// removed. For example: "10m". Gateways can override it with the
+	// kuadrant.io/grace-period annotation
+	GracePeriod string `json:"gracePeriod,omitempty"`
+
+	// GracePeriodFromDNSTTL derives the grace period from the TTL of the
+	// DNSRecords of the gateway DNSPolicy, falling back to GracePeriod
+	// when the gateway has no DNSRecords
+	GracePeriodFromDNSTTL bool `json:"gracePeriodFromDNSTTL,omitempty"`
+
+	// NamespaceMapping configures the namespace the gateways of the class
+	// are placed in on the spokes. By default the hub namespace is
+	// prefixed with "kuadrant". Gateways can override it with the
+	// kuadrant.io/gateway-downstream-namespace annotation
+	NamespaceMapping *NamespaceMapping `json:"namespaceMapping,omitempty"`
+
+	// PropagatedLabels filters the labels of the gateway propagated to the
+	// downstream gateways by their prefix
+	PropagatedLabels *MetadataPropagation `json:"propagatedLabels,omitempty"`
+
+	// PropagatedAnnotations filters the annotations of the gateway propagated
+	// to the downstream gateways by their prefix
+	PropagatedAnnotations *MetadataPropagation `json:"propagatedAnnotations,omitempty"`
+
+	// ClusterIssuer is the name of the cert-manager ClusterIssuer that
+	// issues the certificates of the HTTPS listeners. Gateways can
+	// override it with the kuadrant.io/gateway-cluster-issuer annotation
+	ClusterIssuer string `json:"clusterIssuer,omitempty"`
+}
+
+type NamespaceMapping struct {
+	// Strategy is one of Same, Prefixed or Template
+	Strategy v1alpha1.NamespaceMappingStrategy `json:"strategy,omitempty"`
+	// Prefix is added to the hub namespace by the Prefixed strategy
+	Prefix string `json:"prefix,omitempty"`
+	// Template is rendered with the Namespace and Name of the hub gateway
+	// by the Template strategy. For example: "{{.Namespace}}-edge"
+	Template string `json:"template,omitempty"`
+}
+
+type MetadataPropagation struct {
+	// AllowPrefixes are the prefixes of the keys that are propagated. Every
+	// key is propagated when empty
+	AllowPrefixes []string `json:"allowPrefixes,omitempty"`
+	// DenyPrefixes are the prefixes of the keys that are not propagated,
+	// taking precedence over AllowPrefixes. Defaults to the keys the hub
+	// uses to place the gateways
+	DenyPrefixes []string `json:"denyPrefixes,omitempty"`
+}
+
+// defaultDeniedMetadataPrefixes are the labels and annotations the hub uses to
+// place the gateways, which are not propagated to the downstream gateways
+var defaultDeniedMetadataPrefixes = []string{
+	LabelPrefix + "gateway-",
+	gracePeriod.GracePeriodAnnotation,
+	multicluster.ClustersLabelPrefix,
+	placement.OCMPlacementLabel,
+	corev1.LastAppliedConfigAnnotation,
+}
+
+// predicate returns the predicate of the keys to propagate
+func (m *MetadataPropagation) predicate() metadata.AnnotationPredicate {
+	if m == nil {
+		return metadata.PrefixPredicate(nil, defaultDeniedMetadataPrefixes)
+	}
+	deny := m.DenyPrefixes
+	if deny == nil {
+		deny = defaultDeniedMetadataPrefixes
+	}
+	return metadata.PrefixPredicate(m.AllowPrefixes, deny)
 }
 
 type ParamsGroupVersionResource struct {
@@ -64,7 +153,35 @@ func IsInvalidParamsError(err error) (is bool) {
 type ParamsResolver func(context.Context, client.Client, gatewayapiv1.ParametersReference) (*Params, error)
 
 var paramsResolvers = map[schema.GroupKind]ParamsResolver{
-	{Group: corev1.GroupName, Kind: "ConfigMap"}: fromNamespacedObject(fromConfigMap),
+	{Group: corev1.GroupName, Kind: "ConfigMap"}:                                fromNamespacedObject(fromConfigMap),
+	{Group: v1alpha1.GroupVersion.Group, Kind: "MultiClusterGatewayParameters"}: fromNamespacedObject(fromMultiClusterGatewayParameters),
+}
+
+// paramsObjects returns an object of each kind that can be resolved by the
+// paramsResolvers, so they can be watched for changes to the params of the
+// classes that reference them
+func paramsObjects(scheme *runtime.Scheme, mapper meta.RESTMapper) ([]client.Object, error) {
+	objects := []client.Object{}
+	for groupKind := range paramsResolvers {
+		mapping, err := mapper.RESTMapping(groupKind)
+		if meta.IsNoMatchError(err) {
+			// the CRD of the params kind is not installed
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get mapping for params GroupKind %s: %w", groupKind.String(), err)
+		}
+		obj, err := scheme.New(mapping.GroupVersionKind)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create params object for %s: %w", mapping.GroupVersionKind.String(), err)
+		}
+		clientObj, ok := obj.(client.Object)
+		if !ok {
+			return nil, fmt.Errorf("params kind %s is not a client.Object", mapping.GroupVersionKind.String())
+		}
+		objects = append(objects, clientObj)
+	}
+	return objects, nil
 }
 
 func fromNamespacedObject[T client.Object](getParams func(T) (*Params, error)) ParamsResolver {
@@ -96,14 +213,106 @@ func fromConfigMap(configMap *corev1.ConfigMap) (*Params, error) {
 		return nil, &InvalidParamsError{"Parameters must be defined in \"params\" field of ConfigMap"}
 	}
 
+	// params are decoded case sensitively and unknown fields are rejected, so
+	// misspelled params are not silently ignored
 	result := &Params{}
-	if err := json.Unmarshal([]byte(paramsRaw), result); err != nil {
+	strictErrs, err := k8sjson.UnmarshalStrict([]byte(paramsRaw), result)
+	if err != nil {
 		return nil, &InvalidParamsError{fmt.Sprintf("Failed to unmarshal params: %v", err)}
 	}
+	if len(strictErrs) > 0 {
+		return nil, &InvalidParamsError{fmt.Sprintf("Failed to unmarshal params: %v", errors.Join(strictErrs...))}
+	}
+	if err := validateNamespaceMapping(result.NamespaceMapping); err != nil {
+		return nil, err
+	}
 
 	return result, nil
 }
 
+func fromMultiClusterGatewayParameters(params *v1alpha1.MultiClusterGatewayParameters) (*Params, error) {
+	result := &Params{
+		DownstreamClass:       params.Spec.DownstreamClass,
+		GracePeriodFromDNSTTL: params.Spec.GracePeriodFromDNSTTL,
+		ClusterIssuer:         params.Spec.ClusterIssuer,
+	}
+	if result.DownstreamClass == "" {
+		result.DownstreamClass = defaultParams.DownstreamClass
+	}
+	for _, policy := range params.Spec.PoliciesToSync {
+		result.PoliciesToSync = append(result.PoliciesToSync, ParamsGroupVersionResource{
+			Group:    policy.Group,
+			Version:  policy.Version,
+			Resource: policy.Resource,
+		})
+	}
+	if params.Spec.RolloutBatch != nil {
+		result.RolloutBatch = params.Spec.RolloutBatch.String()
+	}
+	if params.Spec.GracePeriod != nil {
+		result.GracePeriod = params.Spec.GracePeriod.Duration.String()
+	}
+	if propagated := params.Spec.PropagatedLabels; propagated != nil {
+		result.PropagatedLabels = &MetadataPropagation{AllowPrefixes: propagated.AllowPrefixes, DenyPrefixes: propagated.DenyPrefixes}
+	}
+	if propagated := params.Spec.PropagatedAnnotations; propagated != nil {
+		result.PropagatedAnnotations = &MetadataPropagation{AllowPrefixes: propagated.AllowPrefixes, DenyPrefixes: propagated.DenyPrefixes}
+	}
+	if mapping := params.Spec.NamespaceMapping; mapping != nil {
+		result.NamespaceMapping = &NamespaceMapping{
+			Strategy: mapping.Strategy,
+			Prefix:   mapping.Prefix,
+			Template: mapping.Template,
+		}
+	}
+	if err := validateNamespaceMapping(result.NamespaceMapping); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// validateParams validates that the resources referenced by the params exist
+// in the hub
+func validateParams(ctx context.Context, c client.Client, params *Params) error {
+	for _, policy := range params.PoliciesToSync {
+		if err := validatePolicyResource(ctx, c, policy.ToGroupVersionResource()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validatePolicyResource validates that a resource to sync is served by the hub
+// and is a policy, targeting the gateways with spec.targetRef
+func validatePolicyResource(ctx context.Context, c client.Client, gvr schema.GroupVersionResource) error {
+	if _, err := c.RESTMapper().KindFor(gvr); err != nil {
+		if meta.IsNoMatchError(err) {
+			return &InvalidParamsError{fmt.Sprintf("policy resource %s is not served by the hub", gvr.String())}
+		}
+		return err
+	}
+
+	crd := &apiextensionsv1.CustomResourceDefinition{}
+	if err := c.Get(ctx, client.ObjectKey{Name: gvr.GroupResource().String()}, crd); err != nil {
+		if apierrors.IsNotFound(err) {
+			return &InvalidParamsError{fmt.Sprintf("policy resource %s is not a custom resource", gvr.String())}
+		}
+		return err
+	}
+
+	for _, version := range crd.Spec.Versions {
+		if version.Name != gvr.Version || version.Schema == nil || version.Schema.OpenAPIV3Schema == nil {
+			continue
+		}
+		if _, ok := version.Schema.OpenAPIV3Schema.Properties["spec"].Properties["targetRef"]; ok {
+			return nil
+		}
+	}
+
+	return &InvalidParamsError{fmt.Sprintf("policy resource %s has no spec.targetRef", gvr.String())}
+}
+
 func getParams(ctx context.Context, c client.Client, gatewayClassName string) (*Params, error) {
 
 	gatewayClass := &gatewayapiv1.GatewayClass{}
@@ -0,0 +1,89 @@
+package gateway
+
+import (
+	"context"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	gatewayapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
+)
+
+// MultiClusterGatewayParametersReconciler reports the GatewayClasses using a
+// MultiClusterGatewayParameters object in its status
+type MultiClusterGatewayParametersReconciler struct {
+	client.Client
+}
+
+//+kubebuilder:rbac:groups=kuadrant.io,resources=multiclustergatewayparameters,verbs=get;list;watch
+//+kubebuilder:rbac:groups=kuadrant.io,resources=multiclustergatewayparameters/status,verbs=get;update;patch
+
+func (r *MultiClusterGatewayParametersReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := ctrllog.FromContext(ctx)
+
+	previous := &v1alpha1.MultiClusterGatewayParameters{}
+	if err := r.Client.Get(ctx, req.NamespacedName, previous); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	classList := &gatewayapiv1.GatewayClassList{}
+	if err := r.Client.List(ctx, classList); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	params := previous.DeepCopy()
+	params.Status.GatewayClasses = nil
+	params.Status.ObservedGeneration = previous.Generation
+	groupKind := v1alpha1.GroupVersion.WithKind("MultiClusterGatewayParameters").GroupKind()
+	for _, class := range classList.Items {
+		if isManagedClass(&class) && paramsRefersTo(class.Spec.ParametersRef, groupKind, params) {
+			params.Status.GatewayClasses = append(params.Status.GatewayClasses, class.Name)
+		}
+	}
+	sort.Strings(params.Status.GatewayClasses)
+
+	if equality.Semantic.DeepEqual(previous.Status, params.Status) {
+		return ctrl.Result{}, nil
+	}
+
+	log.V(3).Info("Updating MultiClusterGatewayParameters", "status", params.Status)
+	return ctrl.Result{}, r.Client.Status().Update(ctx, params)
+}
+
+// SetupWithManager sets up the controller with the Manager. The controller is
+// not set up when the MultiClusterGatewayParameters CRD is not installed
+func (r *MultiClusterGatewayParametersReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if _, err := mgr.GetRESTMapper().RESTMapping(v1alpha1.GroupVersion.WithKind("MultiClusterGatewayParameters").GroupKind()); err != nil {
+		if meta.IsNoMatchError(err) {
+			ctrllog.Log.Info("MultiClusterGatewayParameters CRD not installed, not reporting the classes using them")
+			return nil
+		}
+		return err
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1.MultiClusterGatewayParameters{}).
+		// the old and new class are both mapped on update, so the params a class
+		// stops referencing are updated too
+		Watches(&gatewayapiv1.GatewayClass{}, handler.EnqueueRequestsFromMapFunc(func(_ context.Context, o client.Object) []reconcile.Request {
+			class, ok := o.(*gatewayapiv1.GatewayClass)
+			if !ok || class.Spec.ParametersRef == nil || class.Spec.ParametersRef.Namespace == nil {
+				return []reconcile.Request{}
+			}
+			paramsRef := class.Spec.ParametersRef
+			if string(paramsRef.Group) != v1alpha1.GroupVersion.Group || paramsRef.Kind != "MultiClusterGatewayParameters" {
+				return []reconcile.Request{}
+			}
+			return []reconcile.Request{{NamespacedName: client.ObjectKey{
+				Namespace: string(*paramsRef.Namespace),
+				Name:      paramsRef.Name,
+			}}}
+		})).
+		Complete(r)
+}
@@ -0,0 +1,75 @@
+//go:build unit
+
+package gateway
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	gatewayapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
+	testutil "github.com/Kuadrant/multicluster-gateway-controller/test/util"
+)
+
+func TestMultiClusterGatewayParametersReconciler_Reconcile(t *testing.T) {
+	scheme := testutil.GetValidTestScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	class := func(name, controllerName, kind string) *gatewayapiv1.GatewayClass {
+		return &gatewayapiv1.GatewayClass{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: gatewayapiv1.GatewayClassSpec{
+				ControllerName: gatewayapiv1.GatewayController(controllerName),
+				ParametersRef: &gatewayapiv1.ParametersReference{
+					Group:     "kuadrant.io",
+					Kind:      gatewayapiv1.Kind(kind),
+					Name:      testutil.DummyCRName,
+					Namespace: testutil.Pointer(gatewayapiv1.Namespace(testutil.Namespace)),
+				},
+			},
+		}
+	}
+	params := &v1alpha1.MultiClusterGatewayParameters{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       testutil.DummyCRName,
+			Namespace:  testutil.Namespace,
+			Generation: 2,
+		},
+	}
+
+	c := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(
+			params,
+			class("mgc-b", ControllerName, "MultiClusterGatewayParameters"),
+			class("mgc-a", ControllerName, "MultiClusterGatewayParameters"),
+			class("other-controller", "example.com/gateway-controller", "MultiClusterGatewayParameters"),
+			class("other-kind", ControllerName, "OtherParameters"),
+		).
+		WithStatusSubresource(&v1alpha1.MultiClusterGatewayParameters{}).
+		Build()
+	r := &MultiClusterGatewayParametersReconciler{Client: c}
+
+	if _, err := r.Reconcile(context.TODO(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(params)}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	got := &v1alpha1.MultiClusterGatewayParameters{}
+	if err := c.Get(context.TODO(), client.ObjectKeyFromObject(params), got); err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"mgc-a", "mgc-b"}; !reflect.DeepEqual(got.Status.GatewayClasses, expected) {
+		t.Errorf("expected gateway classes %v, got %v", expected, got.Status.GatewayClasses)
+	}
+	if got.Status.ObservedGeneration != params.Generation {
+		t.Errorf("expected observed generation %d, got %d", params.Generation, got.Status.ObservedGeneration)
+	}
+}
@@ -7,14 +7,24 @@ import (
 	"fmt"
 	"reflect"
 	"testing"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	gatewayapiv1 "sigs.k8s.io/gateway-api/apis/v1"
 
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/gracePeriod"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/metadata"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/placement"
 	testutil "github.com/Kuadrant/multicluster-gateway-controller/test/util"
 )
 
@@ -125,6 +135,29 @@ func TestGetParams(t *testing.T) {
 			},
 			assertParams: assertError(IsInvalidParamsError),
 		},
+		{
+			name: "Unknown field in ConfigMap",
+			gatewayClass: &gatewayapiv1.GatewayClass{
+				Spec: gatewayapiv1.GatewayClassSpec{
+					ParametersRef: &gatewayapiv1.ParametersReference{
+						Group:     "",
+						Kind:      "ConfigMap",
+						Name:      testutil.DummyCRName,
+						Namespace: testutil.Pointer(gatewayapiv1.Namespace(testutil.Namespace)),
+					},
+				},
+			},
+			paramsObj: &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      testutil.DummyCRName,
+					Namespace: testutil.Namespace,
+				},
+				Data: map[string]string{
+					"params": `{"downstreamclass": "istio"}`,
+				},
+			},
+			assertParams: assertError(IsInvalidParamsError),
+		},
 		{
 			name: "Missing namespace",
 			gatewayClass: &gatewayapiv1.GatewayClass{
@@ -195,6 +228,133 @@ func TestGetParams(t *testing.T) {
 			},
 			assertParams: assertError(IsInvalidParamsError),
 		},
+		{
+			name: "MultiClusterGatewayParameters found",
+			gatewayClass: &gatewayapiv1.GatewayClass{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test",
+				},
+				Spec: gatewayapiv1.GatewayClassSpec{
+					ParametersRef: &gatewayapiv1.ParametersReference{
+						Group:     "kuadrant.io",
+						Kind:      "MultiClusterGatewayParameters",
+						Name:      testutil.DummyCRName,
+						Namespace: testutil.Pointer(gatewayapiv1.Namespace(testutil.Namespace)),
+					},
+				},
+			},
+			paramsObj: &v1alpha1.MultiClusterGatewayParameters{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      testutil.DummyCRName,
+					Namespace: testutil.Namespace,
+				},
+				Spec: v1alpha1.MultiClusterGatewayParametersSpec{
+					DownstreamClass: "eg",
+					PoliciesToSync: []v1alpha1.PolicyGroupVersionResource{
+						{Group: "kuadrant.io", Version: "v1alpha1", Resource: "dnspolicies"},
+					},
+					RolloutBatch:  testutil.Pointer(intstr.FromString("25%")),
+					GracePeriod:   &metav1.Duration{Duration: 5 * time.Minute},
+					ClusterIssuer: "letsencrypt",
+				},
+			},
+			assertParams: and(
+				noError,
+				paramsEqual(Params{
+					DownstreamClass: "eg",
+					PoliciesToSync: []ParamsGroupVersionResource{
+						{Group: "kuadrant.io", Version: "v1alpha1", Resource: "dnspolicies"},
+					},
+					RolloutBatch:  "25%",
+					GracePeriod:   "5m0s",
+					ClusterIssuer: "letsencrypt",
+				}),
+			),
+		},
+		{
+			name: "MultiClusterGatewayParameters with template namespace mapping",
+			gatewayClass: &gatewayapiv1.GatewayClass{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test",
+				},
+				Spec: gatewayapiv1.GatewayClassSpec{
+					ParametersRef: &gatewayapiv1.ParametersReference{
+						Group:     "kuadrant.io",
+						Kind:      "MultiClusterGatewayParameters",
+						Name:      testutil.DummyCRName,
+						Namespace: testutil.Pointer(gatewayapiv1.Namespace(testutil.Namespace)),
+					},
+				},
+			},
+			paramsObj: &v1alpha1.MultiClusterGatewayParameters{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      testutil.DummyCRName,
+					Namespace: testutil.Namespace,
+				},
+				Spec: v1alpha1.MultiClusterGatewayParametersSpec{
+					NamespaceMapping: &v1alpha1.NamespaceMapping{Strategy: v1alpha1.NamespaceMappingTemplate, Template: "{{.Namespace}}-edge"},
+				},
+			},
+			assertParams: and(
+				noError,
+				paramsEqual(Params{
+					DownstreamClass:  "istio",
+					NamespaceMapping: &NamespaceMapping{Strategy: v1alpha1.NamespaceMappingTemplate, Template: "{{.Namespace}}-edge"},
+				}),
+			),
+		},
+		{
+			name: "MultiClusterGatewayParameters with unknown template field",
+			gatewayClass: &gatewayapiv1.GatewayClass{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test",
+				},
+				Spec: gatewayapiv1.GatewayClassSpec{
+					ParametersRef: &gatewayapiv1.ParametersReference{
+						Group:     "kuadrant.io",
+						Kind:      "MultiClusterGatewayParameters",
+						Name:      testutil.DummyCRName,
+						Namespace: testutil.Pointer(gatewayapiv1.Namespace(testutil.Namespace)),
+					},
+				},
+			},
+			paramsObj: &v1alpha1.MultiClusterGatewayParameters{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      testutil.DummyCRName,
+					Namespace: testutil.Namespace,
+				},
+				Spec: v1alpha1.MultiClusterGatewayParametersSpec{
+					NamespaceMapping: &v1alpha1.NamespaceMapping{Strategy: v1alpha1.NamespaceMappingTemplate, Template: "{{.Cluster}}-edge"},
+				},
+			},
+			assertParams: assertError(IsInvalidParamsError),
+		},
+		{
+			name: "Invalid namespace mapping prefix in ConfigMap",
+			gatewayClass: &gatewayapiv1.GatewayClass{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test",
+				},
+				Spec: gatewayapiv1.GatewayClassSpec{
+					ParametersRef: &gatewayapiv1.ParametersReference{
+						Group:     "",
+						Kind:      "ConfigMap",
+						Name:      testutil.DummyCRName,
+						Namespace: testutil.Pointer(gatewayapiv1.Namespace(testutil.Namespace)),
+					},
+				},
+			},
+			paramsObj: &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      testutil.DummyCRName,
+					Namespace: testutil.Namespace,
+				},
+				Data: map[string]string{
+					"params": `{"namespaceMapping": {"strategy": "Prefixed", "prefix": "Edge_"}}`,
+				},
+			},
+			assertParams: assertError(IsInvalidParamsError),
+		},
 	}
 
 	scheme := runtime.NewScheme()
@@ -204,6 +364,9 @@ func TestGetParams(t *testing.T) {
 	if err := corev1.AddToScheme(scheme); err != nil {
 		t.Fatalf("unexpected error building scheme: %v", err)
 	}
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("unexpected error building scheme: %v", err)
+	}
 
 	for _, testCase := range cases {
 		t.Run(testCase.name, func(t *testing.T) {
@@ -221,6 +384,89 @@ func TestGetParams(t *testing.T) {
 	}
 }
 
+func TestValidateParams(t *testing.T) {
+	policyCRD := func(name string, specProperties map[string]apiextensionsv1.JSONSchemaProps) *apiextensionsv1.CustomResourceDefinition {
+		return &apiextensionsv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+				Versions: []apiextensionsv1.CustomResourceDefinitionVersion{
+					{
+						Name: "v1alpha1",
+						Schema: &apiextensionsv1.CustomResourceValidation{
+							OpenAPIV3Schema: &apiextensionsv1.JSONSchemaProps{
+								Properties: map[string]apiextensionsv1.JSONSchemaProps{
+									"spec": {Properties: specProperties},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+	policy := func(resource string) Params {
+		return Params{PoliciesToSync: []ParamsGroupVersionResource{{Group: "kuadrant.io", Version: "v1alpha1", Resource: resource}}}
+	}
+
+	scheme := runtime.NewScheme()
+	if err := apiextensionsv1.AddToScheme(scheme); err != nil {
+		t.Fatalf("unexpected error building scheme: %v", err)
+	}
+	mapper := meta.NewDefaultRESTMapper(nil)
+	for _, kind := range []string{"DNSPolicy", "Widget", "Unmanaged"} {
+		mapper.Add(schema.GroupVersionKind{Group: "kuadrant.io", Version: "v1alpha1", Kind: kind}, meta.RESTScopeNamespace)
+	}
+	c := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithRESTMapper(mapper).
+		WithObjects(
+			policyCRD("dnspolicies.kuadrant.io", map[string]apiextensionsv1.JSONSchemaProps{"targetRef": {}}),
+			policyCRD("widgets.kuadrant.io", map[string]apiextensionsv1.JSONSchemaProps{"size": {}}),
+		).
+		Build()
+
+	cases := []struct {
+		name   string
+		params Params
+		assert func(*Params, error) error
+	}{
+		{
+			name:   "no policies",
+			params: Params{DownstreamClass: "istio"},
+			assert: noError,
+		},
+		{
+			name:   "policy with targetRef",
+			params: policy("dnspolicies"),
+			assert: noError,
+		},
+		{
+			name:   "resource not served by the hub",
+			params: policy("authpolicies"),
+			assert: assertError(IsInvalidParamsError),
+		},
+		{
+			name:   "resource without CustomResourceDefinition",
+			params: policy("unmanageds"),
+			assert: assertError(IsInvalidParamsError),
+		},
+		{
+			name:   "resource without targetRef",
+			params: policy("widgets"),
+			assert: assertError(IsInvalidParamsError),
+		},
+	}
+
+	for _, testCase := range cases {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := validateParams(context.TODO(), c, &testCase.params)
+			if err := testCase.assert(&testCase.params, err); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
 // Assertion utils
 
 func and(assertions ...func(*Params, error) error) func(*Params, error) error {
@@ -263,3 +509,62 @@ func paramsEqual(expected Params) func(*Params, error) error {
 		return fmt.Errorf("unexpected params. Expected %v, got %v", expected, got)
 	}
 }
+
+func TestMetadataPropagation(t *testing.T) {
+	upstream := &gatewayapiv1.Gateway{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{
+				"app":                          "web",
+				"clusters.kuadrant.io/c1_lb":   "x",
+				placement.OCMPlacementLabel:    "gateway-placement",
+				"team.example.com/owner":       "edge",
+				"kuadrant.io/lb-attribute-geo": "eu",
+			},
+			Annotations: map[string]string{
+				GatewayClustersAnnotation:                  `["c1"]`,
+				GatewayDownstreamNamespaceAnnotation:       "edge",
+				gracePeriod.GracePeriodAnnotation:          "5m",
+				corev1.LastAppliedConfigAnnotation:         "{}",
+				"service.beta.kubernetes.io/aws-lb-scheme": "internal",
+			},
+		},
+	}
+
+	cases := []struct {
+		name                string
+		propagation         *MetadataPropagation
+		labels, annotations []string
+	}{
+		{
+			name:        "hub keys are not propagated by default",
+			labels:      []string{"app", "team.example.com/owner", "kuadrant.io/lb-attribute-geo"},
+			annotations: []string{"service.beta.kubernetes.io/aws-lb-scheme"},
+		},
+		{
+			name:        "allowed prefixes",
+			propagation: &MetadataPropagation{AllowPrefixes: []string{"team.example.com/", "service.beta.kubernetes.io/", LabelPrefix}},
+			labels:      []string{"team.example.com/owner", "kuadrant.io/lb-attribute-geo"},
+			annotations: []string{"service.beta.kubernetes.io/aws-lb-scheme"},
+		},
+		{
+			name:        "denied prefixes replace the defaults",
+			propagation: &MetadataPropagation{DenyPrefixes: []string{"team.example.com/", "service.beta.kubernetes.io/"}},
+			labels:      []string{"app", "clusters.kuadrant.io/c1_lb", placement.OCMPlacementLabel, "kuadrant.io/lb-attribute-geo"},
+			annotations: []string{GatewayClustersAnnotation, GatewayDownstreamNamespaceAnnotation, gracePeriod.GracePeriodAnnotation, corev1.LastAppliedConfigAnnotation},
+		},
+	}
+
+	for _, testCase := range cases {
+		t.Run(testCase.name, func(t *testing.T) {
+			downstream := &gatewayapiv1.Gateway{}
+			metadata.CopyLabelsPredicate(upstream, downstream, testCase.propagation.predicate())
+			metadata.CopyAnnotationsPredicate(upstream, downstream, testCase.propagation.predicate())
+			if labels := sets.KeySet(downstream.Labels); !labels.Equal(sets.New(testCase.labels...)) {
+				t.Errorf("expected labels %v got %v", testCase.labels, sets.List(labels))
+			}
+			if annotations := sets.KeySet(downstream.Annotations); !annotations.Equal(sets.New(testCase.annotations...)) {
+				t.Errorf("expected annotations %v got %v", testCase.annotations, sets.List(annotations))
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	gatewayapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayapiv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+)
+
+// secretRefPermitted returns whether the gateway is permitted to reference the secret. A secret
+// in another namespace can only be referenced when a ReferenceGrant in the namespace of the
+// secret allows it
+func secretRefPermitted(ctx context.Context, c client.Client, gateway *gatewayapiv1.Gateway, secretRef gatewayapiv1.SecretObjectReference) (bool, error) {
+	if secretRef.Namespace == nil || string(*secretRef.Namespace) == gateway.Namespace {
+		return true, nil
+	}
+	grants := &gatewayapiv1beta1.ReferenceGrantList{}
+	err := c.List(ctx, grants, client.InNamespace(string(*secretRef.Namespace)))
+	if meta.IsNoMatchError(err) || runtime.IsNotRegisteredError(err) {
+		// no reference can be granted without the ReferenceGrant CRD
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	for _, grant := range grants.Items {
+		if grantsFromGateway(grant, gateway.Namespace) && grantsToSecret(grant, string(secretRef.Name)) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func grantsFromGateway(grant gatewayapiv1beta1.ReferenceGrant, namespace string) bool {
+	for _, from := range grant.Spec.From {
+		if from.Group == gatewayapiv1.GroupName && from.Kind == "Gateway" && string(from.Namespace) == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+func grantsToSecret(grant gatewayapiv1beta1.ReferenceGrant, name string) bool {
+	for _, to := range grant.Spec.To {
+		if to.Group != corev1.GroupName || to.Kind != "Secret" {
+			continue
+		}
+		if to.Name == nil || *to.Name == "" || string(*to.Name) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// listenersRefNotPermitted returns the listeners of the gateway with certificateRefs that are
+// not permitted by a ReferenceGrant, with the message describing the refs
+func listenersRefNotPermitted(ctx context.Context, c client.Client, gateway *gatewayapiv1.Gateway) (map[gatewayapiv1.SectionName]string, error) {
+	notPermitted := map[gatewayapiv1.SectionName]string{}
+	for _, listener := range gateway.Spec.Listeners {
+		if listener.TLS == nil {
+			continue
+		}
+		for _, secretRef := range listener.TLS.CertificateRefs {
+			permitted, err := secretRefPermitted(ctx, c, gateway, secretRef)
+			if err != nil {
+				return nil, err
+			}
+			if !permitted {
+				notPermitted[listener.Name] = fmt.Sprintf("certificateRef to secret %s/%s is not permitted by any ReferenceGrant", string(*secretRef.Namespace), secretRef.Name)
+				break
+			}
+		}
+	}
+	return notPermitted, nil
+}
+
+func buildRefNotPermittedCondition(generation int64, message string) metav1.Condition {
+	return metav1.Condition{
+		Type:               string(gatewayapiv1.ListenerConditionResolvedRefs),
+		Status:             metav1.ConditionFalse,
+		Reason:             string(gatewayapiv1.ListenerReasonRefNotPermitted),
+		Message:            message,
+		ObservedGeneration: generation,
+	}
+}
+
+// mapReferenceGrantToGateways enqueues the gateways in the namespaces the ReferenceGrant
+// grants references from
+func mapReferenceGrantToGateways(c client.Client) func(context.Context, client.Object) []reconcile.Request {
+	return func(ctx context.Context, o client.Object) []reconcile.Request {
+		requests := []reconcile.Request{}
+		grant, ok := o.(*gatewayapiv1beta1.ReferenceGrant)
+		if !ok {
+			return requests
+		}
+		for _, from := range grant.Spec.From {
+			if from.Group != gatewayapiv1.GroupName || from.Kind != "Gateway" {
+				continue
+			}
+			gateways := &gatewayapiv1.GatewayList{}
+			if err := c.List(ctx, gateways, client.InNamespace(string(from.Namespace))); err != nil {
+				continue
+			}
+			for _, gateway := range gateways.Items {
+				requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&gateway)})
+			}
+		}
+		return requests
+	}
+}
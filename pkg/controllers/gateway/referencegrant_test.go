@@ -0,0 +1,115 @@
+//go:build unit
+
+package gateway
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	gatewayapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayapiv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+
+	testutil "github.com/Kuadrant/multicluster-gateway-controller/test/util"
+)
+
+func TestSecretRefPermitted(t *testing.T) {
+	scheme := testutil.GetValidTestScheme()
+	if err := gatewayapiv1beta1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	gateway := &gatewayapiv1.Gateway{ObjectMeta: metav1.ObjectMeta{Name: "test-gw", Namespace: testutil.Namespace}}
+	grant := func(fromNamespace string, name *gatewayapiv1.ObjectName) *gatewayapiv1beta1.ReferenceGrant {
+		return &gatewayapiv1beta1.ReferenceGrant{
+			ObjectMeta: metav1.ObjectMeta{Name: "grant", Namespace: "certs"},
+			Spec: gatewayapiv1beta1.ReferenceGrantSpec{
+				From: []gatewayapiv1beta1.ReferenceGrantFrom{{Group: gatewayapiv1.GroupName, Kind: "Gateway", Namespace: gatewayapiv1.Namespace(fromNamespace)}},
+				To:   []gatewayapiv1beta1.ReferenceGrantTo{{Group: "", Kind: "Secret", Name: name}},
+			},
+		}
+	}
+
+	cases := []struct {
+		name      string
+		grants    []client.Object
+		secretRef gatewayapiv1.SecretObjectReference
+		expected  bool
+	}{
+		{
+			name:      "secret in the gateway namespace",
+			secretRef: gatewayapiv1.SecretObjectReference{Name: "tls"},
+			expected:  true,
+		},
+		{
+			name:      "secret in another namespace without grant",
+			secretRef: gatewayapiv1.SecretObjectReference{Name: "tls", Namespace: testutil.Pointer(gatewayapiv1.Namespace("certs"))},
+			expected:  false,
+		},
+		{
+			name:      "secret in another namespace granted to the gateway namespace",
+			grants:    []client.Object{grant(testutil.Namespace, nil)},
+			secretRef: gatewayapiv1.SecretObjectReference{Name: "tls", Namespace: testutil.Pointer(gatewayapiv1.Namespace("certs"))},
+			expected:  true,
+		},
+		{
+			name:      "secret granted by name",
+			grants:    []client.Object{grant(testutil.Namespace, testutil.Pointer(gatewayapiv1.ObjectName("tls")))},
+			secretRef: gatewayapiv1.SecretObjectReference{Name: "tls", Namespace: testutil.Pointer(gatewayapiv1.Namespace("certs"))},
+			expected:  true,
+		},
+		{
+			name:      "another secret granted by name",
+			grants:    []client.Object{grant(testutil.Namespace, testutil.Pointer(gatewayapiv1.ObjectName("other")))},
+			secretRef: gatewayapiv1.SecretObjectReference{Name: "tls", Namespace: testutil.Pointer(gatewayapiv1.Namespace("certs"))},
+			expected:  false,
+		},
+		{
+			name:      "secret granted to another namespace",
+			grants:    []client.Object{grant("other", nil)},
+			secretRef: gatewayapiv1.SecretObjectReference{Name: "tls", Namespace: testutil.Pointer(gatewayapiv1.Namespace("certs"))},
+			expected:  false,
+		},
+	}
+
+	for _, testCase := range cases {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(testCase.grants...).Build()
+			permitted, err := secretRefPermitted(context.TODO(), c, gateway, testCase.secretRef)
+			if err != nil {
+				t.Fatalf("unexpected error %s", err)
+			}
+			if permitted != testCase.expected {
+				t.Fatalf("expected permitted %t got %t", testCase.expected, permitted)
+			}
+		})
+	}
+}
+
+func TestListenersRefNotPermitted(t *testing.T) {
+	gateway := &gatewayapiv1.Gateway{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-gw", Namespace: testutil.Namespace},
+		Spec: gatewayapiv1.GatewaySpec{
+			Listeners: []gatewayapiv1.Listener{
+				httpsListener("local", "local.example.com", "local-tls"),
+				{
+					Name:     "remote",
+					Protocol: gatewayapiv1.HTTPSProtocolType,
+					TLS: &gatewayapiv1.GatewayTLSConfig{
+						CertificateRefs: []gatewayapiv1.SecretObjectReference{{Name: "remote-tls", Namespace: testutil.Pointer(gatewayapiv1.Namespace("certs"))}},
+					},
+				},
+			},
+		},
+	}
+	// without the ReferenceGrant kind no reference to another namespace is permitted
+	c := fake.NewClientBuilder().WithScheme(testutil.GetValidTestScheme()).Build()
+	notPermitted, err := listenersRefNotPermitted(context.TODO(), c, gateway)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	if _, ok := notPermitted["remote"]; !ok || len(notPermitted) != 1 {
+		t.Fatalf("expected only the remote listener not to be permitted got %v", notPermitted)
+	}
+}
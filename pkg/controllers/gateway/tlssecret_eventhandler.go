@@ -0,0 +1,103 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	gatewayapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// GatewaySecretRefIndex indexes the gateways by the namespace/name of the secrets referenced by
+// the certificateRefs of their listeners
+const GatewaySecretRefIndex = "spec.listeners.tls.certificateRefs"
+
+// gatewaySecretRefs returns the namespace/name of the secrets referenced by the TLS listeners
+// of the gateway, defaulting their namespace to the namespace of the gateway
+func gatewaySecretRefs(obj client.Object) []string {
+	gateway, ok := obj.(*gatewayapiv1.Gateway)
+	if !ok {
+		return nil
+	}
+	refs := []string{}
+	for _, listener := range gateway.Spec.Listeners {
+		if listener.TLS == nil {
+			continue
+		}
+		for _, secretRef := range listener.TLS.CertificateRefs {
+			namespace := gateway.Namespace
+			if secretRef.Namespace != nil {
+				namespace = string(*secretRef.Namespace)
+			}
+			refs = append(refs, types.NamespacedName{Namespace: namespace, Name: string(secretRef.Name)}.String())
+		}
+	}
+	return refs
+}
+
+// IndexGatewaySecretRefs registers the GatewaySecretRefIndex with the indexer
+func IndexGatewaySecretRefs(ctx context.Context, indexer client.FieldIndexer) error {
+	return indexer.IndexField(ctx, &gatewayapiv1.Gateway{}, GatewaySecretRefIndex, gatewaySecretRefs)
+}
+
+// TLSSecretEventHandler enqueues the gateways referencing a secret from their TLS listeners, so
+// the secret is placed again with the gateways when it is rotated
+type TLSSecretEventHandler struct {
+	client client.Client
+}
+
+var _ handler.EventHandler = &TLSSecretEventHandler{}
+
+// Create implements handler.EventHandler
+func (eh *TLSSecretEventHandler) Create(ctx context.Context, e event.CreateEvent, q workqueue.RateLimitingInterface) {
+	eh.enqueueForObject(ctx, e.Object, q)
+}
+
+// Delete implements handler.EventHandler
+func (eh *TLSSecretEventHandler) Delete(ctx context.Context, e event.DeleteEvent, q workqueue.RateLimitingInterface) {
+	eh.enqueueForObject(ctx, e.Object, q)
+}
+
+// Generic implements handler.EventHandler
+func (eh *TLSSecretEventHandler) Generic(ctx context.Context, e event.GenericEvent, q workqueue.RateLimitingInterface) {
+	eh.enqueueForObject(ctx, e.Object, q)
+}
+
+// Update implements handler.EventHandler. Only changes to the data of the secret are placed
+func (eh *TLSSecretEventHandler) Update(ctx context.Context, e event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	oldSecret, ok := e.ObjectOld.(*corev1.Secret)
+	if !ok {
+		return
+	}
+	newSecret, ok := e.ObjectNew.(*corev1.Secret)
+	if !ok {
+		return
+	}
+	if oldSecret.Type == newSecret.Type && reflect.DeepEqual(oldSecret.Data, newSecret.Data) {
+		return
+	}
+	eh.enqueueForObject(ctx, newSecret, q)
+}
+
+func (eh *TLSSecretEventHandler) enqueueForObject(ctx context.Context, obj client.Object, q workqueue.RateLimitingInterface) {
+	gateways := &gatewayapiv1.GatewayList{}
+	if err := eh.client.List(ctx, gateways, client.MatchingFields{GatewaySecretRefIndex: client.ObjectKeyFromObject(obj).String()}); err != nil {
+		log.Log.Error(err, "failed to get gateways when enqueueing from tls secret")
+		return
+	}
+
+	for _, gateway := range gateways.Items {
+		log.Log.Info(fmt.Sprintf("Enqueing reconciliation from tls secret update to gateway/%s", gateway.Name))
+		q.Add(ctrl.Request{
+			NamespacedName: client.ObjectKeyFromObject(&gateway),
+		})
+	}
+}
@@ -0,0 +1,79 @@
+//go:build unit
+
+package gateway
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	gatewayapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	testutil "github.com/Kuadrant/multicluster-gateway-controller/test/util"
+)
+
+func TestTLSSecretEventHandler(t *testing.T) {
+	tlsListener := func(protocol gatewayapiv1.ProtocolType, refs ...gatewayapiv1.SecretObjectReference) gatewayapiv1.Listener {
+		return gatewayapiv1.Listener{
+			Name:     "tls",
+			Protocol: protocol,
+			TLS:      &gatewayapiv1.GatewayTLSConfig{CertificateRefs: refs},
+		}
+	}
+	gateway := func(name string, listeners ...gatewayapiv1.Listener) gatewayapiv1.Gateway {
+		return gatewayapiv1.Gateway{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testutil.Namespace},
+			Spec:       gatewayapiv1.GatewaySpec{Listeners: listeners},
+		}
+	}
+	gateways := []gatewayapiv1.Gateway{
+		gateway("defaulted-namespace", tlsListener(gatewayapiv1.HTTPSProtocolType, gatewayapiv1.SecretObjectReference{Name: "tls"})),
+		gateway("tls-protocol", tlsListener(gatewayapiv1.TLSProtocolType, gatewayapiv1.SecretObjectReference{Name: "tls", Namespace: testutil.Pointer(gatewayapiv1.Namespace(testutil.Namespace))})),
+		gateway("other-secret", tlsListener(gatewayapiv1.HTTPSProtocolType, gatewayapiv1.SecretObjectReference{Name: "other"})),
+		gateway("other-namespace", tlsListener(gatewayapiv1.HTTPSProtocolType, gatewayapiv1.SecretObjectReference{Name: "tls", Namespace: testutil.Pointer(gatewayapiv1.Namespace("other"))})),
+		gateway("http", gatewayapiv1.Listener{Name: "http", Protocol: gatewayapiv1.HTTPProtocolType}),
+	}
+	c := fake.NewClientBuilder().
+		WithScheme(testutil.GetValidTestScheme()).
+		WithLists(&gatewayapiv1.GatewayList{Items: gateways}).
+		WithIndex(&gatewayapiv1.Gateway{}, GatewaySecretRefIndex, gatewaySecretRefs).
+		Build()
+	eventHandler := &TLSSecretEventHandler{client: c}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "tls", Namespace: testutil.Namespace},
+		Data:       map[string][]byte{corev1.TLSCertKey: []byte("cert")},
+	}
+	referencing := []gatewayapiv1.Gateway{gateways[0], gateways[1]}
+
+	t.Run("created secret enqueues the gateways referencing it", func(t *testing.T) {
+		testQ := &TestQueue{t: t}
+		eventHandler.Create(context.Background(), event.CreateEvent{Object: secret}, testQ)
+		testQ.MustHaveEnqueued(referencing)
+	})
+
+	t.Run("rotated secret enqueues the gateways referencing it", func(t *testing.T) {
+		rotated := secret.DeepCopy()
+		rotated.Data[corev1.TLSCertKey] = []byte("rotated")
+		testQ := &TestQueue{t: t}
+		eventHandler.Update(context.Background(), event.UpdateEvent{ObjectOld: secret, ObjectNew: rotated}, testQ)
+		testQ.MustHaveEnqueued(referencing)
+	})
+
+	t.Run("metadata changes are not enqueued", func(t *testing.T) {
+		labelled := secret.DeepCopy()
+		labelled.Labels = map[string]string{"rotated": "false"}
+		testQ := &TestQueue{t: t}
+		eventHandler.Update(context.Background(), event.UpdateEvent{ObjectOld: secret, ObjectNew: labelled}, testQ)
+		testQ.MustHaveEnqueued(nil)
+	})
+
+	t.Run("unreferenced secret is not enqueued", func(t *testing.T) {
+		unreferenced := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "unreferenced", Namespace: testutil.Namespace}}
+		testQ := &TestQueue{t: t}
+		eventHandler.Delete(context.Background(), event.DeleteEvent{Object: unreferenced}, testQ)
+		testQ.MustHaveEnqueued(nil)
+	})
+}
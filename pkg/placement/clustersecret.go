@@ -0,0 +1,545 @@
+package placement
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	gatewayapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/clusterSecret"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/gracePeriod"
+)
+
+// ClientForSecret builds a client for the spoke cluster described by an Argo CD cluster secret
+type ClientForSecret func(secret *corev1.Secret) (client.Client, error)
+
+// clusterSecretPlacer places gateways by applying the downstream gateway and its
+// children directly to the spoke clusters using the Argo CD cluster secrets on the hub.
+// It allows a hub without OCM to place gateways. Clusters are chosen with the
+// ClusterLabelSelectorAnnotation, which is matched against the labels of the cluster secrets
+type clusterSecretPlacer struct {
+	c         client.Client
+	namespace string
+	clientFor ClientForSecret
+
+	mu      sync.Mutex
+	clients map[string]cachedClient
+	// placed are the clusters each gateway has been placed on, by gateway key
+	placed map[string]sets.Set[string]
+}
+
+type cachedClient struct {
+	resourceVersion string
+	client          client.Client
+}
+
+// NewClusterSecretPlacer returns a placer using the cluster secrets in the given namespace.
+// An empty namespace uses the cluster secrets in all namespaces
+func NewClusterSecretPlacer(c client.Client, namespace string) *clusterSecretPlacer {
+	return NewClusterSecretPlacerWithClients(c, namespace, clusterSecret.ClientFromSecret)
+}
+
+// NewClusterSecretPlacerWithClients returns a placer building the spoke clients with clientFor
+func NewClusterSecretPlacerWithClients(c client.Client, namespace string, clientFor ClientForSecret) *clusterSecretPlacer {
+	return &clusterSecretPlacer{
+		c:         c,
+		namespace: namespace,
+		clientFor: clientFor,
+		clients:   map[string]cachedClient{},
+		placed:    map[string]sets.Set[string]{},
+	}
+}
+
+// Place applies the downstream gateway and its children to the targeted clusters and removes them
+// from the clusters that are no longer targeted
+func (cp *clusterSecretPlacer) Place(ctx context.Context, upStreamGateway *gatewayapiv1.Gateway, downStreamGateway *gatewayapiv1.Gateway, children ...metav1.Object) (sets.Set[string], error) {
+	log := log.Log
+	log.V(3).Info("placement: placing with cluster secrets ", "gateway", upStreamGateway.Name, "gateway ns", upStreamGateway.Namespace)
+	key, err := cache.MetaNamespaceKeyFunc(upStreamGateway)
+	if err != nil {
+		return sets.New[string](), err
+	}
+	existingClusters, err := cp.GetPlacedClusters(ctx, upStreamGateway)
+	if err != nil {
+		return existingClusters, err
+	}
+	// remember the clusters placed on, even when failing half way through
+	defer cp.setPlacedClusters(key, existingClusters, upStreamGateway.GetDeletionTimestamp() != nil)
+
+	if upStreamGateway.GetDeletionTimestamp() != nil {
+		for _, cluster := range sets.List(existingClusters) {
+			if err := cp.remove(ctx, upStreamGateway, cluster, true); err != nil {
+				return existingClusters, err
+			}
+			existingClusters.Delete(cluster)
+		}
+		return existingClusters, nil
+	}
+
+	// an unmet constraint still places the gateway on the clusters that meet it
+	placementTargets, constraintErr := cp.GetClusters(ctx, upStreamGateway)
+	if constraintErr != nil && !IsUnmetConstraint(constraintErr) {
+		return existingClusters, constraintErr
+	}
+	log.V(3).Info("placement: ", "targets", placementTargets.UnsortedList(), "gateway", upStreamGateway.Name, "gateway ns", upStreamGateway.Namespace)
+
+	objects := []metav1.Object{downStreamGateway}
+	objects = append(objects, children...)
+	overrides, err := gatewayOverrides(ctx, cp.c, upStreamGateway)
+	if err != nil {
+		return existingClusters, err
+	}
+	for _, cluster := range sets.List(placementTargets) {
+		spokeClient, err := cp.clientForCluster(ctx, cluster)
+		if err != nil {
+			return existingClusters, err
+		}
+		// the overrides select clusters by the labels of their cluster secret
+		clusterObjects := objects
+		if len(overrides) > 0 {
+			secret, err := cp.secretForCluster(ctx, cluster)
+			if err != nil {
+				return existingClusters, err
+			}
+			clusterGateway, err := applyOverrides(downStreamGateway, overrides, secret.Labels)
+			if err != nil {
+				return existingClusters, err
+			}
+			clusterObjects = replaceObject(objects, downStreamGateway, clusterGateway)
+		}
+		if err := cp.apply(ctx, spokeClient, WorkName(upStreamGateway), key, clusterObjects...); err != nil {
+			log.V(3).Info("placement: ", "adding gateway to cluster ", cluster, "gateway", upStreamGateway.Name, "error", err)
+			return existingClusters, err
+		}
+		if err := cp.removeMigrated(ctx, spokeClient, WorkName(upStreamGateway), downStreamGateway, objects...); err != nil {
+			return existingClusters, err
+		}
+		existingClusters.Insert(cluster)
+	}
+
+	for _, cluster := range sets.List(existingClusters.Difference(placementTargets)) {
+		log.V(3).Info("placement: ", "removing gateway from cluster ", cluster, "gateway", upStreamGateway.Name, "gateway ns", upStreamGateway.Namespace)
+		if err := cp.remove(ctx, upStreamGateway, cluster, false); err != nil {
+			return existingClusters, err
+		}
+		existingClusters.Delete(cluster)
+	}
+
+	return existingClusters, constraintErr
+}
+
+// GetPlacedClusters returns the clusters the downstream gateway exists on. Every cluster is checked
+// the first time, after that only the clusters the gateway has been placed on are. A placed cluster
+// that can't be reached is kept, so removing the gateway from it is retried
+func (cp *clusterSecretPlacer) GetPlacedClusters(ctx context.Context, gateway *gatewayapiv1.Gateway) (sets.Set[string], error) {
+	placed := sets.New[string]()
+	key, err := cache.MetaNamespaceKeyFunc(gateway)
+	if err != nil {
+		return placed, err
+	}
+	secrets, err := cp.clusterSecrets(ctx, labels.Everything())
+	if err != nil {
+		return placed, err
+	}
+	cp.mu.Lock()
+	known, scanned := cp.placed[key]
+	cp.mu.Unlock()
+	// the clusters whose cluster secret was removed are no longer placed on
+	for i := range secrets {
+		cluster := clusterName(&secrets[i])
+		if scanned && !known.Has(cluster) {
+			continue
+		}
+		downstream, err := cp.findDownstreamGateway(ctx, gateway, cluster, &secrets[i])
+		if err != nil {
+			if !scanned {
+				return placed, fmt.Errorf("unable to check cluster %s for gateway: %w", cluster, err)
+			}
+			log.Log.V(3).Info("placement: unable to check cluster for gateway, keeping it placed", "cluster", cluster, "error", err)
+			placed.Insert(cluster)
+			continue
+		}
+		if downstream != nil && downstream.DeletionTimestamp == nil {
+			placed.Insert(cluster)
+		}
+	}
+	cp.setPlacedClusters(key, placed, false)
+	return placed.Clone(), nil
+}
+
+// setPlacedClusters records the clusters the gateway is placed on. The record of
+// a deleted gateway is dropped once it is no longer placed on any cluster
+func (cp *clusterSecretPlacer) setPlacedClusters(key string, placed sets.Set[string], deleted bool) {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	if deleted && placed.Len() == 0 {
+		delete(cp.placed, key)
+		return
+	}
+	cp.placed[key] = placed.Clone()
+}
+
+// GetClusters returns the clusters whose cluster secret matches the gateway's label selector
+func (cp *clusterSecretPlacer) GetClusters(ctx context.Context, gateway *gatewayapiv1.Gateway) (sets.Set[string], error) {
+	targetClusters := sets.New[string]()
+	selector, ok := gateway.GetAnnotations()[ClusterLabelSelectorAnnotation]
+	if !ok {
+		return targetClusters, nil
+	}
+	labelSelector, err := ParseClusterSelector(selector)
+	if err != nil {
+		return targetClusters, err
+	}
+	secrets, err := cp.clusterSecrets(ctx, labelSelector)
+	if err != nil {
+		return targetClusters, err
+	}
+	if !HasConstraints(gateway) {
+		for _, secret := range secrets {
+			targetClusters.Insert(clusterName(&secret))
+		}
+		return targetClusters, nil
+	}
+
+	constraints, err := ConstraintsFor(gateway)
+	if err != nil {
+		return targetClusters, err
+	}
+	targets := map[string]labels.Set{}
+	for _, secret := range secrets {
+		targets[clusterName(&secret)] = secret.Labels
+	}
+	return constraints.Apply(targets)
+}
+
+// GetAddresses returns the addresses reported by the downstream gateway on the cluster
+func (cp *clusterSecretPlacer) GetAddresses(ctx context.Context, gateway *gatewayapiv1.Gateway, downstream string) ([]gatewayapiv1.GatewayAddress, error) {
+	downstreamGateway, err := cp.downstreamGateway(ctx, gateway, downstream)
+	if err != nil {
+		return nil, err
+	}
+	if downstreamGateway == nil {
+		return nil, fmt.Errorf("gateway %s/%s not found in cluster %s", gateway.Namespace, gateway.Name, downstream)
+	}
+	addresses := []gatewayapiv1.GatewayAddress{}
+	for _, address := range downstreamGateway.Status.Addresses {
+		addresses = append(addresses, gatewayapiv1.GatewayAddress{
+			Type:  address.Type,
+			Value: address.Value,
+		})
+	}
+	return addresses, nil
+}
+
+// ListenerTotalAttachedRoutes returns the attached routes reported by the downstream gateway listener on the cluster
+func (cp *clusterSecretPlacer) ListenerTotalAttachedRoutes(ctx context.Context, gateway *gatewayapiv1.Gateway, listenerName string, downstream string) (int, error) {
+	downstreamGateway, err := cp.downstreamGateway(ctx, gateway, downstream)
+	if err != nil {
+		return 0, err
+	}
+	if downstreamGateway == nil {
+		return 0, fmt.Errorf("gateway %s/%s not found in cluster %s", gateway.Namespace, gateway.Name, downstream)
+	}
+	for _, listener := range downstreamGateway.Status.Listeners {
+		if string(listener.Name) == listenerName {
+			return int(listener.AttachedRoutes), nil
+		}
+	}
+	return 0, fmt.Errorf("no listener %s status found", listenerName)
+}
+
+// GetFailedClusters returns no clusters, failures to apply the gateway to a cluster are returned by Place
+func (cp *clusterSecretPlacer) GetFailedClusters(_ context.Context, _ *gatewayapiv1.Gateway) (sets.Set[string], error) {
+	return sets.New[string](), nil
+}
+
+// GetListenerStatus returns the status reported by the downstream gateway listener on the cluster
+func (cp *clusterSecretPlacer) GetListenerStatus(ctx context.Context, gateway *gatewayapiv1.Gateway, listenerName string, downstream string) (gatewayapiv1.ListenerStatus, error) {
+	downstreamGateway, err := cp.downstreamGateway(ctx, gateway, downstream)
+	if err != nil {
+		return gatewayapiv1.ListenerStatus{}, err
+	}
+	if downstreamGateway == nil {
+		return gatewayapiv1.ListenerStatus{}, fmt.Errorf("gateway %s/%s not found in cluster %s", gateway.Namespace, gateway.Name, downstream)
+	}
+	for _, listener := range downstreamGateway.Status.Listeners {
+		if string(listener.Name) == listenerName {
+			return listener, nil
+		}
+	}
+	return gatewayapiv1.ListenerStatus{}, fmt.Errorf("no listener %s status found", listenerName)
+}
+
+// downstreamGateway finds the gateway placed from the upstream gateway on the cluster.
+// It returns nil if the gateway has not been placed on the cluster
+func (cp *clusterSecretPlacer) downstreamGateway(ctx context.Context, gateway *gatewayapiv1.Gateway, cluster string) (*gatewayapiv1.Gateway, error) {
+	secret, err := cp.secretForCluster(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+	return cp.findDownstreamGateway(ctx, gateway, cluster, secret)
+}
+
+// findDownstreamGateway finds the gateway placed on the cluster described by the cluster secret
+func (cp *clusterSecretPlacer) findDownstreamGateway(ctx context.Context, gateway *gatewayapiv1.Gateway, cluster string, secret *corev1.Secret) (*gatewayapiv1.Gateway, error) {
+	spokeClient, err := cp.clientForSecret(cluster, secret)
+	if err != nil {
+		return nil, err
+	}
+	gateways := &gatewayapiv1.GatewayList{}
+	if err := spokeClient.List(ctx, gateways, client.MatchingLabels{WorkManifestLabel: WorkName(gateway)}); err != nil {
+		return nil, err
+	}
+	if len(gateways.Items) == 0 {
+		return nil, nil
+	}
+	return &gateways.Items[0], nil
+}
+
+// apply creates or updates the objects on the spoke. Each object is labelled so it can be found
+// and removed later on, and only updated when it differs from the one on the spoke. The namespaces of the objects are created but never removed, as other
+// workloads may be sharing them
+func (cp *clusterSecretPlacer) apply(ctx context.Context, spokeClient client.Client, workname, parent string, obj ...metav1.Object) error {
+	for _, ns := range manifestNamespaces(obj...) {
+		namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: ns}}
+		if err := spokeClient.Create(ctx, namespace); err != nil && !k8serrors.IsAlreadyExists(err) {
+			return err
+		}
+	}
+
+	for _, o := range obj {
+		desired, err := cp.toUnstructured(spokeClient, o)
+		if err != nil {
+			return err
+		}
+		labels := desired.GetLabels()
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		labels[WorkManifestLabel] = workname
+		desired.SetLabels(labels)
+		annotations := desired.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[ParentAnnotation] = parent
+		desired.SetAnnotations(annotations)
+
+		existing := &unstructured.Unstructured{}
+		existing.SetGroupVersionKind(desired.GroupVersionKind())
+		if err := spokeClient.Get(ctx, client.ObjectKeyFromObject(desired), existing); err != nil {
+			if !k8serrors.IsNotFound(err) {
+				return err
+			}
+			if err := spokeClient.Create(ctx, desired); err != nil {
+				return err
+			}
+			continue
+		}
+		if placedObjectEqual(existing, desired) {
+			continue
+		}
+		// keep the status and server managed metadata of the existing object
+		desired.SetResourceVersion(existing.GetResourceVersion())
+		if status, ok := existing.Object["status"]; ok {
+			desired.Object["status"] = status
+		}
+		if err := spokeClient.Update(ctx, desired); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// remove deletes the gateway and the children placed with it from the cluster. The
+// gateway is removed gracefully, the children are removed once the gateway is gone
+func (cp *clusterSecretPlacer) remove(ctx context.Context, gateway *gatewayapiv1.Gateway, cluster string, ignoreGrace bool) error {
+	spokeClient, err := cp.clientForCluster(ctx, cluster)
+	if err != nil {
+		return err
+	}
+	downstream, err := cp.downstreamGateway(ctx, gateway, cluster)
+	if err != nil {
+		return err
+	}
+	if downstream != nil {
+		period, err := gracePeriod.ForObject(gateway, gracePeriod.DefaultGracePeriod)
+		if err != nil {
+			return err
+		}
+		if err := gracePeriod.GracefulDeleteAfter(ctx, spokeClient, downstream, period, ignoreGrace); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+	secrets := &corev1.SecretList{}
+	if err := spokeClient.List(ctx, secrets, client.MatchingLabels{WorkManifestLabel: WorkName(gateway)}); err != nil {
+		return err
+	}
+	for i := range secrets.Items {
+		if err := spokeClient.Delete(ctx, &secrets.Items[i]); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeMigrated deletes the gateways and secrets placed with the work in namespaces the objects are no
+// longer placed in, for example when the namespace of the downstream gateway changes. They are only
+// removed once the downstream gateway reports its addresses in the new namespace
+func (cp *clusterSecretPlacer) removeMigrated(ctx context.Context, spokeClient client.Client, workname string, downstream *gatewayapiv1.Gateway, obj ...metav1.Object) error {
+	namespaces := sets.New(manifestNamespaces(obj...)...)
+	gateways := &gatewayapiv1.GatewayList{}
+	if err := spokeClient.List(ctx, gateways, client.MatchingLabels{WorkManifestLabel: workname}); err != nil {
+		return err
+	}
+	migrated := []client.Object{}
+	for i := range gateways.Items {
+		gateway := &gateways.Items[i]
+		if !namespaces.Has(gateway.Namespace) {
+			migrated = append(migrated, gateway)
+			continue
+		}
+		if gateway.Namespace == downstream.Namespace && gateway.Name == downstream.Name && len(gateway.Status.Addresses) == 0 {
+			// the gateway is not serving from the new namespace yet
+			return nil
+		}
+	}
+	secrets := &corev1.SecretList{}
+	if err := spokeClient.List(ctx, secrets, client.MatchingLabels{WorkManifestLabel: workname}); err != nil {
+		return err
+	}
+	for i := range secrets.Items {
+		if !namespaces.Has(secrets.Items[i].Namespace) {
+			migrated = append(migrated, &secrets.Items[i])
+		}
+	}
+	for _, o := range migrated {
+		log.Log.V(3).Info("placement: removing object migrated to another namespace", "kind", fmt.Sprintf("%T", o), "namespace", o.GetNamespace(), "name", o.GetName())
+		if err := spokeClient.Delete(ctx, o); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// placedObjectEqual compares the labels, annotations and content of the object placed on the
+// spoke, ignoring its status and the metadata managed by the spoke
+func placedObjectEqual(existing, desired *unstructured.Unstructured) bool {
+	if !equality.Semantic.DeepEqual(existing.GetLabels(), desired.GetLabels()) ||
+		!equality.Semantic.DeepEqual(existing.GetAnnotations(), desired.GetAnnotations()) {
+		return false
+	}
+	content := func(u *unstructured.Unstructured) map[string]interface{} {
+		c := map[string]interface{}{}
+		for k, v := range u.Object {
+			if k != "metadata" && k != "status" {
+				c[k] = v
+			}
+		}
+		return c
+	}
+	return equality.Semantic.DeepEqual(content(existing), content(desired))
+}
+
+func (cp *clusterSecretPlacer) toUnstructured(spokeClient client.Client, obj metav1.Object) (*unstructured.Unstructured, error) {
+	runtimeObj, ok := obj.(runtime.Object)
+	if !ok {
+		return nil, fmt.Errorf("object %s/%s is not a runtime object", obj.GetNamespace(), obj.GetName())
+	}
+	gvk, err := apiutil.GVKForObject(runtimeObj, spokeClient.Scheme())
+	if err != nil {
+		return nil, err
+	}
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(runtimeObj)
+	if err != nil {
+		return nil, err
+	}
+	u := &unstructured.Unstructured{Object: content}
+	u.SetGroupVersionKind(gvk)
+	// server managed fields from the hub can not be set on the spoke
+	u.SetResourceVersion("")
+	u.SetUID("")
+	u.SetCreationTimestamp(metav1.Time{})
+	u.SetManagedFields(nil)
+	u.SetOwnerReferences(nil)
+	delete(u.Object, "status")
+	return u, nil
+}
+
+// clientForCluster returns a client for the cluster, reusing the client built
+// from the cluster secret until the secret changes
+func (cp *clusterSecretPlacer) clientForCluster(ctx context.Context, cluster string) (client.Client, error) {
+	secret, err := cp.secretForCluster(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+	return cp.clientForSecret(cluster, secret)
+}
+
+// clientForSecret returns a client for the cluster described by the cluster secret
+func (cp *clusterSecretPlacer) clientForSecret(cluster string, secret *corev1.Secret) (client.Client, error) {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	if cached, ok := cp.clients[cluster]; ok && cached.resourceVersion == secret.ResourceVersion {
+		return cached.client, nil
+	}
+	spokeClient, err := cp.clientFor(secret)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create client for cluster %s: %w", cluster, err)
+	}
+	cp.clients[cluster] = cachedClient{resourceVersion: secret.ResourceVersion, client: spokeClient}
+	return spokeClient, nil
+}
+
+func (cp *clusterSecretPlacer) secretForCluster(ctx context.Context, cluster string) (*corev1.Secret, error) {
+	secrets, err := cp.clusterSecrets(ctx, labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	for i := range secrets {
+		if clusterName(&secrets[i]) == cluster {
+			return &secrets[i], nil
+		}
+	}
+	return nil, k8serrors.NewNotFound(corev1.Resource("secrets"), cluster)
+}
+
+// clusterSecrets returns the cluster secrets matching the selector
+func (cp *clusterSecretPlacer) clusterSecrets(ctx context.Context, selector labels.Selector) ([]corev1.Secret, error) {
+	secrets := &corev1.SecretList{}
+	requirement, err := labels.NewRequirement(clusterSecret.CLUSTER_SECRET_LABEL, selection.Equals, []string{clusterSecret.CLUSTER_SECRET_LABEL_VALUE})
+	if err != nil {
+		return nil, err
+	}
+	listOptions := &client.ListOptions{
+		Namespace:     cp.namespace,
+		LabelSelector: selector.Add(*requirement),
+	}
+	if err := cp.c.List(ctx, secrets, listOptions); err != nil {
+		return nil, err
+	}
+	return secrets.Items, nil
+}
+
+// clusterName returns the name of the cluster in the cluster secret, falling back
+// to the name of the secret
+func clusterName(secret *corev1.Secret) string {
+	if name := string(secret.Data["name"]); name != "" {
+		return name
+	}
+	return secret.Name
+}
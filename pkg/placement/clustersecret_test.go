@@ -0,0 +1,288 @@
+//go:build unit
+
+package placement_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	gatewayapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/clusterSecret"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/gracePeriod"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/placement"
+)
+
+func TestClusterSecretPlacer(t *testing.T) {
+	spokeScheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(spokeScheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := gatewayapiv1.AddToScheme(spokeScheme); err != nil {
+		t.Fatal(err)
+	}
+
+	clusterSecretFor := func(name string, labels map[string]string) *corev1.Secret {
+		labels[clusterSecret.CLUSTER_SECRET_LABEL] = clusterSecret.CLUSTER_SECRET_LABEL_VALUE
+		return &corev1.Secret{
+			ObjectMeta: v1.ObjectMeta{
+				Name:      name + "-secret",
+				Namespace: "argocd",
+				Labels:    labels,
+			},
+			Data: map[string][]byte{"name": []byte(name)},
+		}
+	}
+	hub := fake.NewClientBuilder().WithObjects(
+		clusterSecretFor("c1", map[string]string{"region": "eu"}),
+		clusterSecretFor("c2", map[string]string{"region": "us"}),
+	).Build()
+	spokes := map[string]client.Client{
+		"c1": fake.NewClientBuilder().WithScheme(spokeScheme).Build(),
+		"c2": fake.NewClientBuilder().WithScheme(spokeScheme).Build(),
+	}
+	p := placement.NewClusterSecretPlacerWithClients(hub, "argocd", func(secret *corev1.Secret) (client.Client, error) {
+		return spokes[string(secret.Data["name"])], nil
+	})
+
+	upstream := &gatewayapiv1.Gateway{
+		TypeMeta: v1.TypeMeta{
+			Kind:       "Gateway",
+			APIVersion: "gateway.networking.k8s.io/v1",
+		},
+		ObjectMeta: v1.ObjectMeta{
+			Name:        "test",
+			Namespace:   "test",
+			Annotations: map[string]string{placement.ClusterLabelSelectorAnnotation: "region=eu"},
+		},
+		Spec: gatewayapiv1.GatewaySpec{
+			Listeners: []gatewayapiv1.Listener{{Name: "api", Port: 443, Protocol: gatewayapiv1.HTTPSProtocolType}},
+		},
+	}
+	downstream := upstream.DeepCopy()
+	downstream.Namespace = "kuadrant-test"
+	tlsSecret := &corev1.Secret{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "api-tls",
+			Namespace: "kuadrant-test",
+		},
+	}
+
+	placed, err := p.Place(context.TODO(), upstream, downstream, tlsSecret)
+	if err != nil {
+		t.Fatalf("did not expect an error placing gateway but got %s", err)
+	}
+	if !placed.Equal(sets.New("c1")) {
+		t.Fatalf("expected gateway to be placed on c1 got %v", sets.List(placed))
+	}
+	if err := spokes["c1"].Get(context.TODO(), client.ObjectKeyFromObject(tlsSecret), &corev1.Secret{}); err != nil {
+		t.Fatalf("expected tls secret to be placed on c1 %s", err)
+	}
+	if err := spokes["c2"].Get(context.TODO(), client.ObjectKeyFromObject(downstream), &gatewayapiv1.Gateway{}); !k8serrors.IsNotFound(err) {
+		t.Fatalf("expected gateway not to be placed on c2 got %v", err)
+	}
+
+	// read back the status reported by the spoke
+	spokeGateway := &gatewayapiv1.Gateway{}
+	if err := spokes["c1"].Get(context.TODO(), client.ObjectKeyFromObject(downstream), spokeGateway); err != nil {
+		t.Fatal(err)
+	}
+	ipAddressType := gatewayapiv1.IPAddressType
+	spokeGateway.Status.Addresses = []gatewayapiv1.GatewayStatusAddress{{Type: &ipAddressType, Value: "172.16.0.1"}}
+	spokeGateway.Status.Listeners = []gatewayapiv1.ListenerStatus{{Name: "api", AttachedRoutes: 2}}
+	if err := spokes["c1"].Update(context.TODO(), spokeGateway); err != nil {
+		t.Fatal(err)
+	}
+	addresses, err := p.GetAddresses(context.TODO(), upstream, "c1")
+	if err != nil {
+		t.Fatalf("did not expect an error getting addresses but got %s", err)
+	}
+	if len(addresses) != 1 || addresses[0].Value != "172.16.0.1" {
+		t.Fatalf("expected address 172.16.0.1 got %v", addresses)
+	}
+	routes, err := p.ListenerTotalAttachedRoutes(context.TODO(), upstream, "api", "c1")
+	if err != nil {
+		t.Fatalf("did not expect an error getting attached routes but got %s", err)
+	}
+	if routes != 2 {
+		t.Fatalf("expected 2 attached routes got %d", routes)
+	}
+	listenerStatus, err := p.GetListenerStatus(context.TODO(), upstream, "api", "c1")
+	if err != nil {
+		t.Fatalf("did not expect an error getting listener status but got %s", err)
+	}
+	if listenerStatus.AttachedRoutes != 2 {
+		t.Fatalf("expected listener status with 2 attached routes got %v", listenerStatus)
+	}
+
+	// re-placing keeps the status reported by the spoke
+	if _, err := p.Place(context.TODO(), upstream, downstream, tlsSecret); err != nil {
+		t.Fatalf("did not expect an error placing gateway but got %s", err)
+	}
+	if addresses, _ := p.GetAddresses(context.TODO(), upstream, "c1"); len(addresses) != 1 {
+		t.Fatalf("expected spoke status to be kept got %v", addresses)
+	}
+
+	// moving the gateway to another namespace keeps the old one until the new one reports its addresses
+	previous, previousSecret := downstream.DeepCopy(), tlsSecret.DeepCopy()
+	downstream.Namespace, tlsSecret.Namespace = "test-edge", "test-edge"
+	if _, err := p.Place(context.TODO(), upstream, downstream, tlsSecret); err != nil {
+		t.Fatalf("did not expect an error placing gateway but got %s", err)
+	}
+	if err := spokes["c1"].Get(context.TODO(), client.ObjectKeyFromObject(previous), &gatewayapiv1.Gateway{}); err != nil {
+		t.Fatalf("expected gateway to be kept in the previous namespace %s", err)
+	}
+	if err := spokes["c1"].Get(context.TODO(), client.ObjectKeyFromObject(downstream), spokeGateway); err != nil {
+		t.Fatal(err)
+	}
+	spokeGateway.Status.Addresses = []gatewayapiv1.GatewayStatusAddress{{Type: &ipAddressType, Value: "172.16.0.2"}}
+	if err := spokes["c1"].Update(context.TODO(), spokeGateway); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Place(context.TODO(), upstream, downstream, tlsSecret); err != nil {
+		t.Fatalf("did not expect an error placing gateway but got %s", err)
+	}
+	if err := spokes["c1"].Get(context.TODO(), client.ObjectKeyFromObject(previous), &gatewayapiv1.Gateway{}); !k8serrors.IsNotFound(err) {
+		t.Fatalf("expected gateway to be removed from the previous namespace got %v", err)
+	}
+	if err := spokes["c1"].Get(context.TODO(), client.ObjectKeyFromObject(previousSecret), &corev1.Secret{}); !k8serrors.IsNotFound(err) {
+		t.Fatalf("expected tls secret to be removed from the previous namespace got %v", err)
+	}
+
+	// moving the gateway is subject to the grace period
+	upstream.Annotations[placement.ClusterLabelSelectorAnnotation] = "region=us"
+	placed, err = p.Place(context.TODO(), upstream, downstream, tlsSecret)
+	if !errors.Is(err, gracePeriod.ErrGracePeriodNotExpired) {
+		t.Fatalf("expected grace period error got %v", err)
+	}
+	if !placed.Equal(sets.New("c1", "c2")) {
+		t.Fatalf("expected gateway to be placed on c1 and c2 got %v", sets.List(placed))
+	}
+
+	// deleting the gateway removes it from every cluster
+	upstream.DeletionTimestamp = &v1.Time{}
+	placed, err = p.Place(context.TODO(), upstream, downstream, tlsSecret)
+	if err != nil {
+		t.Fatalf("did not expect an error removing gateway but got %s", err)
+	}
+	if placed.Len() != 0 {
+		t.Fatalf("expected gateway to be removed from all clusters got %v", sets.List(placed))
+	}
+	for cluster, spoke := range spokes {
+		if err := spoke.Get(context.TODO(), client.ObjectKeyFromObject(tlsSecret), &corev1.Secret{}); !k8serrors.IsNotFound(err) {
+			t.Fatalf("expected tls secret to be removed from %s got %v", cluster, err)
+		}
+	}
+}
+
+// unreachableClient fails every call when the cluster is unreachable and counts the updates made
+type unreachableClient struct {
+	client.Client
+	unreachable bool
+	updates     int
+}
+
+func (c *unreachableClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	if c.unreachable {
+		return errors.New("cluster unreachable")
+	}
+	return c.Client.List(ctx, list, opts...)
+}
+
+func (c *unreachableClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	if c.unreachable {
+		return errors.New("cluster unreachable")
+	}
+	c.updates++
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+func TestClusterSecretPlacer_UnreachableCluster(t *testing.T) {
+	spokeScheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(spokeScheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := gatewayapiv1.AddToScheme(spokeScheme); err != nil {
+		t.Fatal(err)
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "c1-secret",
+			Namespace: "argocd",
+			Labels: map[string]string{
+				clusterSecret.CLUSTER_SECRET_LABEL: clusterSecret.CLUSTER_SECRET_LABEL_VALUE,
+				"region":                           "eu",
+			},
+		},
+		Data: map[string][]byte{"name": []byte("c1")},
+	}
+	hub := fake.NewClientBuilder().WithObjects(secret).Build()
+	spoke := &unreachableClient{Client: fake.NewClientBuilder().WithScheme(spokeScheme).Build()}
+	p := placement.NewClusterSecretPlacerWithClients(hub, "argocd", func(_ *corev1.Secret) (client.Client, error) {
+		return spoke, nil
+	})
+
+	upstream := &gatewayapiv1.Gateway{
+		TypeMeta: v1.TypeMeta{
+			Kind:       "Gateway",
+			APIVersion: "gateway.networking.k8s.io/v1",
+		},
+		ObjectMeta: v1.ObjectMeta{
+			Name:        "test",
+			Namespace:   "test",
+			Annotations: map[string]string{placement.ClusterLabelSelectorAnnotation: "region=eu"},
+		},
+		Spec: gatewayapiv1.GatewaySpec{
+			Listeners: []gatewayapiv1.Listener{{Name: "api", Port: 443, Protocol: gatewayapiv1.HTTPSProtocolType}},
+		},
+	}
+	downstream := upstream.DeepCopy()
+	downstream.Namespace = "kuadrant-test"
+
+	if _, err := p.Place(context.TODO(), upstream, downstream); err != nil {
+		t.Fatalf("did not expect an error placing gateway but got %s", err)
+	}
+
+	// placing an unchanged gateway does not update it on the spoke
+	if _, err := p.Place(context.TODO(), upstream, downstream); err != nil {
+		t.Fatalf("did not expect an error placing gateway but got %s", err)
+	}
+	if spoke.updates != 0 {
+		t.Fatalf("expected unchanged gateway not to be updated got %d updates", spoke.updates)
+	}
+
+	// an unreachable cluster is kept placed so removing the gateway from it is retried
+	spoke.unreachable = true
+	placed, err := p.GetPlacedClusters(context.TODO(), upstream)
+	if err != nil {
+		t.Fatalf("did not expect an error getting placed clusters but got %s", err)
+	}
+	if !placed.Equal(sets.New("c1")) {
+		t.Fatalf("expected unreachable cluster c1 to be kept placed got %v", sets.List(placed))
+	}
+	upstream.DeletionTimestamp = &v1.Time{}
+	if _, err := p.Place(context.TODO(), upstream, downstream); err == nil {
+		t.Fatal("expected an error removing gateway from unreachable cluster")
+	}
+
+	spoke.unreachable = false
+	placed, err = p.Place(context.TODO(), upstream, downstream)
+	if err != nil {
+		t.Fatalf("did not expect an error removing gateway but got %s", err)
+	}
+	if placed.Len() != 0 {
+		t.Fatalf("expected gateway to be removed from all clusters got %v", sets.List(placed))
+	}
+	if err := spoke.Get(context.TODO(), client.ObjectKeyFromObject(downstream), &gatewayapiv1.Gateway{}); !k8serrors.IsNotFound(err) {
+		t.Fatalf("expected gateway to be removed from c1 got %v", err)
+	}
+}
@@ -0,0 +1,162 @@
+package placement
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	gatewayapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+const (
+	// MinClustersAnnotation is the minimum number of clusters the gateway must be placed on
+	MinClustersAnnotation = "kuadrant.io/gateway-min-clusters"
+	// MaxClustersAnnotation is the maximum number of clusters the gateway is placed on
+	MaxClustersAnnotation = "kuadrant.io/gateway-max-clusters"
+	// SpreadLabelAnnotation is a cluster label key. The gateway is placed on at most one cluster
+	// for each value of the label, clusters without the label are not used
+	SpreadLabelAnnotation = "kuadrant.io/gateway-spread-label"
+	// PreferredClusterSelectorAnnotation is a cluster label selector. Matching clusters are chosen
+	// first when the spread or maximum constraints leave a choice of clusters
+	PreferredClusterSelectorAnnotation = "kuadrant.io/gateway-preferred-cluster-selector"
+)
+
+const (
+	ConstraintReasonInvalid              = "InvalidPlacementConstraints"
+	ConstraintReasonInsufficientClusters = "InsufficientClusters"
+)
+
+// ConstraintError is returned when the placement constraints of a gateway are invalid
+// or can not be met by the targeted clusters
+type ConstraintError struct {
+	Reason  string
+	Message string
+}
+
+func (e *ConstraintError) Error() string {
+	return e.Message
+}
+
+// IsUnmetConstraint returns true if the error is because the targeted clusters do not meet
+// the constraints. The clusters that do meet them can still be used
+func IsUnmetConstraint(err error) bool {
+	constraintErr := &ConstraintError{}
+	return errors.As(err, &constraintErr) && constraintErr.Reason == ConstraintReasonInsufficientClusters
+}
+
+// Constraints restrict the clusters a gateway is placed on out of those targeted by its placement
+type Constraints struct {
+	MinClusters int
+	MaxClusters int
+	SpreadLabel string
+	Preferred   labels.Selector
+}
+
+// HasConstraints returns true if the gateway sets any placement constraint
+func HasConstraints(gateway *gatewayapiv1.Gateway) bool {
+	for _, annotation := range []string{MinClustersAnnotation, MaxClustersAnnotation, SpreadLabelAnnotation, PreferredClusterSelectorAnnotation} {
+		if _, ok := gateway.GetAnnotations()[annotation]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ConstraintsFor parses the placement constraints from the gateway annotations
+func ConstraintsFor(gateway *gatewayapiv1.Gateway) (*Constraints, error) {
+	annotations := gateway.GetAnnotations()
+	constraints := &Constraints{
+		SpreadLabel: strings.TrimSpace(annotations[SpreadLabelAnnotation]),
+	}
+	var err error
+	if constraints.MinClusters, err = parseClusterCount(annotations, MinClustersAnnotation); err != nil {
+		return nil, err
+	}
+	if constraints.MaxClusters, err = parseClusterCount(annotations, MaxClustersAnnotation); err != nil {
+		return nil, err
+	}
+	if constraints.MaxClusters > 0 && constraints.MinClusters > constraints.MaxClusters {
+		return nil, &ConstraintError{
+			Reason:  ConstraintReasonInvalid,
+			Message: fmt.Sprintf("%s %d is greater than %s %d", MinClustersAnnotation, constraints.MinClusters, MaxClustersAnnotation, constraints.MaxClusters),
+		}
+	}
+	if selector, ok := annotations[PreferredClusterSelectorAnnotation]; ok {
+		if constraints.Preferred, err = labels.Parse(selector); err != nil {
+			return nil, &ConstraintError{
+				Reason:  ConstraintReasonInvalid,
+				Message: fmt.Sprintf("invalid %s %q: %s", PreferredClusterSelectorAnnotation, selector, err),
+			}
+		}
+	}
+	return constraints, nil
+}
+
+func parseClusterCount(annotations map[string]string, annotation string) (int, error) {
+	value, ok := annotations[annotation]
+	if !ok {
+		return 0, nil
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || count < 0 {
+		return 0, &ConstraintError{
+			Reason:  ConstraintReasonInvalid,
+			Message: fmt.Sprintf("invalid %s %q: must be a positive number", annotation, value),
+		}
+	}
+	return count, nil
+}
+
+// Apply chooses the clusters out of the targets that meet the constraints. The targets map the cluster
+// names to their labels. When the minimum number of clusters is not met the chosen clusters are
+// returned along with an error
+func (c *Constraints) Apply(targets map[string]labels.Set) (sets.Set[string], error) {
+	// preferred clusters first, then by name so the choice is stable
+	candidates := make([]string, 0, len(targets))
+	for cluster := range targets {
+		candidates = append(candidates, cluster)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		pi, pj := c.preferred(targets[candidates[i]]), c.preferred(targets[candidates[j]])
+		if pi != pj {
+			return pi
+		}
+		return candidates[i] < candidates[j]
+	})
+
+	chosen := sets.New[string]()
+	spread := sets.New[string]()
+	for _, cluster := range candidates {
+		if c.MaxClusters > 0 && chosen.Len() >= c.MaxClusters {
+			break
+		}
+		if c.SpreadLabel != "" {
+			value, ok := targets[cluster][c.SpreadLabel]
+			if !ok || spread.Has(value) {
+				continue
+			}
+			spread.Insert(value)
+		}
+		chosen.Insert(cluster)
+	}
+
+	if chosen.Len() < c.MinClusters {
+		message := fmt.Sprintf("gateway requires at least %d clusters but only %d of the %d targeted clusters", c.MinClusters, chosen.Len(), len(targets))
+		if c.SpreadLabel != "" {
+			message += fmt.Sprintf(" with a distinct %s label", c.SpreadLabel)
+		}
+		return chosen, &ConstraintError{
+			Reason:  ConstraintReasonInsufficientClusters,
+			Message: message + " are available",
+		}
+	}
+	return chosen, nil
+}
+
+func (c *Constraints) preferred(clusterLabels labels.Set) bool {
+	return c.Preferred != nil && !c.Preferred.Empty() && c.Preferred.Matches(clusterLabels)
+}
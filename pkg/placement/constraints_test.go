@@ -0,0 +1,108 @@
+//go:build unit
+
+package placement_test
+
+import (
+	"testing"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	gatewayapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/placement"
+)
+
+func TestConstraints(t *testing.T) {
+	targets := map[string]labels.Set{
+		"c1": {"region": "eu"},
+		"c2": {"region": "eu", "tier": "gold"},
+		"c3": {"region": "us"},
+		"c4": {},
+	}
+
+	testCases := []struct {
+		Name        string
+		Annotations map[string]string
+		Expected    sets.Set[string]
+		Reason      string
+	}{
+		{
+			Name:     "test no constraints keeps all targets",
+			Expected: sets.New("c1", "c2", "c3", "c4"),
+		},
+		{
+			Name:        "test spread places one cluster per label value",
+			Annotations: map[string]string{placement.SpreadLabelAnnotation: "region"},
+			Expected:    sets.New("c1", "c3"),
+		},
+		{
+			Name: "test preferred clusters are chosen first when spreading",
+			Annotations: map[string]string{
+				placement.SpreadLabelAnnotation:              "region",
+				placement.PreferredClusterSelectorAnnotation: "tier=gold",
+			},
+			Expected: sets.New("c2", "c3"),
+		},
+		{
+			Name: "test max clusters chooses preferred clusters",
+			Annotations: map[string]string{
+				placement.MaxClustersAnnotation:              "2",
+				placement.PreferredClusterSelectorAnnotation: "region=us",
+			},
+			Expected: sets.New("c1", "c3"),
+		},
+		{
+			Name: "test min clusters not met returns the chosen clusters",
+			Annotations: map[string]string{
+				placement.SpreadLabelAnnotation: "region",
+				placement.MinClustersAnnotation: "3",
+			},
+			Expected: sets.New("c1", "c3"),
+			Reason:   placement.ConstraintReasonInsufficientClusters,
+		},
+		{
+			Name:        "test invalid min clusters",
+			Annotations: map[string]string{placement.MinClustersAnnotation: "many"},
+			Reason:      placement.ConstraintReasonInvalid,
+		},
+		{
+			Name: "test min clusters greater than max clusters",
+			Annotations: map[string]string{
+				placement.MinClustersAnnotation: "3",
+				placement.MaxClustersAnnotation: "2",
+			},
+			Reason: placement.ConstraintReasonInvalid,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			gateway := &gatewayapiv1.Gateway{ObjectMeta: v1.ObjectMeta{Annotations: testCase.Annotations}}
+			if placement.HasConstraints(gateway) != (len(testCase.Annotations) > 0) {
+				t.Fatalf("expected gateway to have constraints %v", len(testCase.Annotations) > 0)
+			}
+			constraints, err := placement.ConstraintsFor(gateway)
+			if err == nil {
+				var got sets.Set[string]
+				got, err = constraints.Apply(targets)
+				if !got.Equal(testCase.Expected) {
+					t.Fatalf("expected clusters %v got %v", sets.List(testCase.Expected), sets.List(got))
+				}
+			}
+			if testCase.Reason == "" {
+				if err != nil {
+					t.Fatalf("did not expect an error but got %s", err)
+				}
+				return
+			}
+			constraintErr, ok := err.(*placement.ConstraintError)
+			if !ok || constraintErr.Reason != testCase.Reason {
+				t.Fatalf("expected constraint error with reason %s got %v", testCase.Reason, err)
+			}
+			if placement.IsUnmetConstraint(err) != (testCase.Reason == placement.ConstraintReasonInsufficientClusters) {
+				t.Fatalf("unexpected unmet constraint for %v", err)
+			}
+		})
+	}
+}
@@ -40,8 +40,15 @@ func (p *FakeGatewayPlacer) GetPlacedClusters(_ context.Context, gateway *gatewa
 	return placedClusters, nil
 }
 
-func (p *FakeGatewayPlacer) GetClusters(_ context.Context, _ *gatewayapiv1.Gateway) (sets.Set[string], error) {
-	return nil, nil
+func (p *FakeGatewayPlacer) GetFailedClusters(_ context.Context, _ *gatewayapiv1.Gateway) (sets.Set[string], error) {
+	return sets.New[string](), nil
+}
+
+func (p *FakeGatewayPlacer) GetClusters(_ context.Context, gateway *gatewayapiv1.Gateway) (sets.Set[string], error) {
+	if gateway.Labels == nil {
+		return nil, nil
+	}
+	return sets.New(testutil.Cluster), nil
 }
 
 func (p *FakeGatewayPlacer) ListenerTotalAttachedRoutes(_ context.Context, _ *gatewayapiv1.Gateway, listenerName string, _ string) (int, error) {
@@ -51,6 +58,26 @@ func (p *FakeGatewayPlacer) ListenerTotalAttachedRoutes(_ context.Context, _ *ga
 	return 0, nil
 }
 
+func (p *FakeGatewayPlacer) GetListenerStatus(_ context.Context, _ *gatewayapiv1.Gateway, listenerName string, _ string) (gatewayapiv1.ListenerStatus, error) {
+	status := gatewayapiv1.ListenerStatus{
+		Name: gatewayapiv1.SectionName(listenerName),
+		SupportedKinds: []gatewayapiv1.RouteGroupKind{
+			{Kind: "HTTPRoute"},
+		},
+		Conditions: []metav1.Condition{
+			{
+				Type:   string(gatewayapiv1.ListenerConditionProgrammed),
+				Status: metav1.ConditionTrue,
+				Reason: string(gatewayapiv1.ListenerReasonProgrammed),
+			},
+		},
+	}
+	if listenerName == testutil.Cluster {
+		status.AttachedRoutes = 1
+	}
+	return status, nil
+}
+
 func (p *FakeGatewayPlacer) GetAddresses(_ context.Context, _ *gatewayapiv1.Gateway, _ string) ([]gatewayapiv1.GatewayAddress, error) {
 	t := gatewayapiv1.IPAddressType
 	return []gatewayapiv1.GatewayAddress{
@@ -0,0 +1,321 @@
+package placement
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	workv1 "open-cluster-management.io/api/work/v1"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// manifest builds the manifests for the given objects. A single Namespace
+// manifest comes first for each namespace the objects live in, followed by
+// the root object of the work, which is the first object, and then by the
+// children, deduplicated and sorted, so the workload only changes when the
+// objects change
+func (op *ocmPlacer) manifest(obj ...metav1.Object) ([]workv1.Manifest, error) {
+	if len(obj) == 0 {
+		return []workv1.Manifest{}, nil
+	}
+
+	root := obj[0]
+	seen := sets.New(objectKey(root))
+	children := []metav1.Object{}
+	for _, o := range obj[1:] {
+		key := objectKey(o)
+		if seen.Has(key) {
+			continue
+		}
+		seen.Insert(key)
+		children = append(children, o)
+	}
+	sort.SliceStable(children, func(i, j int) bool {
+		return objectKey(children[i]) < objectKey(children[j])
+	})
+
+	manifests := []workv1.Manifest{}
+	for _, ns := range manifestNamespaces(obj...) {
+		m, err := toManifest(&v1.Namespace{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       "Namespace",
+				APIVersion: "v1",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name: ns,
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		manifests = append(manifests, m)
+	}
+
+	for _, o := range append([]metav1.Object{root}, children...) {
+		m, err := toManifest(o)
+		if err != nil {
+			return nil, err
+		}
+		manifests = append(manifests, m)
+	}
+
+	return manifests, nil
+}
+
+// manifestNamespaces returns the sorted namespaces of the given objects
+func manifestNamespaces(obj ...metav1.Object) []string {
+	namespaces := sets.New[string]()
+	for _, o := range obj {
+		if o.GetNamespace() != "" {
+			namespaces.Insert(o.GetNamespace())
+		}
+	}
+	return sets.List(namespaces)
+}
+
+// namespaceOrphaningRules returns the delete option for a work so the
+// namespaces it creates are left behind when the work, or the namespace
+// manifest, is removed. Other workloads may be sharing the namespace
+func namespaceOrphaningRules(namespaces ...string) *workv1.DeleteOption {
+	rules := []workv1.OrphaningRule{}
+	for _, ns := range sets.List(sets.New(namespaces...)) {
+		rules = append(rules, workv1.OrphaningRule{
+			Group:    "",
+			Resource: "namespaces",
+			Name:     ns,
+		})
+	}
+	return &workv1.DeleteOption{
+		PropagationPolicy: workv1.DeletePropagationPolicyTypeSelectivelyOrphan,
+		SelectivelyOrphan: &workv1.SelectivelyOrphan{
+			OrphaningRules: rules,
+		},
+	}
+}
+
+// orphanedNamespaces returns the namespaces orphaned by the existing work
+func orphanedNamespaces(work *workv1.ManifestWork) []string {
+	namespaces := []string{}
+	if work.Spec.DeleteOption == nil || work.Spec.DeleteOption.SelectivelyOrphan == nil {
+		return namespaces
+	}
+	for _, rule := range work.Spec.DeleteOption.SelectivelyOrphan.OrphaningRules {
+		if rule.Group == "" && rule.Resource == "namespaces" {
+			namespaces = append(namespaces, rule.Name)
+		}
+	}
+	return namespaces
+}
+
+// removedNamespaces returns the namespaces orphaned by the existing work whose
+// Namespace manifest is being dropped from the desired work. These still need
+// to be orphaned while the work agent prunes them
+func removedNamespaces(existing, desired *workv1.ManifestWork) []string {
+	removed := namespaceManifests(existing.Spec.Workload.Manifests).Difference(namespaceManifests(desired.Spec.Workload.Manifests))
+	return sets.List(removed.Intersection(sets.New(orphanedNamespaces(existing)...)))
+}
+
+// namespaceManifests returns the names of the Namespace objects in the manifests
+func namespaceManifests(manifests []workv1.Manifest) sets.Set[string] {
+	namespaces := sets.New[string]()
+	for _, m := range manifests {
+		decoded, ok := decodeManifest(m).(map[string]interface{})
+		if !ok || decoded["kind"] != "Namespace" {
+			continue
+		}
+		if metadata, ok := decoded["metadata"].(map[string]interface{}); ok {
+			if name, ok := metadata["name"].(string); ok {
+				namespaces.Insert(name)
+			}
+		}
+	}
+	return namespaces
+}
+
+// migratingManifests splits the manifests of the existing work into those that are kept and those
+// in namespaces the desired manifests no longer use, along with the Namespace manifests of those
+// namespaces. These are migrating, for example when the namespace of the downstream gateway changes
+func migratingManifests(existing, desired []workv1.Manifest) (kept, migrating []workv1.Manifest) {
+	namespaces := sets.New[string]()
+	for _, m := range desired {
+		if ns := manifestNamespace(m); ns != "" {
+			namespaces.Insert(ns)
+		}
+	}
+	for _, m := range existing {
+		if ns := manifestNamespace(m); ns != "" && !namespaces.Has(ns) {
+			migrating = append(migrating, m)
+			continue
+		}
+		kept = append(kept, m)
+	}
+	return kept, migrating
+}
+
+// retainMigratingManifests returns the desired manifests along with the migrating manifests of the
+// existing work until every desired manifest has been applied, so the objects in the namespaces
+// being left are only removed once their replacements exist
+func retainMigratingManifests(existing, desired []workv1.Manifest, applied bool) []workv1.Manifest {
+	if applied {
+		return desired
+	}
+	_, migrating := migratingManifests(existing, desired)
+	return append(append([]workv1.Manifest{}, desired...), migrating...)
+}
+
+// manifestsApplied returns whether every manifest is reported as applied in each of the statuses
+func manifestsApplied(manifests []workv1.Manifest, statuses ...workv1.ManifestResourceStatus) bool {
+	for _, status := range statuses {
+		applied := sets.New[string]()
+		for _, condition := range status.Manifests {
+			if meta.IsStatusConditionTrue(condition.Conditions, string(workv1.ManifestApplied)) {
+				resource := condition.ResourceMeta
+				applied.Insert(fmt.Sprintf("%s/%s/%s/%s", resource.Group, resource.Kind, resource.Namespace, resource.Name))
+			}
+		}
+		for _, m := range manifests {
+			decoded, ok := decodeManifest(m).(map[string]interface{})
+			if !ok {
+				return false
+			}
+			apiVersion, _ := decoded["apiVersion"].(string)
+			group, _, found := strings.Cut(apiVersion, "/")
+			if !found {
+				group = ""
+			}
+			metadata, _ := decoded["metadata"].(map[string]interface{})
+			namespace, _ := metadata["namespace"].(string)
+			if !applied.Has(fmt.Sprintf("%s/%v/%s/%v", group, decoded["kind"], namespace, metadata["name"])) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// manifestNamespace returns the namespace of the object in the manifest, or the name of the
+// namespace for a Namespace manifest
+func manifestNamespace(m workv1.Manifest) string {
+	decoded, ok := decodeManifest(m).(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	metadata, _ := decoded["metadata"].(map[string]interface{})
+	if decoded["kind"] == "Namespace" {
+		name, _ := metadata["name"].(string)
+		return name
+	}
+	namespace, _ := metadata["namespace"].(string)
+	return namespace
+}
+
+// ManifestWorkSpecEqual compares two ManifestWork specs. The manifests are
+// compared by their decoded content rather than their raw bytes, as the
+// serialised form stored by the API server does not keep the field order or
+// empty fields of the object that was submitted
+func ManifestWorkSpecEqual(a, b workv1.ManifestWorkSpec) bool {
+	if len(a.Workload.Manifests) != len(b.Workload.Manifests) {
+		return false
+	}
+	for i := range a.Workload.Manifests {
+		if !equality.Semantic.DeepEqual(decodeManifest(a.Workload.Manifests[i]), decodeManifest(b.Workload.Manifests[i])) {
+			return false
+		}
+	}
+
+	a.Workload, b.Workload = workv1.ManifestsTemplate{}, workv1.ManifestsTemplate{}
+	return equality.Semantic.DeepEqual(a, b)
+}
+
+// ManifestIdentifiers returns a description of each object in the manifests
+func ManifestIdentifiers(manifests []workv1.Manifest) sets.Set[string] {
+	ids := sets.New[string]()
+	for _, m := range manifests {
+		decoded, ok := decodeManifest(m).(map[string]interface{})
+		if !ok {
+			continue
+		}
+		metadata, _ := decoded["metadata"].(map[string]interface{})
+		ids.Insert(fmt.Sprintf("%v/%v/%v/%v", decoded["apiVersion"], decoded["kind"], metadata["namespace"], metadata["name"]))
+	}
+	return ids
+}
+
+func toManifest(obj interface{}) (workv1.Manifest, error) {
+	jsonData, err := json.Marshal(obj)
+	if err != nil {
+		return workv1.Manifest{}, err
+	}
+	return workv1.Manifest{RawExtension: runtime.RawExtension{Raw: jsonData}}, nil
+}
+
+func decodeManifest(m workv1.Manifest) interface{} {
+	raw := m.Raw
+	if raw == nil && m.Object != nil {
+		var err error
+		if raw, err = json.Marshal(m.Object); err != nil {
+			return nil
+		}
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		return nil
+	}
+	return pruneEmpty(decoded)
+}
+
+// pruneEmpty removes null and empty values, which are dropped when the
+// manifest is stored
+func pruneEmpty(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			pruned := pruneEmpty(field)
+			if isEmpty(pruned) {
+				delete(v, key)
+				continue
+			}
+			v[key] = pruned
+		}
+		return v
+	case []interface{}:
+		for i := range v {
+			v[i] = pruneEmpty(v[i])
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+func isEmpty(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case map[string]interface{}:
+		return len(v) == 0
+	default:
+		return false
+	}
+}
+
+// objectKey identifies an object by its kind, namespace and name. The kind is read from the
+// GroupVersionKind of the object, as unstructured objects of every kind share the same type,
+// falling back to the type of objects without one
+func objectKey(obj metav1.Object) string {
+	kind := reflect.TypeOf(obj).String()
+	if o, ok := obj.(runtime.Object); ok {
+		if gvk := o.GetObjectKind().GroupVersionKind(); !gvk.Empty() {
+			kind = gvk.String()
+		}
+	}
+	return fmt.Sprintf("%s/%s/%s", kind, obj.GetNamespace(), obj.GetName())
+}
@@ -3,6 +3,7 @@ package placement
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 
@@ -10,13 +11,12 @@ import (
 	placement "open-cluster-management.io/api/cluster/v1beta1"
 	workv1 "open-cluster-management.io/api/work/v1"
 
-	v1 "k8s.io/api/core/v1"
 	rbac "k8s.io/api/rbac/v1"
-	"k8s.io/apimachinery/pkg/api/equality"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	k8smeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -33,33 +33,109 @@ const (
 	rbacName          = "open-cluster-management:klusterlet-work:gateway"
 	rbacManifest      = "gateway-rbac"
 	WorkManifestLabel = "kuadrant.io/manifestKey"
+	ParentAnnotation  = "kuadrant.io/parent"
 )
 
+// ClusterLabelSelectorAnnotation selects the ManagedClusters to place a gateway on by their labels.
+// It is only used when the gateway does not reference an OCM Placement
+const ClusterLabelSelectorAnnotation = "kuadrant.io/gateway-cluster-label-selector"
+
+// ErrPlacementDecisionMissing is returned when there is no PlacementDecision for the OCM Placement the gateway references
+var ErrPlacementDecisionMissing = errors.New("no PlacementDecisions found")
+
+// ErrWorkDeleting is returned while the works of a deleted gateway are being removed from the clusters, as the
+// RBAC of the work agent is only removed once they are gone
+var ErrWorkDeleting = errors.New("waiting for the gateway works to be removed")
+
+// DefaultWorkAgentSubject is the service account of the work agent of a default klusterlet install,
+// which is allowed to manage the objects placed on the spokes
+var DefaultWorkAgentSubject = rbac.Subject{
+	Kind:      rbac.ServiceAccountKind,
+	Name:      "klusterlet-work-sa",
+	Namespace: "open-cluster-management-agent",
+}
+
 type ocmPlacer struct {
-	c client.Client
+	c                client.Client
+	secretDelivery   SecretDelivery
+	workAgentSubject rbac.Subject
 }
 
-func NewOCMPlacer(c client.Client) *ocmPlacer {
+// OCMPlacerOption configures the OCM placers
+type OCMPlacerOption func(*ocmPlacer)
 
-	return &ocmPlacer{
-		c: c,
+// WithSecretDelivery sets how the TLS secrets of the gateways are delivered to the spokes.
+// By default they are embedded in the ManifestWorks
+func WithSecretDelivery(delivery SecretDelivery) OCMPlacerOption {
+	return func(op *ocmPlacer) {
+		op.secretDelivery = delivery
+	}
+}
+
+// WithWorkAgentSubject sets the subject of the work agent on the spokes that is allowed to manage
+// the objects placed. Defaults to the DefaultWorkAgentSubject
+func WithWorkAgentSubject(subject rbac.Subject) OCMPlacerOption {
+	return func(op *ocmPlacer) {
+		op.workAgentSubject = subject
+	}
+}
+
+func NewOCMPlacer(c client.Client, opts ...OCMPlacerOption) *ocmPlacer {
+
+	op := &ocmPlacer{
+		c:                c,
+		workAgentSubject: DefaultWorkAgentSubject,
+	}
+	for _, opt := range opts {
+		opt(op)
 	}
+	return op
 }
 
 func (op *ocmPlacer) GetAddresses(ctx context.Context, gateway *gatewayapiv1.Gateway, downstream string) ([]gatewayapiv1.GatewayAddress, error) {
-	workname := WorkName(gateway)
-	addresses := []gatewayapiv1.GatewayAddress{}
-	rootMeta, _ := k8smeta.Accessor(gateway)
+	mw, err := op.gatewayWork(ctx, gateway, downstream)
+	if err != nil {
+		return []gatewayapiv1.GatewayAddress{}, err
+	}
+	return workAddresses(gateway, mw)
+}
+
+func (op *ocmPlacer) ListenerTotalAttachedRoutes(ctx context.Context, gateway *gatewayapiv1.Gateway, listenerName string, downstream string) (int, error) {
+	mw, err := op.gatewayWork(ctx, gateway, downstream)
+	if err != nil {
+		return 0, err
+	}
+	return workListenerTotalAttachedRoutes(gateway, mw, listenerName)
+}
+
+// GetListenerStatus returns the status of the downstream gateway listener reported through the ManifestWork status feedback
+func (op *ocmPlacer) GetListenerStatus(ctx context.Context, gateway *gatewayapiv1.Gateway, listenerName string, downstream string) (gatewayapiv1.ListenerStatus, error) {
+	mw, err := op.gatewayWork(ctx, gateway, downstream)
+	if err != nil {
+		return emptyListenerStatus(listenerName), err
+	}
+	return workListenerStatus(gateway, mw, listenerName)
+}
+
+// gatewayWork returns the ManifestWork placing the gateway on the cluster
+func (op *ocmPlacer) gatewayWork(ctx context.Context, gateway *gatewayapiv1.Gateway, downstream string) (*workv1.ManifestWork, error) {
 	mw := &workv1.ManifestWork{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      workname,
+			Name:      WorkName(gateway),
 			Namespace: downstream,
 		},
 	}
-	var err error
-	if err = op.c.Get(ctx, client.ObjectKeyFromObject(mw), mw, &client.GetOptions{}); err != nil {
-		return addresses, err
+	if err := op.c.Get(ctx, client.ObjectKeyFromObject(mw), mw, &client.GetOptions{}); err != nil {
+		return nil, err
 	}
+	return mw, nil
+}
+
+// workAddresses returns the addresses of the downstream gateway reported through the ManifestWork status feedback
+func workAddresses(gateway *gatewayapiv1.Gateway, mw *workv1.ManifestWork) ([]gatewayapiv1.GatewayAddress, error) {
+	addresses := []gatewayapiv1.GatewayAddress{}
+	rootMeta, _ := k8smeta.Accessor(gateway)
+	var err error
 	for _, m := range mw.Status.ResourceStatus.Manifests {
 		if m.ResourceMeta.Group == gateway.GetObjectKind().GroupVersionKind().Group && m.ResourceMeta.Name == rootMeta.GetName() {
 			for _, value := range m.StatusFeedbacks.Values {
@@ -73,18 +149,10 @@ func (op *ocmPlacer) GetAddresses(ctx context.Context, gateway *gatewayapiv1.Gat
 	return addresses, err
 }
 
-func (op *ocmPlacer) ListenerTotalAttachedRoutes(ctx context.Context, gateway *gatewayapiv1.Gateway, listenerName string, downstream string) (int, error) {
-	workname := WorkName(gateway)
+// workListenerTotalAttachedRoutes returns the routes attached to the downstream gateway listener reported
+// through the ManifestWork status feedback
+func workListenerTotalAttachedRoutes(gateway *gatewayapiv1.Gateway, mw *workv1.ManifestWork, listenerName string) (int, error) {
 	rootMeta, _ := k8smeta.Accessor(gateway)
-	mw := &workv1.ManifestWork{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      workname,
-			Namespace: downstream,
-		},
-	}
-	if err := op.c.Get(ctx, client.ObjectKeyFromObject(mw), mw, &client.GetOptions{}); err != nil {
-		return 0, err
-	}
 	for _, m := range mw.Status.ResourceStatus.Manifests {
 		if m.ResourceMeta.Group == gateway.GetObjectKind().GroupVersionKind().Group && m.ResourceMeta.Name == rootMeta.GetName() {
 			for _, value := range m.StatusFeedbacks.Values {
@@ -99,6 +167,51 @@ func (op *ocmPlacer) ListenerTotalAttachedRoutes(ctx context.Context, gateway *g
 
 }
 
+func emptyListenerStatus(listenerName string) gatewayapiv1.ListenerStatus {
+	return gatewayapiv1.ListenerStatus{
+		Name:           gatewayapiv1.SectionName(listenerName),
+		SupportedKinds: []gatewayapiv1.RouteGroupKind{},
+		Conditions:     []metav1.Condition{},
+	}
+}
+
+// workListenerStatus returns the status of the downstream gateway listener reported through the ManifestWork status feedback
+func workListenerStatus(gateway *gatewayapiv1.Gateway, mw *workv1.ManifestWork, listenerName string) (gatewayapiv1.ListenerStatus, error) {
+	rootMeta, _ := k8smeta.Accessor(gateway)
+	status := emptyListenerStatus(listenerName)
+	found := false
+	for _, m := range mw.Status.ResourceStatus.Manifests {
+		if m.ResourceMeta.Group != gateway.GetObjectKind().GroupVersionKind().Group || m.ResourceMeta.Name != rootMeta.GetName() {
+			continue
+		}
+		for _, value := range m.StatusFeedbacks.Values {
+			var err error
+			switch strings.ToLower(value.Name) {
+			case strings.ToLower(fmt.Sprintf("listener%sAttachedRoutes", listenerName)):
+				if value.Value.Integer != nil {
+					status.AttachedRoutes = int32(*value.Value.Integer)
+					found = true
+				}
+			case strings.ToLower(fmt.Sprintf("listener%sSupportedKinds", listenerName)):
+				if value.Value.JsonRaw != nil {
+					err = json.Unmarshal([]byte(*value.Value.JsonRaw), &status.SupportedKinds)
+				}
+			case strings.ToLower(fmt.Sprintf("listener%sConditions", listenerName)):
+				if value.Value.JsonRaw != nil {
+					err = json.Unmarshal([]byte(*value.Value.JsonRaw), &status.Conditions)
+				}
+			}
+			if err != nil {
+				return status, fmt.Errorf("invalid listener %s status feedback %s: %w", listenerName, value.Name, err)
+			}
+		}
+	}
+	if !found {
+		return status, fmt.Errorf("no listener %s status found", listenerName)
+	}
+	return status, nil
+}
+
 func WorkName(rootObj runtime.Object) string {
 	kind := rootObj.GetObjectKind().GroupVersionKind().Kind
 	rootMeta, _ := k8smeta.Accessor(rootObj)
@@ -113,9 +226,10 @@ func (op *ocmPlacer) Place(ctx context.Context, upStreamGateway *gatewayapiv1.Ga
 	workname := WorkName(upStreamGateway)
 	emyptySet := sets.Set[string](sets.NewString())
 	// where the placement decision says to place the gateway
-	placementTargets, err := op.GetClusters(ctx, upStreamGateway)
-	if err != nil {
-		return emyptySet, err
+	// an unmet constraint still places the gateway on the clusters that meet it
+	placementTargets, constraintErr := op.GetClusters(ctx, upStreamGateway)
+	if constraintErr != nil && !IsUnmetConstraint(constraintErr) {
+		return emyptySet, constraintErr
 	}
 	log.V(3).Info("placement: ", "targets", placementTargets.UnsortedList(), "gateway", downStreamGateway.Name, "gateway ns", upStreamGateway.Namespace)
 	existingClusters, err := op.GetPlacedClusters(ctx, upStreamGateway)
@@ -128,8 +242,13 @@ func (op *ocmPlacer) Place(ctx context.Context, upStreamGateway *gatewayapiv1.Ga
 	log.V(3).Info("placement: ", "removeFrom", removeFrom.UnsortedList(), "gateway", upStreamGateway.Name, "gateway ns", upStreamGateway.Namespace)
 	// if being deleted entirely remove manifest from all existing clusters
 	if upStreamGateway.GetDeletionTimestamp() != nil {
-		log.V(3).Info("placement: ", "deleting gateway from ", existingClusters.UnsortedList(), "gateway", upStreamGateway.Name, "gateway ns", upStreamGateway.Namespace)
-		for _, cluster := range existingClusters.UnsortedList() {
+		// every work of the gateway is deleted, including those not applied
+		workClusters, err := op.workClusters(ctx, upStreamGateway)
+		if err != nil {
+			return existingClusters, err
+		}
+		log.V(3).Info("placement: ", "deleting gateway from ", workClusters.UnsortedList(), "gateway", upStreamGateway.Name, "gateway ns", upStreamGateway.Namespace)
+		for _, cluster := range workClusters.UnsortedList() {
 			// being deleted need to remove from clusters
 			w := &workv1.ManifestWork{ObjectMeta: metav1.ObjectMeta{
 				Name:      workname,
@@ -140,11 +259,42 @@ func (op *ocmPlacer) Place(ctx context.Context, upStreamGateway *gatewayapiv1.Ga
 			}
 			existingClusters.Delete(cluster)
 		}
-		return existingClusters, nil
+		// the work agent needs the RBAC to remove the objects of the works, so it is only
+		// removed once they are gone
+		remaining, err := op.workClusters(ctx, upStreamGateway)
+		if err != nil {
+			return existingClusters, err
+		}
+		if remaining.Len() > 0 {
+			return existingClusters, fmt.Errorf("%w from clusters %v", ErrWorkDeleting, sets.List(remaining))
+		}
+		return existingClusters, op.removeUnusedRBAC(ctx, nil)
 	}
 	objects := []metav1.Object{downStreamGateway}
 	objects = append(objects, children...)
-	for _, cluster := range placementTargets.UnsortedList() {
+
+	// with a progressive rollout only some of the clusters with an out of date gateway are updated
+	updateTargets := placementTargets
+	batch, err := rolloutBatch(upStreamGateway, placementTargets.Len())
+	if err != nil {
+		return existingClusters, err
+	}
+	var rolloutErr error
+	if batch > 0 {
+		desired := map[string]workv1.ManifestWork{}
+		for _, cluster := range placementTargets.UnsortedList() {
+			if desired[cluster], err = op.clusterManifestWork(ctx, workname, upStreamGateway, downStreamGateway, cluster, objects...); err != nil {
+				return existingClusters, err
+			}
+		}
+		updateTargets, rolloutErr = op.rolloutClusters(ctx, batch, placementTargets, desired)
+		if rolloutErr != nil && !errors.As(rolloutErr, new(*RolloutError)) {
+			return existingClusters, rolloutErr
+		}
+		log.V(3).Info("placement: ", "rollout", rolloutErr, "gateway", upStreamGateway.Name, "gateway ns", upStreamGateway.Namespace)
+	}
+
+	for _, cluster := range updateTargets.UnsortedList() {
 		log.V(3).Info("placement: ", "adding gateway rbac to cluster ", cluster, "gateway", upStreamGateway.Name, "gateway ns", upStreamGateway.Namespace)
 		if err := op.defaultRBAC(ctx, cluster); err != nil {
 			log.V(3).Info("placement: ", "adding gateway rbac to cluster ", cluster, "gateway", upStreamGateway.Name, "gateway ns", upStreamGateway.Namespace, "error", err)
@@ -162,7 +312,6 @@ func (op *ocmPlacer) Place(ctx context.Context, upStreamGateway *gatewayapiv1.Ga
 	// remove from remove
 	for _, cluster := range removeFrom.UnsortedList() {
 		log.V(3).Info("placement: ", "removing gateway from cluster ", cluster, "gateway", upStreamGateway.Name, "gateway ns", upStreamGateway.Namespace)
-		//todo remove rbac
 		w := &workv1.ManifestWork{
 			ObjectMeta: metav1.ObjectMeta{
 				Name:      workname,
@@ -189,27 +338,27 @@ func (op *ocmPlacer) Place(ctx context.Context, upStreamGateway *gatewayapiv1.Ga
 			log.V(3).Info(fmt.Sprintf("ManagedCluster not found '%s', ignoring grace period", cluster))
 			ignoreGrace = true
 		}
-		if err := gracePeriod.GracefulDelete(ctx, op.c, w, ignoreGrace); err != nil {
-			// use a multi-error
-			log.V(3).Info("error during graceful delete", "error", err)
+		period, err := gracePeriod.ForObject(upStreamGateway, gracePeriod.DefaultGracePeriod)
+		if err != nil {
 			return existingClusters, err
 		}
-
-		log.V(3).Info("graceful delete of gateway manifestwork complete, deleting RBAC")
-		rbac := &workv1.ManifestWork{
-			ObjectMeta: metav1.ObjectMeta{
-				Namespace: cluster,
-				Name:      rbacManifest,
-			},
-		}
-		if err := op.c.Delete(ctx, rbac, &client.DeleteOptions{}); client.IgnoreNotFound(err) != nil {
+		if err := gracePeriod.GracefulDeleteAfter(ctx, op.c, w, period, ignoreGrace); err != nil {
 			// use a multi-error
+			log.V(3).Info("error during graceful delete", "error", err)
 			return existingClusters, err
 		}
+
+		log.V(3).Info("graceful delete of gateway manifestwork complete")
 		existingClusters.Delete(cluster)
 	}
 
-	return existingClusters, nil
+	// the RBAC is removed from the clusters left by the last gateway once its works are gone there. The
+	// targeted clusters keep it, as the works just placed on them may not be listed yet
+	if err := op.removeUnusedRBAC(ctx, placementTargets); err != nil {
+		return existingClusters, err
+	}
+
+	return existingClusters, errors.Join(constraintErr, rolloutErr)
 }
 
 // GetPlacedClusters will return the list of clusters this gateway has been successfully placed on
@@ -234,13 +383,64 @@ func (op *ocmPlacer) GetPlacedClusters(ctx context.Context, gateway *gatewayapiv
 	return existingClusters, nil
 }
 
+// GetFailedClusters returns the clusters where the ManifestWork placing the gateway failed to apply
+func (op *ocmPlacer) GetFailedClusters(ctx context.Context, gateway *gatewayapiv1.Gateway) (sets.Set[string], error) {
+	existing := &workv1.ManifestWorkList{}
+	if err := op.c.List(ctx, existing, client.MatchingLabels{WorkManifestLabel: WorkName(gateway)}); err != nil {
+		return sets.New[string](), err
+	}
+	return failedWorkClusters(existing.Items), nil
+}
+
+// failedWorkClusters returns the clusters of the works that failed to apply
+func failedWorkClusters(works []workv1.ManifestWork) sets.Set[string] {
+	failed := sets.New[string]()
+	for _, w := range works {
+		if w.DeletionTimestamp == nil && meta.IsStatusConditionFalse(w.Status.Conditions, workv1.WorkApplied) {
+			failed.Insert(w.GetNamespace())
+		}
+	}
+	return failed
+}
+
 // GetClusters will return the set of clusters this gateway is targeted to be placed on. It does not check the placement has happened
+// Any placement constraints on the gateway are applied to the clusters in the placement decision
 func (op *ocmPlacer) GetClusters(ctx context.Context, gateway *gatewayapiv1.Gateway) (sets.Set[string], error) {
+	targetClusters, err := op.getTargetedClusters(ctx, gateway)
+	// a deleting gateway is removed from every cluster so its constraints no longer matter
+	if err != nil || !HasConstraints(gateway) || gateway.GetDeletionTimestamp() != nil {
+		return targetClusters, err
+	}
+	constraints, err := ConstraintsFor(gateway)
+	if err != nil {
+		return sets.Set[string](sets.NewString()), err
+	}
+	clusters := &clusterv1.ManagedClusterList{}
+	if err := op.c.List(ctx, clusters); err != nil {
+		return sets.Set[string](sets.NewString()), err
+	}
+	targets := map[string]labels.Set{}
+	for _, cluster := range sets.List(targetClusters) {
+		targets[cluster] = labels.Set{}
+	}
+	for _, cluster := range clusters.Items {
+		if _, ok := targets[cluster.Name]; ok {
+			targets[cluster.Name] = cluster.Labels
+		}
+	}
+	return constraints.Apply(targets)
+}
+
+// getTargetedClusters returns the clusters in the placement decision or matching the cluster label selector
+func (op *ocmPlacer) getTargetedClusters(ctx context.Context, gateway *gatewayapiv1.Gateway) (sets.Set[string], error) {
 	rootMeta, _ := k8smeta.Accessor(gateway)
 	labels := rootMeta.GetLabels()
 	selectedPlacement := labels[OCMPlacementLabel]
 	targetClusters := sets.Set[string](sets.NewString())
 	if selectedPlacement == "" {
+		if selector, ok := rootMeta.GetAnnotations()[ClusterLabelSelectorAnnotation]; ok {
+			return op.getSelectedClusters(ctx, selector)
+		}
 		return targetClusters, nil
 	}
 
@@ -255,7 +455,7 @@ func (op *ocmPlacer) GetClusters(ctx context.Context, gateway *gatewayapiv1.Gate
 	}
 	if len(pdList.Items) == 0 {
 		placementNotFound := k8serrors.NewNotFound(schema.GroupResource{Resource: "PlacementDecision"}, selectedPlacement)
-		return targetClusters, fmt.Errorf("no PlacementDecisions found for placement %s via label selector: %s error: %w", selectedPlacement, labelSelector, placementNotFound)
+		return targetClusters, fmt.Errorf("%w for placement %s via label selector: %s error: %w", ErrPlacementDecisionMissing, selectedPlacement, labelSelector, placementNotFound)
 	}
 	for _, pd := range pdList.Items {
 		for _, d := range pd.Status.Decisions {
@@ -265,34 +465,114 @@ func (op *ocmPlacer) GetClusters(ctx context.Context, gateway *gatewayapiv1.Gate
 	return targetClusters, nil
 }
 
+// getSelectedClusters returns the ManagedClusters matching the label selector
+func (op *ocmPlacer) getSelectedClusters(ctx context.Context, selector string) (sets.Set[string], error) {
+	targetClusters := sets.Set[string](sets.NewString())
+	labelSelector, err := ParseClusterSelector(selector)
+	if err != nil {
+		return targetClusters, err
+	}
+	clusters := &clusterv1.ManagedClusterList{}
+	if err := op.c.List(ctx, clusters, client.MatchingLabelsSelector{Selector: labelSelector}); err != nil {
+		return targetClusters, err
+	}
+	for _, cluster := range clusters.Items {
+		if cluster.DeletionTimestamp != nil {
+			continue
+		}
+		targetClusters.Insert(cluster.Name)
+	}
+	return targetClusters, nil
+}
+
+// ParseClusterSelector parses the value of the ClusterLabelSelectorAnnotation.
+// An empty selector is rejected rather than selecting every cluster
+func ParseClusterSelector(selector string) (labels.Selector, error) {
+	if strings.TrimSpace(selector) == "" {
+		return nil, fmt.Errorf("%s annotation must not be empty", ClusterLabelSelectorAnnotation)
+	}
+	labelSelector, err := labels.Parse(selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s annotation %q: %w", ClusterLabelSelectorAnnotation, selector, err)
+	}
+	return labelSelector, nil
+}
+
 func (op *ocmPlacer) createUpdateClusterManifests(ctx context.Context, manifestName string, upstream *gatewayapiv1.Gateway, downstream *gatewayapiv1.Gateway, cluster string, obj ...metav1.Object) error {
 	log := log.Log
+	work, err := op.clusterManifestWork(ctx, manifestName, upstream, downstream, cluster, obj...)
+	if err != nil {
+		return err
+	}
+	log.V(3).Info("placement: creating updating maniftests for ", "cluster", cluster)
+	return op.createUpdateManifest(ctx, cluster, work)
+
+}
+
+// clusterManifestWork builds the ManifestWork that places the gateway on the cluster, with the
+// GatewayClusterOverrides selecting the cluster applied to the downstream gateway
+func (op *ocmPlacer) clusterManifestWork(ctx context.Context, manifestName string, upstream *gatewayapiv1.Gateway, downstream *gatewayapiv1.Gateway, cluster string, obj ...metav1.Object) (workv1.ManifestWork, error) {
 	// set up gateway manifest
 	key, err := cache.MetaNamespaceKeyFunc(upstream)
 	if err != nil {
-		return err
+		return workv1.ManifestWork{}, err
+	}
+	clusterGateway, err := op.clusterGateway(ctx, upstream, downstream, cluster)
+	if err != nil {
+		return workv1.ManifestWork{}, err
 	}
+	obj = replaceObject(obj, downstream, clusterGateway)
 	work := workv1.ManifestWork{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      manifestName,
 			Namespace: cluster,
 			Labels:    map[string]string{"kuadrant.io": "managed", WorkManifestLabel: manifestName},
 			// this is crap, there has to be a better way to map to the parent object perhaps using cache
-			// there is also a resource https://github.com/open-cluster-management-io/api/blob/main/work/v1alpha1/types_manifestworkreplicaset.go that we may migrate to which would solve this
-			Annotations: map[string]string{"kuadrant.io/parent": key},
+			// the ManifestWorkReplicaSet placer avoids this by letting OCM create the per cluster works
+			Annotations: map[string]string{ParentAnnotation: key},
 		},
 	}
+	spec, err := op.workSpec(upstream, downstream, obj...)
+	if err != nil {
+		return work, err
+	}
+	work.Spec = spec
+	return work, nil
+}
+
+// clusterGateway returns the downstream gateway with the GatewayClusterOverrides of the gateway
+// that select the cluster, by the labels and ClusterClaims of its ManagedCluster, applied
+func (op *ocmPlacer) clusterGateway(ctx context.Context, upstream *gatewayapiv1.Gateway, downstream *gatewayapiv1.Gateway, cluster string) (*gatewayapiv1.Gateway, error) {
+	overrides, err := gatewayOverrides(ctx, op.c, upstream)
+	if err != nil || len(overrides) == 0 {
+		return downstream, err
+	}
+	clusterLabels, err := managedClusterLabels(ctx, op.c, cluster)
+	if err != nil {
+		return nil, err
+	}
+	return applyOverrides(downstream, overrides, clusterLabels)
+}
+
+// workSpec builds the ManifestWork spec that places the downstream gateway and its children
+func (op *ocmPlacer) workSpec(upstream *gatewayapiv1.Gateway, downstream *gatewayapiv1.Gateway, obj ...metav1.Object) (workv1.ManifestWorkSpec, error) {
+	log := log.Log
+	spec := workv1.ManifestWorkSpec{}
+	obj = op.secretDelivery.objects(obj...)
 	objManifests, err := op.manifest(obj...)
 	if err != nil {
-		return err
+		return spec, err
 	}
 	log.V(3).Info("placement:", "manifests prepared", len(objManifests))
 
-	work.Spec.Workload = workv1.ManifestsTemplate{
+	spec.Workload = workv1.ManifestsTemplate{
 		Manifests: objManifests,
 	}
+	// the namespaces may be shared with other workloads in the spoke so are
+	// never removed when the gateway leaves the cluster
+	spec.DeleteOption = namespaceOrphaningRules(manifestNamespaces(obj...)...)
 
-	work.Spec.ManifestConfigs = []workv1.ManifestConfigOption{
+	spec.ManifestConfigs = []workv1.ManifestConfigOption{
 		{
 			ResourceIdentifier: workv1.ResourceIdentifier{
 				Group:     "gateway.networking.k8s.io",
@@ -303,7 +583,7 @@ func (op *ocmPlacer) createUpdateClusterManifests(ctx context.Context, manifestN
 		},
 	}
 	// using 0 index as there is only one config here
-	work.Spec.ManifestConfigs[0].FeedbackRules = []workv1.FeedbackRule{
+	spec.ManifestConfigs[0].FeedbackRules = []workv1.FeedbackRule{
 		{Type: workv1.JSONPathsType},
 	}
 
@@ -317,46 +597,22 @@ func (op *ocmPlacer) createUpdateClusterManifests(ctx context.Context, manifestN
 		jsonPaths = append(jsonPaths, workv1.JsonPath{
 			Name: fmt.Sprintf("listener%sAttachedRoutes", l.Name),
 			Path: fmt.Sprintf(".status.listeners[?(@.name==\"%s\")].attachedRoutes", l.Name),
+		}, workv1.JsonPath{
+			Name: fmt.Sprintf("listener%sSupportedKinds", l.Name),
+			Path: fmt.Sprintf(".status.listeners[?(@.name==\"%s\")].supportedKinds", l.Name),
+		}, workv1.JsonPath{
+			Name: fmt.Sprintf("listener%sConditions", l.Name),
+			Path: fmt.Sprintf(".status.listeners[?(@.name==\"%s\")].conditions", l.Name),
 		})
 	}
 
-	work.Spec.ManifestConfigs[0].FeedbackRules[0].JsonPaths = jsonPaths
-	log.V(3).Info("feedback rules set ", "feedback ", work.Spec.ManifestConfigs[0].FeedbackRules)
-	log.V(3).Info("placement: creating updating maniftests for ", "cluster", cluster)
-	return op.createUpdateManifest(ctx, cluster, work)
-
-}
-
-func (op *ocmPlacer) manifest(obj ...metav1.Object) ([]workv1.Manifest, error) {
-	//TODO need to create an empty meta data to avoid problems with UID and resourceid
-	manifests := []workv1.Manifest{}
-	for _, o := range obj {
-		meta, _ := k8smeta.Accessor(o)
-		jsonData, err := json.Marshal(meta)
-		if err != nil {
-			return nil, err
-		}
-
-		manifests = append(manifests, workv1.Manifest{RawExtension: runtime.RawExtension{Raw: jsonData}})
-		//setup the ns TODO this is gross look for better options
-		ns := v1.Namespace{
-			TypeMeta: metav1.TypeMeta{
-				Kind:       "Namespace",
-				APIVersion: "v1",
-			},
-			ObjectMeta: metav1.ObjectMeta{
-				Name: meta.GetNamespace(),
-			},
-		}
-		jsonData, err = json.Marshal(ns)
-		if err != nil {
-			return nil, err
-		}
-		manifests = append(manifests, workv1.Manifest{RawExtension: runtime.RawExtension{Raw: jsonData}})
-	}
-	return manifests, nil
+	spec.ManifestConfigs[0].FeedbackRules[0].JsonPaths = jsonPaths
+	log.V(3).Info("feedback rules set ", "feedback ", spec.ManifestConfigs[0].FeedbackRules)
+	return spec, nil
 }
 
+// defaultRBAC ensures the work agent on the spoke is allowed to manage the kinds of objects placed
+// with the gateways
 func (op *ocmPlacer) defaultRBAC(ctx context.Context, clusterName string) error {
 	var m = []workv1.Manifest{}
 	cr := rbac.ClusterRole{
@@ -367,13 +623,7 @@ func (op *ocmPlacer) defaultRBAC(ctx context.Context, clusterName string) error
 		ObjectMeta: metav1.ObjectMeta{
 			Name: rbacName,
 		},
-		Rules: []rbac.PolicyRule{
-			{
-				Verbs:     []string{"*"},
-				APIGroups: []string{"gateway.networking.k8s.io"},
-				Resources: []string{"gateways"},
-			},
-		},
+		Rules: op.rbacRules(),
 	}
 
 	clusterRoleJSON, err := json.Marshal(cr)
@@ -387,20 +637,14 @@ func (op *ocmPlacer) defaultRBAC(ctx context.Context, clusterName string) error
 			APIVersion: "rbac.authorization.k8s.io/v1",
 		},
 		ObjectMeta: metav1.ObjectMeta{
-			Name: "open-cluster-management:klusterlet-work:gateway",
+			Name: rbacName,
 		},
 		RoleRef: rbac.RoleRef{
 			APIGroup: "rbac.authorization.k8s.io",
 			Kind:     "ClusterRole",
-			Name:     "open-cluster-management:klusterlet-work:gateway",
-		},
-		Subjects: []rbac.Subject{
-			{
-				Kind:      "ServiceAccount",
-				Name:      "klusterlet-work-sa",
-				Namespace: "open-cluster-management-agent",
-			},
+			Name:     rbacName,
 		},
+		Subjects: []rbac.Subject{op.workAgentSubject},
 	}
 
 	clusterRoleBindingJSON, err := json.Marshal(crb)
@@ -414,7 +658,7 @@ func (op *ocmPlacer) defaultRBAC(ctx context.Context, clusterName string) error
 
 	work := workv1.ManifestWork{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "gateway-rbac",
+			Name:      rbacManifest,
 			Namespace: clusterName,
 		},
 		Spec: workv1.ManifestWorkSpec{
@@ -424,6 +668,90 @@ func (op *ocmPlacer) defaultRBAC(ctx context.Context, clusterName string) error
 	return op.createUpdateManifest(ctx, clusterName, work)
 }
 
+// rbacRules returns the rules for the kinds of objects placed with the gateways: their namespaces,
+// the gateways and their TLS secrets, as delivered by the secret delivery
+func (op *ocmPlacer) rbacRules() []rbac.PolicyRule {
+	manage := []string{"get", "list", "watch", "create", "update", "patch", "delete"}
+	rules := []rbac.PolicyRule{
+		{
+			// the namespaces are orphaned when the gateways leave the cluster, so are never deleted
+			Verbs:     []string{"get", "list", "watch", "create", "update", "patch"},
+			APIGroups: []string{""},
+			Resources: []string{"namespaces"},
+		},
+		{
+			Verbs:     manage,
+			APIGroups: []string{gatewayapiv1.GroupName},
+			Resources: []string{"gateways"},
+		},
+	}
+	switch op.secretDelivery.Mode {
+	case "", SecretDeliveryInline:
+		rules = append(rules, rbac.PolicyRule{
+			Verbs:     manage,
+			APIGroups: []string{""},
+			Resources: []string{"secrets"},
+		})
+	case SecretDeliveryExternalSecret:
+		rules = append(rules, rbac.PolicyRule{
+			Verbs:     manage,
+			APIGroups: []string{externalSecretGroup},
+			Resources: []string{"externalsecrets"},
+		})
+	}
+	return rules
+}
+
+// removeUnusedRBAC removes the RBAC of the work agent from the clusters no gateway is placed on. It is kept
+// while the works of any gateway remain on a cluster, including those being deleted, as the work agent needs
+// it to remove their objects. The clusters in keep are skipped
+func (op *ocmPlacer) removeUnusedRBAC(ctx context.Context, keep sets.Set[string]) error {
+	works := &workv1.ManifestWorkList{}
+	if err := op.c.List(ctx, works); err != nil {
+		return err
+	}
+	rbacClusters, gatewayClusters := sets.New[string](), sets.New[string]()
+	for i := range works.Items {
+		work := &works.Items[i]
+		if work.Name == rbacManifest {
+			rbacClusters.Insert(work.Namespace)
+			continue
+		}
+		// the works of the gateways are placed per cluster with a parent, or by a replica set
+		_, placed := work.Annotations[ParentAnnotation]
+		_, replicaSet := ReplicaSetForWork(work)
+		if placed || replicaSet {
+			gatewayClusters.Insert(work.Namespace)
+		}
+	}
+	for _, cluster := range sets.List(rbacClusters.Difference(gatewayClusters).Difference(keep)) {
+		log.Log.V(3).Info("placement: removing work agent rbac from cluster without gateways", "cluster", cluster)
+		rbacWork := &workv1.ManifestWork{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: cluster,
+				Name:      rbacManifest,
+			},
+		}
+		if err := op.c.Delete(ctx, rbacWork); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// workClusters returns the clusters with a ManifestWork placing the gateway, including those being deleted
+func (op *ocmPlacer) workClusters(ctx context.Context, gateway *gatewayapiv1.Gateway) (sets.Set[string], error) {
+	works := &workv1.ManifestWorkList{}
+	clusters := sets.New[string]()
+	if err := op.c.List(ctx, works, client.MatchingLabels{WorkManifestLabel: WorkName(gateway)}); err != nil {
+		return clusters, err
+	}
+	for _, work := range works.Items {
+		clusters.Insert(work.Namespace)
+	}
+	return clusters, nil
+}
+
 func (op *ocmPlacer) createUpdateManifest(ctx context.Context, cluster string, m workv1.ManifestWork) error {
 	mw := &workv1.ManifestWork{
 		ObjectMeta: metav1.ObjectMeta{
@@ -439,10 +767,24 @@ func (op *ocmPlacer) createUpdateManifest(ctx context.Context, cluster string, m
 			}
 			return nil
 		}
+		return err
+	}
+
+	// objects in namespaces the work is moving away from are kept until the work is applied in the new ones
+	applied := manifestsApplied(m.Spec.Workload.Manifests, mw.Status.ResourceStatus)
+	m.Spec.Workload.Manifests = retainMigratingManifests(mw.Spec.Workload.Manifests, m.Spec.Workload.Manifests, applied)
+
+	if m.Spec.DeleteOption != nil && m.Spec.DeleteOption.SelectivelyOrphan != nil {
+		namespaces := append(orphanedNamespaces(&m), sets.List(namespaceManifests(m.Spec.Workload.Manifests))...)
+		m.Spec.DeleteOption = namespaceOrphaningRules(append(namespaces, removedNamespaces(mw, &m)...)...)
 	}
 
-	if !equality.Semantic.DeepEqual(mw.Spec, m.Spec) {
+	if !ManifestWorkSpecEqual(mw.Spec, m.Spec) {
 		log.Log.V(3).Info("placement: manifest found updating it ")
+		pruned := ManifestIdentifiers(mw.Spec.Workload.Manifests).Difference(ManifestIdentifiers(m.Spec.Workload.Manifests))
+		if pruned.Len() > 0 {
+			log.Log.V(3).Info("placement: pruning objects removed from manifest", "cluster", cluster, "objects", sets.List(pruned))
+		}
 		mw.Spec = m.Spec
 		if err := op.c.Update(ctx, mw, &client.UpdateOptions{}); err != nil {
 			log.Log.V(3).Info("placement:  updating manifest ", "error", err)
@@ -0,0 +1,367 @@
+package placement
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	workv1 "open-cluster-management.io/api/work/v1"
+	workv1alpha1 "open-cluster-management.io/api/work/v1alpha1"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	gatewayapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+const (
+	// ReplicaSetWorkLabel is set by OCM on each ManifestWork created from a
+	// ManifestWorkReplicaSet, with a value of <namespace>.<name>
+	ReplicaSetWorkLabel = "work.open-cluster-management.io/manifestworkreplicaset"
+)
+
+// ocmReplicaSetPlacer places gateways using a single ManifestWorkReplicaSet
+// per gateway that references the gateway's Placement. OCM creates and
+// removes the ManifestWork in each cluster chosen by the PlacementDecision,
+// so the hub does not write a ManifestWork per cluster on every reconcile.
+//
+// As OCM removes the ManifestWork as soon as a cluster leaves the decision,
+// the grace period applied by the ocmPlacer is not applied here.
+type ocmReplicaSetPlacer struct {
+	*ocmPlacer
+}
+
+func NewOCMReplicaSetPlacer(c client.Client, opts ...OCMPlacerOption) *ocmReplicaSetPlacer {
+	return &ocmReplicaSetPlacer{
+		ocmPlacer: NewOCMPlacer(c, opts...),
+	}
+}
+
+// ReplicaSetName returns the name of the ManifestWorkReplicaSet placing the gateway. It differs from
+// the name of the ManifestWork placed per cluster, as OCM names the works it creates after the replica set
+func ReplicaSetName(gateway runtime.Object) string {
+	return WorkName(gateway) + "-replicaset"
+}
+
+// Place ensures the ManifestWorkReplicaSet for the gateway exists and is up to date.
+// It returns the clusters targeted by the gateway placement
+func (rp *ocmReplicaSetPlacer) Place(ctx context.Context, upStreamGateway *gatewayapiv1.Gateway, downStreamGateway *gatewayapiv1.Gateway, children ...metav1.Object) (sets.Set[string], error) {
+	log := log.Log
+	log.V(3).Info("placement: placing with replica set ", "gateway", upStreamGateway.Name, "gateway ns", upStreamGateway.Namespace)
+	emptySet := sets.New[string]()
+	mwrs := &workv1alpha1.ManifestWorkReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ReplicaSetName(upStreamGateway),
+			Namespace: upStreamGateway.Namespace,
+		},
+	}
+
+	// OCM removes the works from every cluster when the replica set is deleted. Any works placed per
+	// cluster, and the RBAC of the clusters left without gateways, are removed by the ocmPlacer
+	if upStreamGateway.GetDeletionTimestamp() != nil {
+		if err := rp.c.Delete(ctx, mwrs); client.IgnoreNotFound(err) != nil {
+			return emptySet, err
+		}
+		placed, err := rp.ocmPlacer.Place(ctx, upStreamGateway, downStreamGateway, children...)
+		if err != nil {
+			return placed, err
+		}
+		// the RBAC is kept until the works of the replica set are gone
+		works, err := rp.replicaSetWorks(ctx, upStreamGateway)
+		if err != nil {
+			return placed, err
+		}
+		clusters := sets.New[string]()
+		for _, work := range works {
+			clusters.Insert(work.Namespace)
+		}
+		if clusters.Len() > 0 {
+			return placed, fmt.Errorf("%w from clusters %v", ErrWorkDeleting, sets.List(clusters))
+		}
+		return placed, nil
+	}
+
+	// a ManifestWorkReplicaSet places the same work on every cluster in the decision of a Placement at once,
+	// so gateways selecting clusters by label, with placement constraints, with a progressive rollout or
+	// with overrides per cluster are placed with a ManifestWork per cluster
+	selectedPlacement := upStreamGateway.GetLabels()[OCMPlacementLabel]
+	_, rollout := upStreamGateway.GetAnnotations()[RolloutBatchAnnotation]
+	overrides, err := gatewayOverrides(ctx, rp.c, upStreamGateway)
+	if err != nil {
+		return emptySet, err
+	}
+	if selectedPlacement == "" || HasConstraints(upStreamGateway) || rollout || len(overrides) > 0 {
+		placed, err := rp.ocmPlacer.Place(ctx, upStreamGateway, downStreamGateway, children...)
+		if removeErr := rp.removeReplicaSet(ctx, upStreamGateway, mwrs); removeErr != nil {
+			return placed, removeErr
+		}
+		return placed, err
+	}
+
+	targets, err := rp.GetClusters(ctx, upStreamGateway)
+	if err != nil {
+		return emptySet, err
+	}
+
+	// the work agent needs the RBAC before applying the work of the replica set,
+	// so it is only ensured on the clusters where the work is not applied yet
+	works, err := rp.replicaSetWorks(ctx, upStreamGateway)
+	if err != nil {
+		return emptySet, err
+	}
+	applied := appliedWorkClusters(works)
+	for _, cluster := range sets.List(targets.Difference(applied)) {
+		if err := rp.defaultRBAC(ctx, cluster); err != nil {
+			return emptySet, err
+		}
+	}
+
+	key, err := cache.MetaNamespaceKeyFunc(upStreamGateway)
+	if err != nil {
+		return emptySet, err
+	}
+	objects := []metav1.Object{downStreamGateway}
+	objects = append(objects, children...)
+	spec, err := rp.workSpec(upStreamGateway, downStreamGateway, objects...)
+	if err != nil {
+		return emptySet, err
+	}
+	desired := &workv1alpha1.ManifestWorkReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        mwrs.Name,
+			Namespace:   mwrs.Namespace,
+			Labels:      map[string]string{"kuadrant.io": "managed", WorkManifestLabel: WorkName(upStreamGateway)},
+			Annotations: map[string]string{ParentAnnotation: key},
+		},
+		Spec: workv1alpha1.ManifestWorkReplicaSetSpec{
+			ManifestWorkTemplate: spec,
+			PlacementRefs: []workv1alpha1.LocalPlacementReference{
+				{Name: selectedPlacement},
+			},
+		},
+	}
+
+	if err := rp.createUpdateReplicaSet(ctx, desired, works); err != nil {
+		return emptySet, err
+	}
+	if err := rp.removeClusterWorks(ctx, upStreamGateway, targets, applied); err != nil {
+		return emptySet, err
+	}
+	// the targeted clusters keep the RBAC, as the works of the replica set may not be listed yet
+	if err := rp.removeUnusedRBAC(ctx, targets); err != nil {
+		return emptySet, err
+	}
+	return targets, nil
+}
+
+// removeReplicaSet deletes the ManifestWorkReplicaSet of a gateway now placed with a ManifestWork per cluster. As
+// deleting it removes the gateway from its clusters at once, it is kept until the ManifestWork placed per cluster
+// is applied on each of its clusters that is still targeted
+func (rp *ocmReplicaSetPlacer) removeReplicaSet(ctx context.Context, gateway *gatewayapiv1.Gateway, mwrs *workv1alpha1.ManifestWorkReplicaSet) error {
+	works, err := rp.replicaSetWorks(ctx, gateway)
+	if err != nil {
+		return err
+	}
+	targets, err := rp.ocmPlacer.GetClusters(ctx, gateway)
+	if err != nil && !IsUnmetConstraint(err) {
+		return err
+	}
+	placed, err := rp.ocmPlacer.GetPlacedClusters(ctx, gateway)
+	if err != nil {
+		return err
+	}
+	clusters := sets.New[string]()
+	for _, work := range works {
+		clusters.Insert(work.Namespace)
+	}
+	if pending := clusters.Intersection(targets).Difference(placed); pending.Len() > 0 {
+		log.Log.V(3).Info("placement: keeping manifest work replica set until the gateway is placed per cluster", "name", mwrs.Name, "pending", sets.List(pending))
+		return nil
+	}
+	// the RBAC of the clusters no longer targeted is removed by a later placement, once the works are gone
+	return client.IgnoreNotFound(rp.c.Delete(ctx, mwrs))
+}
+
+// removeClusterWorks deletes the ManifestWorks placing the gateway per cluster once the work of the replica
+// set is applied on their cluster, or their cluster is no longer targeted
+func (rp *ocmReplicaSetPlacer) removeClusterWorks(ctx context.Context, gateway *gatewayapiv1.Gateway, targets, applied sets.Set[string]) error {
+	works := &workv1.ManifestWorkList{}
+	if err := rp.c.List(ctx, works, client.MatchingLabels{WorkManifestLabel: WorkName(gateway)}); err != nil {
+		return err
+	}
+	for i := range works.Items {
+		work := &works.Items[i]
+		if _, ok := ReplicaSetForWork(work); ok || (targets.Has(work.Namespace) && !applied.Has(work.Namespace)) {
+			continue
+		}
+		log.Log.V(3).Info("placement: removing manifest work replaced by the replica set", "cluster", work.Namespace, "name", work.Name)
+		if err := rp.c.Delete(ctx, work); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetPlacedClusters returns the clusters where the ManifestWork created from the
+// gateway's ManifestWorkReplicaSet, or placed per cluster, has been applied
+func (rp *ocmReplicaSetPlacer) GetPlacedClusters(ctx context.Context, gateway *gatewayapiv1.Gateway) (sets.Set[string], error) {
+	placed, err := rp.ocmPlacer.GetPlacedClusters(ctx, gateway)
+	if err != nil {
+		return placed, err
+	}
+	works, err := rp.replicaSetWorks(ctx, gateway)
+	if err != nil {
+		return placed, err
+	}
+	return placed.Union(appliedWorkClusters(works)), nil
+}
+
+// GetAddresses returns the addresses of the downstream gateway reported by the work placing it on the cluster
+func (rp *ocmReplicaSetPlacer) GetAddresses(ctx context.Context, gateway *gatewayapiv1.Gateway, downstream string) ([]gatewayapiv1.GatewayAddress, error) {
+	mw, err := rp.gatewayWork(ctx, gateway, downstream)
+	if err != nil {
+		return []gatewayapiv1.GatewayAddress{}, err
+	}
+	return workAddresses(gateway, mw)
+}
+
+// ListenerTotalAttachedRoutes returns the routes attached to the downstream gateway listener reported by the
+// work placing it on the cluster
+func (rp *ocmReplicaSetPlacer) ListenerTotalAttachedRoutes(ctx context.Context, gateway *gatewayapiv1.Gateway, listenerName string, downstream string) (int, error) {
+	mw, err := rp.gatewayWork(ctx, gateway, downstream)
+	if err != nil {
+		return 0, err
+	}
+	return workListenerTotalAttachedRoutes(gateway, mw, listenerName)
+}
+
+// GetListenerStatus returns the status of the downstream gateway listener reported by the work placing it on the cluster
+func (rp *ocmReplicaSetPlacer) GetListenerStatus(ctx context.Context, gateway *gatewayapiv1.Gateway, listenerName string, downstream string) (gatewayapiv1.ListenerStatus, error) {
+	mw, err := rp.gatewayWork(ctx, gateway, downstream)
+	if err != nil {
+		return emptyListenerStatus(listenerName), err
+	}
+	return workListenerStatus(gateway, mw, listenerName)
+}
+
+// gatewayWork returns the work placing the gateway on the cluster. The work created from the replica set
+// is preferred once applied, otherwise the work placed per cluster is used while the placement is handed over
+func (rp *ocmReplicaSetPlacer) gatewayWork(ctx context.Context, gateway *gatewayapiv1.Gateway, downstream string) (*workv1.ManifestWork, error) {
+	replicaSetWork := &workv1.ManifestWork{}
+	key := client.ObjectKey{Namespace: downstream, Name: ReplicaSetName(gateway)}
+	if err := rp.c.Get(ctx, key, replicaSetWork); err != nil {
+		if !k8serrors.IsNotFound(err) {
+			return nil, err
+		}
+		return rp.ocmPlacer.gatewayWork(ctx, gateway, downstream)
+	}
+	if meta.IsStatusConditionTrue(replicaSetWork.Status.Conditions, string(workv1.ManifestApplied)) {
+		return replicaSetWork, nil
+	}
+	mw, err := rp.ocmPlacer.gatewayWork(ctx, gateway, downstream)
+	if k8serrors.IsNotFound(err) {
+		return replicaSetWork, nil
+	}
+	return mw, err
+}
+
+// GetFailedClusters returns the clusters where the gateway failed to apply, from either the works of the
+// replica set or those placed directly
+func (rp *ocmReplicaSetPlacer) GetFailedClusters(ctx context.Context, gateway *gatewayapiv1.Gateway) (sets.Set[string], error) {
+	failed, err := rp.ocmPlacer.GetFailedClusters(ctx, gateway)
+	if err != nil {
+		return failed, err
+	}
+	works, err := rp.replicaSetWorks(ctx, gateway)
+	if err != nil {
+		return failed, err
+	}
+	return failed.Union(failedWorkClusters(works)), nil
+}
+
+// replicaSetWorks returns the ManifestWorks OCM created from the gateway's ManifestWorkReplicaSet
+func (rp *ocmReplicaSetPlacer) replicaSetWorks(ctx context.Context, gateway *gatewayapiv1.Gateway) ([]workv1.ManifestWork, error) {
+	works := &workv1.ManifestWorkList{}
+	listOptions := client.MatchingLabels{
+		ReplicaSetWorkLabel: fmt.Sprintf("%s.%s", gateway.Namespace, ReplicaSetName(gateway)),
+	}
+	if err := rp.c.List(ctx, works, listOptions); err != nil {
+		return nil, err
+	}
+	return works.Items, nil
+}
+
+// appliedWorkClusters returns the clusters of the works that are applied and not being deleted
+func appliedWorkClusters(works []workv1.ManifestWork) sets.Set[string] {
+	clusters := sets.New[string]()
+	for _, work := range works {
+		deleting := work.DeletionTimestamp != nil
+		applied := meta.IsStatusConditionTrue(work.Status.Conditions, string(workv1.ManifestApplied))
+		if !deleting && applied {
+			clusters.Insert(work.Namespace)
+		}
+	}
+	return clusters
+}
+
+func (rp *ocmReplicaSetPlacer) createUpdateReplicaSet(ctx context.Context, desired *workv1alpha1.ManifestWorkReplicaSet, works []workv1.ManifestWork) error {
+	existing := &workv1alpha1.ManifestWorkReplicaSet{}
+	if err := rp.c.Get(ctx, client.ObjectKeyFromObject(desired), existing); err != nil {
+		if k8serrors.IsNotFound(err) {
+			log.Log.V(3).Info("placement: manifest work replica set not found creating it ", "name", desired.Name)
+			return rp.c.Create(ctx, desired)
+		}
+		return err
+	}
+
+	// objects in namespaces the works are moving away from are kept until every work is applied in the new ones
+	statuses := []workv1.ManifestResourceStatus{}
+	for _, work := range works {
+		statuses = append(statuses, work.Status.ResourceStatus)
+	}
+	template := &desired.Spec.ManifestWorkTemplate
+	applied := manifestsApplied(template.Workload.Manifests, statuses...)
+	template.Workload.Manifests = retainMigratingManifests(existing.Spec.ManifestWorkTemplate.Workload.Manifests, template.Workload.Manifests, applied)
+	if template.DeleteOption != nil && template.DeleteOption.SelectivelyOrphan != nil {
+		template.DeleteOption = namespaceOrphaningRules(append(orphanedNamespaces(&workv1.ManifestWork{Spec: *template}), sets.List(namespaceManifests(template.Workload.Manifests))...)...)
+	}
+
+	if ManifestWorkSpecEqual(existing.Spec.ManifestWorkTemplate, desired.Spec.ManifestWorkTemplate) &&
+		equalPlacementRefs(existing.Spec.PlacementRefs, desired.Spec.PlacementRefs) {
+		return nil
+	}
+	log.Log.V(3).Info("placement: manifest work replica set found updating it ", "name", desired.Name)
+	existing.Spec = desired.Spec
+	existing.Labels = desired.Labels
+	existing.Annotations = desired.Annotations
+	return rp.c.Update(ctx, existing)
+}
+
+func equalPlacementRefs(a, b []workv1alpha1.LocalPlacementReference) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Name != b[i].Name {
+			return false
+		}
+	}
+	return true
+}
+
+// ReplicaSetForWork returns the key of the ManifestWorkReplicaSet that created the
+// given ManifestWork, if any
+func ReplicaSetForWork(work metav1.Object) (client.ObjectKey, bool) {
+	value := work.GetLabels()[ReplicaSetWorkLabel]
+	// namespaces can not contain a '.' so the first one separates the name
+	namespace, name, found := strings.Cut(value, ".")
+	if !found || namespace == "" || name == "" {
+		return client.ObjectKey{}, false
+	}
+	return client.ObjectKey{Namespace: namespace, Name: name}, true
+}
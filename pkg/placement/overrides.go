@@ -0,0 +1,121 @@
+package placement
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	clusterv1 "open-cluster-management.io/api/cluster/v1"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
+)
+
+// gatewayOverrides returns the GatewayClusterOverrides of the gateway in the order they are
+// applied. No overrides are returned when the GatewayClusterOverride CRD is not installed
+func gatewayOverrides(ctx context.Context, c client.Client, gateway *gatewayapiv1.Gateway) ([]v1alpha1.GatewayClusterOverride, error) {
+	list := &v1alpha1.GatewayClusterOverrideList{}
+	if err := c.List(ctx, list, client.InNamespace(gateway.Namespace)); err != nil {
+		if meta.IsNoMatchError(err) || runtime.IsNotRegisteredError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	overrides := []v1alpha1.GatewayClusterOverride{}
+	for _, override := range list.Items {
+		if override.Spec.GatewayName == gateway.Name {
+			overrides = append(overrides, override)
+		}
+	}
+	sort.Slice(overrides, func(i, j int) bool {
+		return overrides[i].Name < overrides[j].Name
+	})
+	return overrides, nil
+}
+
+// managedClusterLabels returns the labels and ClusterClaims of the ManagedCluster, which the
+// overrides select clusters by. ClusterClaims take precedence over labels with the same name
+func managedClusterLabels(ctx context.Context, c client.Client, cluster string) (labels.Set, error) {
+	managedCluster := &clusterv1.ManagedCluster{}
+	if err := c.Get(ctx, client.ObjectKey{Name: cluster}, managedCluster); err != nil {
+		return nil, client.IgnoreNotFound(err)
+	}
+	set := labels.Set{}
+	for key, value := range managedCluster.Labels {
+		set[key] = value
+	}
+	for _, claim := range managedCluster.Status.ClusterClaims {
+		set[claim.Name] = claim.Value
+	}
+	return set, nil
+}
+
+// applyOverrides returns a copy of the downstream gateway with the overrides selecting the
+// cluster applied
+func applyOverrides(downstream *gatewayapiv1.Gateway, overrides []v1alpha1.GatewayClusterOverride, clusterLabels labels.Set) (*gatewayapiv1.Gateway, error) {
+	gateway := downstream.DeepCopy()
+	for _, override := range overrides {
+		selector := labels.Everything()
+		if override.Spec.ClusterSelector != nil {
+			var err error
+			if selector, err = metav1.LabelSelectorAsSelector(override.Spec.ClusterSelector); err != nil {
+				return nil, fmt.Errorf("invalid cluster selector in GatewayClusterOverride %s/%s: %w", override.Namespace, override.Name, err)
+			}
+		}
+		if !selector.Matches(clusterLabels) {
+			continue
+		}
+
+		if len(override.Spec.Addresses) > 0 {
+			gateway.Spec.Addresses = override.Spec.Addresses
+		}
+		if infrastructure := override.Spec.Infrastructure; infrastructure != nil {
+			if gateway.Spec.Infrastructure == nil {
+				gateway.Spec.Infrastructure = &gatewayapiv1.GatewayInfrastructure{}
+			}
+			gateway.Spec.Infrastructure.Labels = mergeInfrastructure(gateway.Spec.Infrastructure.Labels, infrastructure.Labels)
+			gateway.Spec.Infrastructure.Annotations = mergeInfrastructure(gateway.Spec.Infrastructure.Annotations, infrastructure.Annotations)
+		}
+		for _, listenerOverride := range override.Spec.Listeners {
+			for i := range gateway.Spec.Listeners {
+				listener := &gateway.Spec.Listeners[i]
+				if listener.Name == listenerOverride.Name && listenerOverride.Hostname != nil {
+					hostname := *listenerOverride.Hostname
+					listener.Hostname = &hostname
+				}
+			}
+		}
+	}
+	return gateway, nil
+}
+
+func mergeInfrastructure(existing, override map[gatewayapiv1.AnnotationKey]gatewayapiv1.AnnotationValue) map[gatewayapiv1.AnnotationKey]gatewayapiv1.AnnotationValue {
+	if len(override) == 0 {
+		return existing
+	}
+	if existing == nil {
+		existing = map[gatewayapiv1.AnnotationKey]gatewayapiv1.AnnotationValue{}
+	}
+	for key, value := range override {
+		existing[key] = value
+	}
+	return existing
+}
+
+// replaceObject returns a copy of the objects with the object replaced by its replacement
+func replaceObject(obj []metav1.Object, object, replacement metav1.Object) []metav1.Object {
+	objects := make([]metav1.Object, 0, len(obj))
+	for _, o := range obj {
+		if o == object {
+			o = replacement
+		}
+		objects = append(objects, o)
+	}
+	return objects
+}
@@ -5,21 +5,29 @@ package placement_test
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
 	"testing"
 
+	clusterv1 "open-cluster-management.io/api/cluster/v1"
 	pd "open-cluster-management.io/api/cluster/v1beta1"
 	workv1 "open-cluster-management.io/api/work/v1"
+	workv1alpha1 "open-cluster-management.io/api/work/v1alpha1"
 
 	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/kubernetes/scheme"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	gatewayapiv1 "sigs.k8s.io/gateway-api/apis/v1"
 
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/apis/v1alpha1"
 	"github.com/Kuadrant/multicluster-gateway-controller/pkg/placement"
 )
 
@@ -30,6 +38,15 @@ func init() {
 	if err := pd.AddToScheme(scheme.Scheme); err != nil {
 		panic(err)
 	}
+	if err := workv1alpha1.AddToScheme(scheme.Scheme); err != nil {
+		panic(err)
+	}
+	if err := clusterv1.AddToScheme(scheme.Scheme); err != nil {
+		panic(err)
+	}
+	if err := v1alpha1.AddToScheme(scheme.Scheme); err != nil {
+		panic(err)
+	}
 }
 
 func TestGetAddresses(t *testing.T) {
@@ -330,6 +347,99 @@ func TestListenerTotalAttachedRoutes(t *testing.T) {
 	}
 }
 
+func TestGetListenerStatus(t *testing.T) {
+	gateway := &gatewayapiv1.Gateway{
+		TypeMeta: v1.TypeMeta{
+			Kind:       "Gateway",
+			APIVersion: "gateway.networking.k8s.io/gatewayapiv1",
+		},
+		ObjectMeta: v1.ObjectMeta{
+			Name: "test",
+		},
+	}
+	attachedRoutes := int64(2)
+	supportedKinds := `[{"group":"gateway.networking.k8s.io","kind":"HTTPRoute"}]`
+	conditions := `[{"type":"ResolvedRefs","status":"False","reason":"InvalidCertificateRef","message":"bad certificate","lastTransitionTime":"2024-01-01T00:00:00Z"}]`
+	f := fake.NewClientBuilder().WithObjects(&workv1.ManifestWork{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      placement.WorkName(gateway),
+			Namespace: "test",
+		},
+		Status: workv1.ManifestWorkStatus{
+			ResourceStatus: workv1.ManifestResourceStatus{
+				Manifests: []workv1.ManifestCondition{
+					{
+						ResourceMeta: workv1.ManifestResourceMeta{
+							Group: "gateway.networking.k8s.io",
+							Name:  "test",
+						},
+						StatusFeedbacks: workv1.StatusFeedbackResult{
+							Values: []workv1.FeedbackValue{
+								{Name: "listenerapiAttachedRoutes", Value: workv1.FieldValue{Integer: &attachedRoutes}},
+								{Name: "listenerapiSupportedKinds", Value: workv1.FieldValue{JsonRaw: &supportedKinds}},
+								{Name: "listenerapiConditions", Value: workv1.FieldValue{JsonRaw: &conditions}},
+							},
+						},
+					},
+				},
+			},
+		},
+	}).Build()
+	p := placement.NewOCMPlacer(f)
+
+	status, err := p.GetListenerStatus(context.TODO(), gateway, "api", "test")
+	if err != nil {
+		t.Fatalf("did not expect an error but got one %s ", err)
+	}
+	if status.Name != "api" || status.AttachedRoutes != 2 {
+		t.Fatalf("expected listener api with 2 attached routes got %v", status)
+	}
+	if len(status.SupportedKinds) != 1 || status.SupportedKinds[0].Kind != "HTTPRoute" {
+		t.Fatalf("expected HTTPRoute supported kind got %v", status.SupportedKinds)
+	}
+	if len(status.Conditions) != 1 || status.Conditions[0].Reason != "InvalidCertificateRef" {
+		t.Fatalf("expected listener condition from the cluster got %v", status.Conditions)
+	}
+
+	if _, err := p.GetListenerStatus(context.TODO(), gateway, "other", "test"); err == nil {
+		t.Fatalf("expected an error for a listener without status")
+	}
+}
+
+func TestGetFailedClusters(t *testing.T) {
+	gateway := &gatewayapiv1.Gateway{
+		TypeMeta: v1.TypeMeta{
+			Kind:       "Gateway",
+			APIVersion: "gateway.networking.k8s.io/v1",
+		},
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "test",
+			Namespace: "test",
+		},
+	}
+	workFor := func(cluster string, applied v1.ConditionStatus) *workv1.ManifestWork {
+		return &workv1.ManifestWork{
+			ObjectMeta: v1.ObjectMeta{
+				Name:      placement.WorkName(gateway),
+				Namespace: cluster,
+				Labels:    map[string]string{placement.WorkManifestLabel: placement.WorkName(gateway)},
+			},
+			Status: workv1.ManifestWorkStatus{
+				Conditions: []v1.Condition{{Type: workv1.WorkApplied, Status: applied}},
+			},
+		}
+	}
+	f := fake.NewClientBuilder().WithObjects(workFor("c1", v1.ConditionTrue), workFor("c2", v1.ConditionFalse)).Build()
+
+	failed, err := placement.NewOCMPlacer(f).GetFailedClusters(context.TODO(), gateway)
+	if err != nil {
+		t.Fatalf("did not expect an error but got %s", err)
+	}
+	if !failed.Equal(sets.New("c2")) {
+		t.Fatalf("expected gateway to have failed on c2 got %v", sets.List(failed))
+	}
+}
+
 func TestGetPlacedClusters(t *testing.T) {
 	testCases := []struct {
 		Name               string
@@ -525,6 +635,9 @@ func TestGetClusters(t *testing.T) {
 				if !k8serrors.IsNotFound(err) {
 					t.Fatalf("expected a not found err %v", err)
 				}
+				if !errors.Is(err, placement.ErrPlacementDecisionMissing) {
+					t.Fatalf("expected a placement decision missing err %v", err)
+				}
 				if !got.Equal(expected) {
 					t.Fatalf("expected clusters %v but it was not present in %v", expected.UnsortedList(), got.UnsortedList())
 				}
@@ -548,6 +661,90 @@ func TestGetClusters(t *testing.T) {
 	}
 }
 
+func TestGetClustersLabelSelector(t *testing.T) {
+	cluster := func(name string, labels map[string]string) *clusterv1.ManagedCluster {
+		return &clusterv1.ManagedCluster{
+			ObjectMeta: v1.ObjectMeta{
+				Name:   name,
+				Labels: labels,
+			},
+		}
+	}
+	gateway := func(labels map[string]string, selector string) *gatewayapiv1.Gateway {
+		return &gatewayapiv1.Gateway{
+			ObjectMeta: v1.ObjectMeta{
+				Labels:      labels,
+				Annotations: map[string]string{placement.ClusterLabelSelectorAnnotation: selector},
+				Namespace:   "test",
+			},
+		}
+	}
+
+	testCases := []struct {
+		Name      string
+		Gateway   *gatewayapiv1.Gateway
+		Expected  sets.Set[string]
+		ExpectErr bool
+	}{
+		{
+			Name:     "test clusters matching the selector are returned",
+			Gateway:  gateway(nil, "region=eu"),
+			Expected: sets.New("c1", "c2"),
+		},
+		{
+			Name:     "test set based selector",
+			Gateway:  gateway(nil, "region in (us),tier!=canary"),
+			Expected: sets.New("c3"),
+		},
+		{
+			Name:     "test placement label takes precedence over the selector",
+			Gateway:  gateway(map[string]string{placement.OCMPlacementLabel: "test"}, "region=eu"),
+			Expected: sets.New("c4"),
+		},
+		{
+			Name:      "test empty selector is rejected",
+			Gateway:   gateway(nil, ""),
+			Expected:  sets.New[string](),
+			ExpectErr: true,
+		},
+		{
+			Name:      "test invalid selector is rejected",
+			Gateway:   gateway(nil, "region in eu"),
+			Expected:  sets.New[string](),
+			ExpectErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			c := fake.NewClientBuilder().WithObjects(
+				cluster("c1", map[string]string{"region": "eu"}),
+				cluster("c2", map[string]string{"region": "eu", "tier": "canary"}),
+				cluster("c3", map[string]string{"region": "us"}),
+				cluster("c5", map[string]string{"region": "us", "tier": "canary"}),
+				&pd.PlacementDecision{
+					ObjectMeta: v1.ObjectMeta{
+						Labels:    map[string]string{placement.OCMPlacementLabel: "test"},
+						Namespace: "test",
+						Name:      "test",
+					},
+					Status: pd.PlacementDecisionStatus{
+						Decisions: []pd.ClusterDecision{{ClusterName: "c4"}},
+					},
+				},
+			).Build()
+			p := placement.NewOCMPlacer(c)
+			got, err := p.GetClusters(context.TODO(), testCase.Gateway)
+			if testCase.ExpectErr != (err != nil) {
+				t.Fatalf("expected error %v but got %v", testCase.ExpectErr, err)
+			}
+			if !got.Equal(testCase.Expected) {
+				t.Fatalf("expected clusters %v but got %v", sets.List(testCase.Expected), sets.List(got))
+			}
+		})
+	}
+}
+
 func TestDeschedule(t *testing.T) {
 	var manifestWorkFunc = func(downstream, name string) *workv1.ManifestWork {
 
@@ -708,3 +905,1033 @@ func TestDeschedule(t *testing.T) {
 		})
 	}
 }
+
+func TestPlaceManifests(t *testing.T) {
+	upstream := &gatewayapiv1.Gateway{
+		ObjectMeta: v1.ObjectMeta{
+			Labels:    map[string]string{placement.OCMPlacementLabel: "test"},
+			Namespace: "test",
+			Name:      "test",
+		},
+		TypeMeta: v1.TypeMeta{
+			Kind:       "Gateway",
+			APIVersion: "gateway.networking.k8s.io/v1",
+		},
+	}
+	downstream := upstream.DeepCopy()
+	downstream.Namespace = "kuadrant-test"
+	secret := func(name string) *corev1.Secret {
+		return &corev1.Secret{
+			TypeMeta: v1.TypeMeta{
+				Kind:       "Secret",
+				APIVersion: "v1",
+			},
+			ObjectMeta: v1.ObjectMeta{
+				Name:      name,
+				Namespace: "kuadrant-test",
+			},
+		}
+	}
+	decision := &pd.PlacementDecision{
+		ObjectMeta: v1.ObjectMeta{
+			Labels:    map[string]string{placement.OCMPlacementLabel: "test"},
+			Namespace: "test",
+			Name:      "test",
+		},
+		Status: pd.PlacementDecisionStatus{
+			Decisions: []pd.ClusterDecision{{ClusterName: "c1"}},
+		},
+	}
+
+	c := fake.NewClientBuilder().WithObjects(decision).Build()
+	p := placement.NewOCMPlacer(c)
+	workKey := client.ObjectKey{Namespace: "c1", Name: placement.WorkName(upstream)}
+
+	// the same secret referenced by two listeners should only be placed once
+	if _, err := p.Place(context.TODO(), upstream, downstream, secret("b"), secret("a"), secret("b")); err != nil {
+		t.Fatalf("did not expect an error placing gateway but got %s", err)
+	}
+	work := &workv1.ManifestWork{}
+	if err := c.Get(context.TODO(), workKey, work); err != nil {
+		t.Fatalf("expected manifest work to exist %s", err)
+	}
+	ids := []string{}
+	for _, m := range work.Spec.Workload.Manifests {
+		obj := map[string]interface{}{}
+		if err := json.Unmarshal(m.Raw, &obj); err != nil {
+			t.Fatal(err)
+		}
+		metadata := obj["metadata"].(map[string]interface{})
+		ids = append(ids, obj["kind"].(string)+"/"+metadata["name"].(string))
+	}
+	expected := []string{"Namespace/kuadrant-test", "Gateway/test", "Secret/a", "Secret/b"}
+	if !reflect.DeepEqual(ids, expected) {
+		t.Fatalf("expected manifests %v got %v", expected, ids)
+	}
+	if work.Spec.DeleteOption == nil || work.Spec.DeleteOption.PropagationPolicy != workv1.DeletePropagationPolicyTypeSelectivelyOrphan {
+		t.Fatalf("expected namespaces to be selectively orphaned, got %v", work.Spec.DeleteOption)
+	}
+	rules := work.Spec.DeleteOption.SelectivelyOrphan.OrphaningRules
+	if len(rules) != 1 || rules[0].Resource != "namespaces" || rules[0].Name != "kuadrant-test" {
+		t.Fatalf("expected kuadrant-test namespace to be orphaned, got %v", rules)
+	}
+
+	// placing the same objects in a different order should not update the work
+	resourceVersion := work.ResourceVersion
+	if _, err := p.Place(context.TODO(), upstream, downstream, secret("a"), secret("b")); err != nil {
+		t.Fatalf("did not expect an error placing gateway but got %s", err)
+	}
+	if err := c.Get(context.TODO(), workKey, work); err != nil {
+		t.Fatal(err)
+	}
+	if work.ResourceVersion != resourceVersion {
+		t.Fatalf("expected manifest work not to be updated")
+	}
+
+	// removing a child prunes it from the work
+	if _, err := p.Place(context.TODO(), upstream, downstream, secret("a")); err != nil {
+		t.Fatalf("did not expect an error placing gateway but got %s", err)
+	}
+	if err := c.Get(context.TODO(), workKey, work); err != nil {
+		t.Fatal(err)
+	}
+	if len(work.Spec.Workload.Manifests) != 3 {
+		t.Fatalf("expected removed secret to be pruned from the work, got %d manifests", len(work.Spec.Workload.Manifests))
+	}
+}
+
+func TestPlaceSecretDelivery(t *testing.T) {
+	upstream := &gatewayapiv1.Gateway{
+		ObjectMeta: v1.ObjectMeta{
+			Labels:    map[string]string{placement.OCMPlacementLabel: "test"},
+			Namespace: "test",
+			Name:      "test",
+		},
+		TypeMeta: v1.TypeMeta{
+			Kind:       "Gateway",
+			APIVersion: "gateway.networking.k8s.io/v1",
+		},
+	}
+	downstream := upstream.DeepCopy()
+	downstream.Namespace = "kuadrant-test"
+	secret := &corev1.Secret{
+		TypeMeta: v1.TypeMeta{
+			Kind:       "Secret",
+			APIVersion: "v1",
+		},
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "tls",
+			Namespace: "kuadrant-test",
+		},
+		Type: corev1.SecretTypeTLS,
+		Data: map[string][]byte{corev1.TLSCertKey: []byte("cert"), corev1.TLSPrivateKeyKey: []byte("key")},
+	}
+	decision := &pd.PlacementDecision{
+		ObjectMeta: v1.ObjectMeta{
+			Labels:    map[string]string{placement.OCMPlacementLabel: "test"},
+			Namespace: "test",
+			Name:      "test",
+		},
+		Status: pd.PlacementDecisionStatus{
+			Decisions: []pd.ClusterDecision{{ClusterName: "c1"}},
+		},
+	}
+
+	cases := []struct {
+		name      string
+		delivery  placement.SecretDelivery
+		expected  []string
+		assertObj func(t *testing.T, obj map[string]interface{})
+	}{
+		{
+			name:     "inline secrets are embedded",
+			delivery: placement.SecretDelivery{Mode: placement.SecretDeliveryInline},
+			expected: []string{"Namespace/kuadrant-test", "Gateway/test", "Secret/tls"},
+		},
+		{
+			name:     "excluded secrets are not placed",
+			delivery: placement.SecretDelivery{Mode: placement.SecretDeliveryExclude},
+			expected: []string{"Namespace/kuadrant-test", "Gateway/test"},
+		},
+		{
+			name:     "external secrets are placed without the secret data",
+			delivery: placement.SecretDelivery{Mode: placement.SecretDeliveryExternalSecret, SecretStoreName: "hub", RemoteKeyPrefix: "gateways/"},
+			expected: []string{"Namespace/kuadrant-test", "Gateway/test", "ExternalSecret/tls"},
+			assertObj: func(t *testing.T, obj map[string]interface{}) {
+				if obj["kind"] != "ExternalSecret" {
+					return
+				}
+				if _, ok := obj["data"]; ok {
+					t.Fatalf("expected no secret data in the external secret got %v", obj)
+				}
+				spec := obj["spec"].(map[string]interface{})
+				store := spec["secretStoreRef"].(map[string]interface{})
+				if store["name"] != "hub" || store["kind"] != placement.ClusterSecretStoreKind {
+					t.Fatalf("expected the external secret to read from the hub cluster store got %v", store)
+				}
+				extract := spec["dataFrom"].([]interface{})[0].(map[string]interface{})["extract"].(map[string]interface{})
+				if extract["key"] != "gateways/kuadrant-test/tls" {
+					t.Fatalf("expected the external secret to extract the prefixed key got %v", extract)
+				}
+				template := spec["target"].(map[string]interface{})["template"].(map[string]interface{})
+				if template["type"] != string(corev1.SecretTypeTLS) {
+					t.Fatalf("expected the external secret to create a tls secret got %v", template)
+				}
+			},
+		},
+	}
+
+	for _, testCase := range cases {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := fake.NewClientBuilder().WithObjects(decision).Build()
+			p := placement.NewOCMPlacer(c, placement.WithSecretDelivery(testCase.delivery))
+			if _, err := p.Place(context.TODO(), upstream, downstream, secret); err != nil {
+				t.Fatalf("did not expect an error placing gateway but got %s", err)
+			}
+			work := &workv1.ManifestWork{}
+			if err := c.Get(context.TODO(), client.ObjectKey{Namespace: "c1", Name: placement.WorkName(upstream)}, work); err != nil {
+				t.Fatalf("expected manifest work to exist %s", err)
+			}
+			ids := []string{}
+			for _, m := range work.Spec.Workload.Manifests {
+				obj := map[string]interface{}{}
+				if err := json.Unmarshal(m.Raw, &obj); err != nil {
+					t.Fatal(err)
+				}
+				metadata := obj["metadata"].(map[string]interface{})
+				ids = append(ids, obj["kind"].(string)+"/"+metadata["name"].(string))
+				if testCase.assertObj != nil {
+					testCase.assertObj(t, obj)
+				}
+			}
+			if !reflect.DeepEqual(ids, testCase.expected) {
+				t.Fatalf("expected manifests %v got %v", testCase.expected, ids)
+			}
+		})
+	}
+}
+
+func TestPlaceExternalSecretKeys(t *testing.T) {
+	delivery := placement.SecretDelivery{Mode: placement.SecretDeliveryExternalSecret, SecretStoreName: "hub", RemoteKeyPrefix: "gateways/"}
+	c := fake.NewClientBuilder().Build()
+	p := placement.NewOCMPlacer(c, placement.WithSecretDelivery(delivery))
+
+	// secrets of the same name placed with gateways in different namespaces are read from different keys
+	keys := []string{}
+	for _, namespace := range []string{"team-a", "team-b"} {
+		upstream := &gatewayapiv1.Gateway{
+			ObjectMeta: v1.ObjectMeta{
+				Labels:    map[string]string{placement.OCMPlacementLabel: "test"},
+				Namespace: namespace,
+				Name:      "test",
+			},
+			TypeMeta: v1.TypeMeta{
+				Kind:       "Gateway",
+				APIVersion: "gateway.networking.k8s.io/v1",
+			},
+		}
+		downstream := upstream.DeepCopy()
+		downstream.Namespace = "kuadrant-" + namespace
+		secret := &corev1.Secret{
+			TypeMeta:   v1.TypeMeta{Kind: "Secret", APIVersion: "v1"},
+			ObjectMeta: v1.ObjectMeta{Name: "tls", Namespace: downstream.Namespace},
+			Type:       corev1.SecretTypeTLS,
+		}
+		decision := &pd.PlacementDecision{
+			ObjectMeta: v1.ObjectMeta{
+				Labels:    map[string]string{placement.OCMPlacementLabel: "test"},
+				Namespace: namespace,
+				Name:      "test",
+			},
+			Status: pd.PlacementDecisionStatus{
+				Decisions: []pd.ClusterDecision{{ClusterName: "c1"}},
+			},
+		}
+		if err := c.Create(context.TODO(), decision); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := p.Place(context.TODO(), upstream, downstream, secret); err != nil {
+			t.Fatalf("did not expect an error placing gateway but got %s", err)
+		}
+		work := &workv1.ManifestWork{}
+		if err := c.Get(context.TODO(), client.ObjectKey{Namespace: "c1", Name: placement.WorkName(upstream)}, work); err != nil {
+			t.Fatalf("expected manifest work to exist %s", err)
+		}
+		for _, m := range work.Spec.Workload.Manifests {
+			obj := map[string]interface{}{}
+			if err := json.Unmarshal(m.Raw, &obj); err != nil {
+				t.Fatal(err)
+			}
+			if obj["kind"] != "ExternalSecret" {
+				continue
+			}
+			extract := obj["spec"].(map[string]interface{})["dataFrom"].([]interface{})[0].(map[string]interface{})["extract"].(map[string]interface{})
+			keys = append(keys, extract["key"].(string))
+		}
+	}
+
+	expected := []string{"gateways/kuadrant-team-a/tls", "gateways/kuadrant-team-b/tls"}
+	if !reflect.DeepEqual(keys, expected) {
+		t.Fatalf("expected external secret keys %v got %v", expected, keys)
+	}
+}
+
+func TestPlaceUnstructuredChildren(t *testing.T) {
+	upstream := &gatewayapiv1.Gateway{
+		ObjectMeta: v1.ObjectMeta{
+			Labels:    map[string]string{placement.OCMPlacementLabel: "test"},
+			Namespace: "test",
+			Name:      "test",
+		},
+		TypeMeta: v1.TypeMeta{
+			Kind:       "Gateway",
+			APIVersion: "gateway.networking.k8s.io/v1",
+		},
+	}
+	downstream := upstream.DeepCopy()
+	downstream.Namespace = "kuadrant-test"
+	child := func(apiVersion, kind string) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{}
+		obj.SetAPIVersion(apiVersion)
+		obj.SetKind(kind)
+		obj.SetNamespace("kuadrant-test")
+		obj.SetName("shared")
+		return obj
+	}
+	decision := &pd.PlacementDecision{
+		ObjectMeta: v1.ObjectMeta{
+			Labels:    map[string]string{placement.OCMPlacementLabel: "test"},
+			Namespace: "test",
+			Name:      "test",
+		},
+		Status: pd.PlacementDecisionStatus{
+			Decisions: []pd.ClusterDecision{{ClusterName: "c1"}},
+		},
+	}
+	c := fake.NewClientBuilder().WithObjects(decision).Build()
+	p := placement.NewOCMPlacer(c)
+
+	// children of different kinds with the same namespace and name are all placed, and duplicates only once
+	if _, err := p.Place(context.TODO(), upstream, downstream, child("v1", "Service"), child("v1", "ConfigMap"), child("v1", "Service")); err != nil {
+		t.Fatalf("did not expect an error placing gateway but got %s", err)
+	}
+	work := &workv1.ManifestWork{}
+	if err := c.Get(context.TODO(), client.ObjectKey{Namespace: "c1", Name: placement.WorkName(upstream)}, work); err != nil {
+		t.Fatalf("expected manifest work to exist %s", err)
+	}
+	ids := []string{}
+	for _, m := range work.Spec.Workload.Manifests {
+		obj := map[string]interface{}{}
+		if err := json.Unmarshal(m.Raw, &obj); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, obj["kind"].(string)+"/"+obj["metadata"].(map[string]interface{})["name"].(string))
+	}
+	expected := []string{"Namespace/kuadrant-test", "Gateway/test", "ConfigMap/shared", "Service/shared"}
+	if !reflect.DeepEqual(ids, expected) {
+		t.Fatalf("expected manifests %v got %v", expected, ids)
+	}
+}
+
+func TestPlaceRBAC(t *testing.T) {
+	gateway := func(name string) *gatewayapiv1.Gateway {
+		return &gatewayapiv1.Gateway{
+			ObjectMeta: v1.ObjectMeta{
+				Labels:    map[string]string{placement.OCMPlacementLabel: name},
+				Namespace: "test",
+				Name:      name,
+			},
+			TypeMeta: v1.TypeMeta{
+				Kind:       "Gateway",
+				APIVersion: "gateway.networking.k8s.io/v1",
+			},
+		}
+	}
+	decision := func(name string, clusters ...string) *pd.PlacementDecision {
+		d := &pd.PlacementDecision{
+			ObjectMeta: v1.ObjectMeta{
+				Labels:    map[string]string{placement.OCMPlacementLabel: name},
+				Namespace: "test",
+				Name:      name,
+			},
+		}
+		for _, cluster := range clusters {
+			d.Status.Decisions = append(d.Status.Decisions, pd.ClusterDecision{ClusterName: cluster})
+		}
+		return d
+	}
+	subject := rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Name: "work-agent", Namespace: "klusterlet"}
+	c := fake.NewClientBuilder().WithObjects(decision("a", "c1"), decision("b", "c1")).Build()
+	p := placement.NewOCMPlacer(c, placement.WithWorkAgentSubject(subject))
+	rbacKey := client.ObjectKey{Namespace: "c1", Name: "gateway-rbac"}
+	place := func(upstream *gatewayapiv1.Gateway) {
+		t.Helper()
+		downstream := upstream.DeepCopy()
+		downstream.Namespace = "kuadrant-test"
+		if _, err := p.Place(context.TODO(), upstream, downstream); err != nil {
+			t.Fatalf("did not expect an error placing gateway but got %s", err)
+		}
+		work := &workv1.ManifestWork{}
+		if err := c.Get(context.TODO(), client.ObjectKey{Namespace: "c1", Name: placement.WorkName(upstream)}, work); err != nil {
+			return
+		}
+		work.Status.Conditions = []v1.Condition{{Type: workv1.WorkApplied, Status: v1.ConditionTrue, Reason: "Test", LastTransitionTime: v1.Now()}}
+		if err := c.Update(context.TODO(), work); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	place(gateway("a"))
+	place(gateway("b"))
+
+	// the work agent is only allowed to manage the kinds placed
+	work := &workv1.ManifestWork{}
+	if err := c.Get(context.TODO(), rbacKey, work); err != nil {
+		t.Fatalf("expected rbac work to exist %s", err)
+	}
+	clusterRole := &rbacv1.ClusterRole{}
+	if err := json.Unmarshal(work.Spec.Workload.Manifests[0].Raw, clusterRole); err != nil {
+		t.Fatal(err)
+	}
+	resources := map[string][]string{}
+	for _, rule := range clusterRole.Rules {
+		for _, verb := range rule.Verbs {
+			if verb == "*" {
+				t.Fatalf("expected no wildcard verbs got %v", rule)
+			}
+		}
+		for _, resource := range rule.Resources {
+			resources[resource] = rule.Verbs
+		}
+	}
+	if len(resources) != 3 || resources["gateways"] == nil || resources["secrets"] == nil || resources["namespaces"] == nil {
+		t.Fatalf("expected rules for gateways, secrets and namespaces got %v", clusterRole.Rules)
+	}
+	for _, verb := range resources["namespaces"] {
+		if verb == "delete" {
+			t.Fatalf("expected namespaces not to be deleted got %v", resources["namespaces"])
+		}
+	}
+	binding := &rbacv1.ClusterRoleBinding{}
+	if err := json.Unmarshal(work.Spec.Workload.Manifests[1].Raw, binding); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(binding.Subjects, []rbacv1.Subject{subject}) {
+		t.Fatalf("expected the rbac to be bound to %v got %v", subject, binding.Subjects)
+	}
+
+	// the rbac is kept while other gateways remain on the cluster
+	removeCluster := func(name string) {
+		t.Helper()
+		d := &pd.PlacementDecision{}
+		if err := c.Get(context.TODO(), client.ObjectKey{Namespace: "test", Name: name}, d); err != nil {
+			t.Fatal(err)
+		}
+		d.Status.Decisions = nil
+		if err := c.Update(context.TODO(), d); err != nil {
+			t.Fatal(err)
+		}
+	}
+	removeCluster("a")
+	place(gateway("a"))
+	if err := c.Get(context.TODO(), rbacKey, work); err != nil {
+		t.Fatalf("expected rbac work to be kept while gateway b remains: %s", err)
+	}
+
+	// and removed with the last gateway, once the work agent has removed its objects
+	gatewayWork := &workv1.ManifestWork{}
+	gatewayWorkKey := client.ObjectKey{Namespace: "c1", Name: placement.WorkName(gateway("b"))}
+	if err := c.Get(context.TODO(), gatewayWorkKey, gatewayWork); err != nil {
+		t.Fatal(err)
+	}
+	gatewayWork.Finalizers = []string{"cluster.open-cluster-management.io/manifest-work-cleanup"}
+	if err := c.Update(context.TODO(), gatewayWork); err != nil {
+		t.Fatal(err)
+	}
+	removeCluster("b")
+	place(gateway("b"))
+	if err := c.Get(context.TODO(), rbacKey, work); err != nil {
+		t.Fatalf("expected rbac work to be kept while the gateway work is being deleted: %s", err)
+	}
+	if err := c.Get(context.TODO(), gatewayWorkKey, gatewayWork); err != nil {
+		t.Fatal(err)
+	}
+	gatewayWork.Finalizers = nil
+	if err := c.Update(context.TODO(), gatewayWork); err != nil {
+		t.Fatal(err)
+	}
+	place(gateway("b"))
+	if err := c.Get(context.TODO(), rbacKey, work); !k8serrors.IsNotFound(err) {
+		t.Fatalf("expected rbac work to be removed with the last gateway got %v", err)
+	}
+}
+
+func TestPlaceNamespaceMigration(t *testing.T) {
+	upstream := &gatewayapiv1.Gateway{
+		ObjectMeta: v1.ObjectMeta{
+			Labels:    map[string]string{placement.OCMPlacementLabel: "test"},
+			Namespace: "test",
+			Name:      "test",
+		},
+		TypeMeta: v1.TypeMeta{
+			Kind:       "Gateway",
+			APIVersion: "gateway.networking.k8s.io/v1",
+		},
+	}
+	secret := func(namespace string) *corev1.Secret {
+		return &corev1.Secret{
+			TypeMeta:   v1.TypeMeta{Kind: "Secret", APIVersion: "v1"},
+			ObjectMeta: v1.ObjectMeta{Name: "tls", Namespace: namespace},
+		}
+	}
+	decision := &pd.PlacementDecision{
+		ObjectMeta: v1.ObjectMeta{
+			Labels:    map[string]string{placement.OCMPlacementLabel: "test"},
+			Namespace: "test",
+			Name:      "test",
+		},
+		Status: pd.PlacementDecisionStatus{
+			Decisions: []pd.ClusterDecision{{ClusterName: "c1"}},
+		},
+	}
+
+	c := fake.NewClientBuilder().WithObjects(decision).Build()
+	p := placement.NewOCMPlacer(c)
+	workKey := client.ObjectKey{Namespace: "c1", Name: placement.WorkName(upstream)}
+	manifestIDs := func(work *workv1.ManifestWork) sets.Set[string] {
+		ids := sets.New[string]()
+		for _, m := range work.Spec.Workload.Manifests {
+			obj := map[string]interface{}{}
+			if err := json.Unmarshal(m.Raw, &obj); err != nil {
+				t.Fatal(err)
+			}
+			metadata := obj["metadata"].(map[string]interface{})
+			ids.Insert(fmt.Sprintf("%s/%v/%v", obj["kind"], metadata["namespace"], metadata["name"]))
+		}
+		return ids
+	}
+	orphaned := func(work *workv1.ManifestWork) sets.Set[string] {
+		namespaces := sets.New[string]()
+		for _, rule := range work.Spec.DeleteOption.SelectivelyOrphan.OrphaningRules {
+			namespaces.Insert(rule.Name)
+		}
+		return namespaces
+	}
+
+	downstream := upstream.DeepCopy()
+	downstream.Namespace = "kuadrant-test"
+	if _, err := p.Place(context.TODO(), upstream, downstream, secret("kuadrant-test")); err != nil {
+		t.Fatalf("did not expect an error placing gateway but got %s", err)
+	}
+
+	// moving the gateway to another namespace keeps the objects in the old one until it is applied
+	downstream.Namespace = "test-edge"
+	if _, err := p.Place(context.TODO(), upstream, downstream, secret("test-edge")); err != nil {
+		t.Fatalf("did not expect an error placing gateway but got %s", err)
+	}
+	work := &workv1.ManifestWork{}
+	if err := c.Get(context.TODO(), workKey, work); err != nil {
+		t.Fatal(err)
+	}
+	expected := sets.New(
+		"Namespace/<nil>/test-edge", "Gateway/test-edge/test", "Secret/test-edge/tls",
+		"Namespace/<nil>/kuadrant-test", "Gateway/kuadrant-test/test", "Secret/kuadrant-test/tls",
+	)
+	if ids := manifestIDs(work); !ids.Equal(expected) {
+		t.Fatalf("expected manifests %v got %v", sets.List(expected), sets.List(ids))
+	}
+	if namespaces := orphaned(work); !namespaces.Equal(sets.New("kuadrant-test", "test-edge")) {
+		t.Fatalf("expected both namespaces to be orphaned got %v", sets.List(namespaces))
+	}
+
+	// the objects in the old namespace are pruned once the work is applied in the new one
+	applied := []v1.Condition{{Type: string(workv1.ManifestApplied), Status: v1.ConditionTrue}}
+	work.Status.ResourceStatus.Manifests = []workv1.ManifestCondition{
+		{ResourceMeta: workv1.ManifestResourceMeta{Kind: "Namespace", Name: "test-edge"}, Conditions: applied},
+		{ResourceMeta: workv1.ManifestResourceMeta{Group: "gateway.networking.k8s.io", Kind: "Gateway", Namespace: "test-edge", Name: "test"}, Conditions: applied},
+		{ResourceMeta: workv1.ManifestResourceMeta{Kind: "Secret", Namespace: "test-edge", Name: "tls"}, Conditions: applied},
+	}
+	if err := c.Update(context.TODO(), work); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Place(context.TODO(), upstream, downstream, secret("test-edge")); err != nil {
+		t.Fatalf("did not expect an error placing gateway but got %s", err)
+	}
+	if err := c.Get(context.TODO(), workKey, work); err != nil {
+		t.Fatal(err)
+	}
+	expected = sets.New("Namespace/<nil>/test-edge", "Gateway/test-edge/test", "Secret/test-edge/tls")
+	if ids := manifestIDs(work); !ids.Equal(expected) {
+		t.Fatalf("expected manifests %v got %v", sets.List(expected), sets.List(ids))
+	}
+	if namespaces := orphaned(work); !namespaces.Equal(sets.New("kuadrant-test", "test-edge")) {
+		t.Fatalf("expected the old namespace to stay orphaned while it is pruned got %v", sets.List(namespaces))
+	}
+}
+
+func TestPlaceClusterOverrides(t *testing.T) {
+	hostname := func(name string) *gatewayapiv1.Hostname {
+		h := gatewayapiv1.Hostname(name)
+		return &h
+	}
+	upstream := &gatewayapiv1.Gateway{
+		ObjectMeta: v1.ObjectMeta{
+			Labels:    map[string]string{placement.OCMPlacementLabel: "test"},
+			Namespace: "test",
+			Name:      "test",
+		},
+		TypeMeta: v1.TypeMeta{
+			Kind:       "Gateway",
+			APIVersion: "gateway.networking.k8s.io/v1",
+		},
+		Spec: gatewayapiv1.GatewaySpec{
+			Listeners: []gatewayapiv1.Listener{{Name: "api", Hostname: hostname("api.example.com")}},
+		},
+	}
+	downstream := upstream.DeepCopy()
+	downstream.Namespace = "kuadrant-test"
+	decision := &pd.PlacementDecision{
+		ObjectMeta: v1.ObjectMeta{
+			Labels:    map[string]string{placement.OCMPlacementLabel: "test"},
+			Namespace: "test",
+			Name:      "test",
+		},
+		Status: pd.PlacementDecisionStatus{
+			Decisions: []pd.ClusterDecision{{ClusterName: "c1"}, {ClusterName: "c2"}},
+		},
+	}
+	c1 := &clusterv1.ManagedCluster{
+		ObjectMeta: v1.ObjectMeta{Name: "c1", Labels: map[string]string{"region": "eu"}},
+	}
+	c2 := &clusterv1.ManagedCluster{
+		ObjectMeta: v1.ObjectMeta{Name: "c2"},
+		Status: clusterv1.ManagedClusterStatus{
+			ClusterClaims: []clusterv1.ManagedClusterClaim{{Name: "region", Value: "us"}},
+		},
+	}
+	usOverride := &v1alpha1.GatewayClusterOverride{
+		ObjectMeta: v1.ObjectMeta{Name: "b-us", Namespace: "test"},
+		Spec: v1alpha1.GatewayClusterOverrideSpec{
+			GatewayName:     "test",
+			ClusterSelector: &v1.LabelSelector{MatchLabels: map[string]string{"region": "us"}},
+			Addresses:       []gatewayapiv1.GatewayAddress{{Value: "10.0.0.1"}},
+			Listeners:       []v1alpha1.ListenerOverride{{Name: "api", Hostname: hostname("us.api.example.com")}},
+		},
+	}
+	allOverride := &v1alpha1.GatewayClusterOverride{
+		ObjectMeta: v1.ObjectMeta{Name: "a-all", Namespace: "test"},
+		Spec: v1alpha1.GatewayClusterOverrideSpec{
+			GatewayName: "test",
+			Infrastructure: &gatewayapiv1.GatewayInfrastructure{
+				Annotations: map[gatewayapiv1.AnnotationKey]gatewayapiv1.AnnotationValue{"service.beta.kubernetes.io/aws-load-balancer-scheme": "internal"},
+			},
+			Addresses: []gatewayapiv1.GatewayAddress{{Value: "10.0.0.2"}},
+		},
+	}
+	otherGatewayOverride := &v1alpha1.GatewayClusterOverride{
+		ObjectMeta: v1.ObjectMeta{Name: "c-other", Namespace: "test"},
+		Spec: v1alpha1.GatewayClusterOverrideSpec{
+			GatewayName: "other",
+			Addresses:   []gatewayapiv1.GatewayAddress{{Value: "10.0.0.3"}},
+		},
+	}
+
+	c := fake.NewClientBuilder().WithObjects(decision, c1, c2, usOverride, allOverride, otherGatewayOverride).Build()
+	p := placement.NewOCMPlacer(c)
+	if _, err := p.Place(context.TODO(), upstream, downstream); err != nil {
+		t.Fatalf("did not expect an error placing gateway but got %s", err)
+	}
+
+	placedGateway := func(cluster string) *gatewayapiv1.Gateway {
+		work := &workv1.ManifestWork{}
+		if err := c.Get(context.TODO(), client.ObjectKey{Namespace: cluster, Name: placement.WorkName(upstream)}, work); err != nil {
+			t.Fatalf("expected manifest work to exist on %s: %s", cluster, err)
+		}
+		gateway := &gatewayapiv1.Gateway{}
+		if err := json.Unmarshal(work.Spec.Workload.Manifests[1].Raw, gateway); err != nil {
+			t.Fatal(err)
+		}
+		return gateway
+	}
+
+	eu := placedGateway("c1")
+	if len(eu.Spec.Addresses) != 1 || eu.Spec.Addresses[0].Value != "10.0.0.2" {
+		t.Fatalf("expected the address of the override of every cluster on c1 got %v", eu.Spec.Addresses)
+	}
+	if *eu.Spec.Listeners[0].Hostname != "api.example.com" {
+		t.Fatalf("expected the listener hostname not to be overridden on c1 got %s", *eu.Spec.Listeners[0].Hostname)
+	}
+	if eu.Spec.Infrastructure == nil || eu.Spec.Infrastructure.Annotations["service.beta.kubernetes.io/aws-load-balancer-scheme"] != "internal" {
+		t.Fatalf("expected infrastructure annotations on c1 got %v", eu.Spec.Infrastructure)
+	}
+
+	// the overrides are applied in the order of their names
+	us := placedGateway("c2")
+	if len(us.Spec.Addresses) != 1 || us.Spec.Addresses[0].Value != "10.0.0.1" {
+		t.Fatalf("expected the address of the override selecting the cluster claim on c2 got %v", us.Spec.Addresses)
+	}
+	if *us.Spec.Listeners[0].Hostname != "us.api.example.com" {
+		t.Fatalf("expected the listener hostname to be overridden on c2 got %s", *us.Spec.Listeners[0].Hostname)
+	}
+	if downstream.Spec.Addresses != nil || *downstream.Spec.Listeners[0].Hostname != "api.example.com" {
+		t.Fatalf("expected the downstream gateway not to be modified got %v", downstream.Spec)
+	}
+}
+
+func TestReplicaSetPlace(t *testing.T) {
+	upstream := &gatewayapiv1.Gateway{
+		ObjectMeta: v1.ObjectMeta{
+			Labels:    map[string]string{placement.OCMPlacementLabel: "test-placement"},
+			Namespace: "test",
+			Name:      "test",
+		},
+		TypeMeta: v1.TypeMeta{
+			Kind:       "Gateway",
+			APIVersion: "gateway.networking.k8s.io/v1",
+		},
+	}
+	downstream := upstream.DeepCopy()
+	downstream.Namespace = "kuadrant-test"
+	decision := &pd.PlacementDecision{
+		ObjectMeta: v1.ObjectMeta{
+			Labels:    map[string]string{placement.OCMPlacementLabel: "test-placement"},
+			Namespace: "test",
+			Name:      "test",
+		},
+		Status: pd.PlacementDecisionStatus{
+			Decisions: []pd.ClusterDecision{{ClusterName: "c1"}, {ClusterName: "c2"}},
+		},
+	}
+	replicaSetName := placement.ReplicaSetName(upstream)
+	if replicaSetName == placement.WorkName(upstream) {
+		t.Fatalf("expected replica set name to differ from the work placed per cluster %s", replicaSetName)
+	}
+	appliedWork := &workv1.ManifestWork{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      replicaSetName,
+			Namespace: "c1",
+			Labels:    map[string]string{placement.ReplicaSetWorkLabel: "test." + replicaSetName},
+		},
+		Status: workv1.ManifestWorkStatus{
+			Conditions: []v1.Condition{{Type: string(workv1.ManifestApplied), Status: v1.ConditionTrue}},
+		},
+	}
+	rbacWork := &workv1.ManifestWork{
+		ObjectMeta: v1.ObjectMeta{Name: "gateway-rbac", Namespace: "c1"},
+	}
+
+	c := fake.NewClientBuilder().WithObjects(decision, appliedWork, rbacWork).Build()
+	p := placement.NewOCMReplicaSetPlacer(c)
+
+	targets, err := p.Place(context.TODO(), upstream, downstream)
+	if err != nil {
+		t.Fatalf("did not expect an error placing gateway but got %s", err)
+	}
+	if !targets.Equal(sets.New("c1", "c2")) {
+		t.Fatalf("expected gateway to target c1 and c2 got %v", sets.List(targets))
+	}
+
+	// the rbac is only ensured on the clusters the work of the replica set is not applied on yet
+	if err := c.Get(context.TODO(), client.ObjectKey{Namespace: "c2", Name: "gateway-rbac"}, &workv1.ManifestWork{}); err != nil {
+		t.Fatalf("expected rbac to be placed on c2 %s", err)
+	}
+
+	mwrs := &workv1alpha1.ManifestWorkReplicaSet{}
+	if err := c.Get(context.TODO(), client.ObjectKey{Namespace: "test", Name: replicaSetName}, mwrs); err != nil {
+		t.Fatalf("expected manifest work replica set to exist %s", err)
+	}
+	if len(mwrs.Spec.PlacementRefs) != 1 || mwrs.Spec.PlacementRefs[0].Name != "test-placement" {
+		t.Fatalf("expected replica set to reference the gateway placement, got %v", mwrs.Spec.PlacementRefs)
+	}
+	if mwrs.Annotations[placement.ParentAnnotation] != "test/test" {
+		t.Fatalf("expected replica set parent annotation to be test/test, got %v", mwrs.Annotations)
+	}
+	if len(mwrs.Spec.ManifestWorkTemplate.Workload.Manifests) != 2 {
+		t.Fatalf("expected namespace and gateway manifests, got %d", len(mwrs.Spec.ManifestWorkTemplate.Workload.Manifests))
+	}
+
+	key, ok := placement.ReplicaSetForWork(appliedWork)
+	if !ok || key != client.ObjectKeyFromObject(mwrs) {
+		t.Fatalf("expected work to reference replica set %v got %v", client.ObjectKeyFromObject(mwrs), key)
+	}
+
+	placed, err := p.GetPlacedClusters(context.TODO(), upstream)
+	if err != nil {
+		t.Fatalf("did not expect an error getting placed clusters but got %s", err)
+	}
+	if !placed.Equal(sets.New("c1")) {
+		t.Fatalf("expected gateway to be placed on c1 got %v", sets.List(placed))
+	}
+
+	// deleting the gateway removes the replica set, keeping the rbac until OCM removes its works
+	upstream.DeletionTimestamp = &v1.Time{}
+	if _, err := p.Place(context.TODO(), upstream, downstream); !errors.Is(err, placement.ErrWorkDeleting) {
+		t.Fatalf("expected to wait for the works of the replica set to be removed but got %v", err)
+	}
+	if err := c.Get(context.TODO(), client.ObjectKeyFromObject(mwrs), mwrs); !k8serrors.IsNotFound(err) {
+		t.Fatalf("expected manifest work replica set to be deleted, got %v", err)
+	}
+	if err := c.Get(context.TODO(), client.ObjectKeyFromObject(rbacWork), &workv1.ManifestWork{}); err != nil {
+		t.Fatalf("expected rbac to be kept on c1 while the work of the replica set remains, got %v", err)
+	}
+
+	// and removes the rbac once the works are gone
+	if err := c.Delete(context.TODO(), appliedWork); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Place(context.TODO(), upstream, downstream); err != nil {
+		t.Fatalf("did not expect an error removing gateway but got %s", err)
+	}
+	if err := c.Get(context.TODO(), client.ObjectKeyFromObject(rbacWork), &workv1.ManifestWork{}); !k8serrors.IsNotFound(err) {
+		t.Fatalf("expected rbac to be removed from c1, got %v", err)
+	}
+}
+
+func TestReplicaSetPlaceSwitch(t *testing.T) {
+	upstream := &gatewayapiv1.Gateway{
+		ObjectMeta: v1.ObjectMeta{
+			Labels:    map[string]string{placement.OCMPlacementLabel: "test-placement"},
+			Namespace: "test",
+			Name:      "test",
+		},
+		TypeMeta: v1.TypeMeta{
+			Kind:       "Gateway",
+			APIVersion: "gateway.networking.k8s.io/v1",
+		},
+	}
+	downstream := upstream.DeepCopy()
+	downstream.Namespace = "kuadrant-test"
+	decision := &pd.PlacementDecision{
+		ObjectMeta: v1.ObjectMeta{
+			Labels:    map[string]string{placement.OCMPlacementLabel: "test-placement"},
+			Namespace: "test",
+			Name:      "test",
+		},
+		Status: pd.PlacementDecisionStatus{
+			Decisions: []pd.ClusterDecision{{ClusterName: "c1"}},
+		},
+	}
+	replicaSetWork := &workv1.ManifestWork{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      placement.ReplicaSetName(upstream),
+			Namespace: "c1",
+			Labels:    map[string]string{placement.ReplicaSetWorkLabel: "test." + placement.ReplicaSetName(upstream)},
+		},
+		Status: workv1.ManifestWorkStatus{
+			Conditions: []v1.Condition{{Type: string(workv1.ManifestApplied), Status: v1.ConditionTrue}},
+		},
+	}
+	c := fake.NewClientBuilder().WithObjects(decision, replicaSetWork).WithStatusSubresource(&workv1.ManifestWork{}).Build()
+	p := placement.NewOCMReplicaSetPlacer(c)
+	mwrsKey := client.ObjectKey{Namespace: "test", Name: placement.ReplicaSetName(upstream)}
+	workKey := client.ObjectKey{Namespace: "c1", Name: placement.WorkName(upstream)}
+
+	if _, err := p.Place(context.TODO(), upstream, downstream); err != nil {
+		t.Fatalf("did not expect an error placing gateway but got %s", err)
+	}
+
+	// a gateway moving to a work per cluster keeps the replica set until the work is applied
+	upstream.Annotations = map[string]string{placement.MaxClustersAnnotation: "1"}
+	if _, err := p.Place(context.TODO(), upstream, downstream); err != nil {
+		t.Fatalf("did not expect an error placing gateway but got %s", err)
+	}
+	work := &workv1.ManifestWork{}
+	if err := c.Get(context.TODO(), workKey, work); err != nil {
+		t.Fatalf("expected manifest work to be placed on c1 %s", err)
+	}
+	if err := c.Get(context.TODO(), mwrsKey, &workv1alpha1.ManifestWorkReplicaSet{}); err != nil {
+		t.Fatalf("expected manifest work replica set to be kept until the work is applied %s", err)
+	}
+	work.Status.Conditions = []v1.Condition{{Type: string(workv1.ManifestApplied), Status: v1.ConditionTrue, Reason: "Applied", LastTransitionTime: v1.Now()}}
+	if err := c.Status().Update(context.TODO(), work); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Place(context.TODO(), upstream, downstream); err != nil {
+		t.Fatalf("did not expect an error placing gateway but got %s", err)
+	}
+	if err := c.Get(context.TODO(), mwrsKey, &workv1alpha1.ManifestWorkReplicaSet{}); !k8serrors.IsNotFound(err) {
+		t.Fatalf("expected manifest work replica set to be deleted once the work is applied, got %v", err)
+	}
+
+	// moving back to the replica set keeps the work until the work of the replica set is applied
+	upstream.Annotations = nil
+	replicaSetWork.Status.Conditions = nil
+	if err := c.Status().Update(context.TODO(), replicaSetWork); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Place(context.TODO(), upstream, downstream); err != nil {
+		t.Fatalf("did not expect an error placing gateway but got %s", err)
+	}
+	if err := c.Get(context.TODO(), mwrsKey, &workv1alpha1.ManifestWorkReplicaSet{}); err != nil {
+		t.Fatalf("expected manifest work replica set to be created %s", err)
+	}
+	if err := c.Get(context.TODO(), workKey, &workv1.ManifestWork{}); err != nil {
+		t.Fatalf("expected manifest work to be kept until the replica set is applied %s", err)
+	}
+	replicaSetWork.Status.Conditions = []v1.Condition{{Type: string(workv1.ManifestApplied), Status: v1.ConditionTrue, Reason: "Applied", LastTransitionTime: v1.Now()}}
+	if err := c.Status().Update(context.TODO(), replicaSetWork); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Place(context.TODO(), upstream, downstream); err != nil {
+		t.Fatalf("did not expect an error placing gateway but got %s", err)
+	}
+	if err := c.Get(context.TODO(), workKey, &workv1.ManifestWork{}); !k8serrors.IsNotFound(err) {
+		t.Fatalf("expected manifest work to be deleted once the replica set is applied, got %v", err)
+	}
+
+	// the status of the gateway is then read from the work of the replica set
+	addresses := `[{"type":"IPAddress","value":"172.31.200.0"}]`
+	attachedRoutes := int64(2)
+	replicaSetWork.Status.ResourceStatus.Manifests = []workv1.ManifestCondition{{
+		ResourceMeta: workv1.ManifestResourceMeta{Group: gatewayapiv1.GroupName, Kind: "Gateway", Name: "test", Namespace: "kuadrant-test"},
+		StatusFeedbacks: workv1.StatusFeedbackResult{Values: []workv1.FeedbackValue{
+			{Name: "addresses", Value: workv1.FieldValue{Type: workv1.JsonRaw, JsonRaw: &addresses}},
+			{Name: "listenerapiAttachedRoutes", Value: workv1.FieldValue{Type: workv1.Integer, Integer: &attachedRoutes}},
+		}},
+	}}
+	if err := c.Status().Update(context.TODO(), replicaSetWork); err != nil {
+		t.Fatal(err)
+	}
+	gatewayAddresses, err := p.GetAddresses(context.TODO(), upstream, "c1")
+	if err != nil {
+		t.Fatalf("did not expect an error getting addresses but got %s", err)
+	}
+	if len(gatewayAddresses) != 1 || gatewayAddresses[0].Value != "172.31.200.0" {
+		t.Fatalf("expected the addresses reported by the replica set work got %v", gatewayAddresses)
+	}
+	routes, err := p.ListenerTotalAttachedRoutes(context.TODO(), upstream, "api", "c1")
+	if err != nil || routes != 2 {
+		t.Fatalf("expected 2 attached routes reported by the replica set work got %d, %v", routes, err)
+	}
+	listenerStatus, err := p.GetListenerStatus(context.TODO(), upstream, "api", "c1")
+	if err != nil || listenerStatus.AttachedRoutes != 2 {
+		t.Fatalf("expected the listener status reported by the replica set work got %v, %v", listenerStatus, err)
+	}
+}
+
+func TestRollout(t *testing.T) {
+	upstream := &gatewayapiv1.Gateway{
+		ObjectMeta: v1.ObjectMeta{
+			Labels:    map[string]string{placement.OCMPlacementLabel: "test"},
+			Namespace: "test",
+			Name:      "test",
+		},
+		TypeMeta: v1.TypeMeta{
+			Kind:       "Gateway",
+			APIVersion: "gateway.networking.k8s.io/v1",
+		},
+		Spec: gatewayapiv1.GatewaySpec{
+			Listeners: []gatewayapiv1.Listener{{Name: "api", Port: 80, Protocol: gatewayapiv1.HTTPProtocolType}},
+		},
+	}
+	decision := &pd.PlacementDecision{
+		ObjectMeta: v1.ObjectMeta{
+			Labels:    map[string]string{placement.OCMPlacementLabel: "test"},
+			Namespace: "test",
+			Name:      "test",
+		},
+		Status: pd.PlacementDecisionStatus{
+			Decisions: []pd.ClusterDecision{{ClusterName: "c1"}, {ClusterName: "c2"}, {ClusterName: "c3"}},
+		},
+	}
+	c := fake.NewClientBuilder().WithObjects(decision).Build()
+	p := placement.NewOCMPlacer(c)
+	workKey := func(cluster string) client.ObjectKey {
+		return client.ObjectKey{Namespace: cluster, Name: placement.WorkName(upstream)}
+	}
+	downstream := func() *gatewayapiv1.Gateway {
+		d := upstream.DeepCopy()
+		d.Namespace = "kuadrant-test"
+		return d
+	}
+	setStatus := func(cluster string, status v1.ConditionStatus) {
+		work := &workv1.ManifestWork{}
+		if err := c.Get(context.TODO(), workKey(cluster), work); err != nil {
+			t.Fatal(err)
+		}
+		addresses := `[{"type":"IPAddress","value":"172.16.0.1"}]`
+		work.Status.Conditions = []v1.Condition{
+			{Type: workv1.WorkApplied, Status: status, ObservedGeneration: work.Generation, Reason: "Test", LastTransitionTime: v1.Now()},
+			{Type: workv1.WorkAvailable, Status: status, ObservedGeneration: work.Generation, Reason: "Test", LastTransitionTime: v1.Now()},
+		}
+		work.Status.ResourceStatus.Manifests = []workv1.ManifestCondition{{
+			StatusFeedbacks: workv1.StatusFeedbackResult{
+				Values: []workv1.FeedbackValue{{Name: "addresses", Value: workv1.FieldValue{Type: workv1.JsonRaw, JsonRaw: &addresses}}},
+			},
+		}}
+		if err := c.Update(context.TODO(), work); err != nil {
+			t.Fatal(err)
+		}
+	}
+	listenerPort := func(cluster string) gatewayapiv1.PortNumber {
+		work := &workv1.ManifestWork{}
+		if err := c.Get(context.TODO(), workKey(cluster), work); err != nil {
+			t.Fatal(err)
+		}
+		for _, m := range work.Spec.Workload.Manifests {
+			gateway := &gatewayapiv1.Gateway{}
+			if err := json.Unmarshal(m.Raw, gateway); err == nil && gateway.Kind == "Gateway" {
+				return gateway.Spec.Listeners[0].Port
+			}
+		}
+		return 0
+	}
+	expectRolloutReason := func(err error, reason string) {
+		t.Helper()
+		rolloutErr := &placement.RolloutError{}
+		if !errors.As(err, &rolloutErr) || rolloutErr.Reason != reason {
+			t.Fatalf("expected rollout error with reason %s got %v", reason, err)
+		}
+	}
+
+	// new clusters are placed straight away
+	if _, err := p.Place(context.TODO(), upstream, downstream()); err != nil {
+		t.Fatalf("did not expect an error placing gateway but got %s", err)
+	}
+	for _, cluster := range []string{"c1", "c2", "c3"} {
+		setStatus(cluster, v1.ConditionTrue)
+	}
+
+	// a change is rolled out one cluster at a time
+	upstream.Annotations = map[string]string{placement.RolloutBatchAnnotation: "1"}
+	upstream.Spec.Listeners[0].Port = 90
+	_, err := p.Place(context.TODO(), upstream, downstream())
+	expectRolloutReason(err, placement.RolloutReasonInProgress)
+	if listenerPort("c1") != 90 || listenerPort("c2") != 80 || listenerPort("c3") != 80 {
+		t.Fatalf("expected only c1 to be updated")
+	}
+
+	// waits for the updated cluster to be healthy
+	setStatus("c1", v1.ConditionUnknown)
+	_, err = p.Place(context.TODO(), upstream, downstream())
+	expectRolloutReason(err, placement.RolloutReasonInProgress)
+	if listenerPort("c2") != 80 {
+		t.Fatalf("expected c2 not to be updated until c1 is healthy")
+	}
+	setStatus("c1", v1.ConditionTrue)
+	_, err = p.Place(context.TODO(), upstream, downstream())
+	expectRolloutReason(err, placement.RolloutReasonInProgress)
+	if listenerPort("c2") != 90 || listenerPort("c3") != 80 {
+		t.Fatalf("expected c2 to be updated once c1 is healthy")
+	}
+
+	// halts when an updated cluster fails
+	setStatus("c2", v1.ConditionFalse)
+	_, err = p.Place(context.TODO(), upstream, downstream())
+	expectRolloutReason(err, placement.RolloutReasonHalted)
+	if listenerPort("c3") != 80 {
+		t.Fatalf("expected c3 not to be updated once the rollout is halted")
+	}
+
+	// completes once every cluster is healthy
+	setStatus("c2", v1.ConditionTrue)
+	_, err = p.Place(context.TODO(), upstream, downstream())
+	expectRolloutReason(err, placement.RolloutReasonInProgress)
+	if listenerPort("c3") != 90 {
+		t.Fatalf("expected c3 to be updated once c2 is healthy")
+	}
+	setStatus("c3", v1.ConditionTrue)
+	if _, err := p.Place(context.TODO(), upstream, downstream()); err != nil {
+		t.Fatalf("expected rollout to be complete but got %s", err)
+	}
+}
@@ -0,0 +1,173 @@
+package placement
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	workv1 "open-cluster-management.io/api/work/v1"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	gatewayapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// RolloutBatchAnnotation enables a progressive rollout of gateway changes. The value is the number,
+// or percentage, of clusters updated at a time, e.g. "1" or "25%". The next clusters are only updated
+// once the clusters already updated have applied the change and report addresses
+const RolloutBatchAnnotation = "kuadrant.io/gateway-rollout-batch"
+
+const (
+	RolloutReasonInvalid    = "InvalidRollout"
+	RolloutReasonInProgress = "RolloutInProgress"
+	RolloutReasonHalted     = "RolloutHalted"
+)
+
+// RolloutError is returned while a progressive rollout of the gateway is in progress, or when it
+// has been halted because an updated cluster failed to apply the change
+type RolloutError struct {
+	Reason  string
+	Message string
+}
+
+func (e *RolloutError) Error() string {
+	return e.Message
+}
+
+// IsRolloutInProgress returns true if the error is because the rollout is waiting on updated clusters
+func IsRolloutInProgress(err error) bool {
+	rolloutErr := &RolloutError{}
+	return errors.As(err, &rolloutErr) && rolloutErr.Reason == RolloutReasonInProgress
+}
+
+// rolloutBatch returns the number of clusters out of total to update at a time. Zero means
+// the gateway does not use a progressive rollout
+func rolloutBatch(gateway *gatewayapiv1.Gateway, total int) (int, error) {
+	value, ok := gateway.GetAnnotations()[RolloutBatchAnnotation]
+	if !ok {
+		return 0, nil
+	}
+	batch := intstr.Parse(strings.TrimSpace(value))
+	size, err := intstr.GetScaledValueFromIntOrPercent(&batch, total, true)
+	if err != nil || size < 0 || (batch.Type == intstr.Int && batch.IntVal < 1) {
+		return 0, &RolloutError{
+			Reason:  RolloutReasonInvalid,
+			Message: fmt.Sprintf("invalid %s %q: must be a positive number or a percentage", RolloutBatchAnnotation, value),
+		}
+	}
+	if size < 1 {
+		size = 1
+	}
+	return size, nil
+}
+
+// rolloutClusters returns the target clusters whose ManifestWork can be created or updated in this
+// reconcile. Clusters without the gateway are always included. Clusters with an out of date gateway
+// are updated a batch at a time, once every cluster already updated is healthy. If an updated
+// cluster has failed the rollout is halted until the gateway changes again or the cluster recovers
+func (op *ocmPlacer) rolloutClusters(ctx context.Context, batch int, targets sets.Set[string], desired map[string]workv1.ManifestWork) (sets.Set[string], error) {
+	include := sets.New[string]()
+	outdated := []string{}
+	failed := []string{}
+	pending := []string{}
+	for _, cluster := range sets.List(targets) {
+		want := desired[cluster]
+		existing := &workv1.ManifestWork{}
+		if err := op.c.Get(ctx, client.ObjectKeyFromObject(&want), existing); err != nil {
+			if k8serrors.IsNotFound(err) {
+				include.Insert(cluster)
+				continue
+			}
+			return include, err
+		}
+		if !workloadEqual(existing.Spec, want.Spec) {
+			outdated = append(outdated, cluster)
+			continue
+		}
+		include.Insert(cluster)
+		switch workHealth(existing) {
+		case workFailed:
+			failed = append(failed, cluster)
+		case workPending:
+			pending = append(pending, cluster)
+		}
+	}
+
+	if len(outdated) == 0 {
+		return include, nil
+	}
+	if len(failed) > 0 {
+		return include, &RolloutError{
+			Reason:  RolloutReasonHalted,
+			Message: fmt.Sprintf("rollout halted as the gateway failed to apply on clusters %v, clusters %v have not been updated", failed, outdated),
+		}
+	}
+	if len(pending) == 0 {
+		if batch > len(outdated) {
+			batch = len(outdated)
+		}
+		log.Log.V(3).Info("placement: rolling out gateway ", "clusters", outdated[:batch])
+		pending = outdated[:batch]
+		outdated = outdated[batch:]
+		include.Insert(pending...)
+	}
+	return include, &RolloutError{
+		Reason:  RolloutReasonInProgress,
+		Message: fmt.Sprintf("rollout waiting on clusters %v before updating clusters %v", pending, outdated),
+	}
+}
+
+type workHealthStatus int
+
+const (
+	workHealthy workHealthStatus = iota
+	workPending
+	workFailed
+)
+
+// workHealth returns whether the current generation of the work has been applied and is available,
+// with the gateway reporting its addresses
+func workHealth(work *workv1.ManifestWork) workHealthStatus {
+	current := func(conditionType string) *metav1.Condition {
+		condition := meta.FindStatusCondition(work.Status.Conditions, conditionType)
+		if condition == nil || condition.ObservedGeneration != work.Generation {
+			return nil
+		}
+		return condition
+	}
+	applied, available := current(workv1.WorkApplied), current(workv1.WorkAvailable)
+	if (applied != nil && applied.Status == metav1.ConditionFalse) || (available != nil && available.Status == metav1.ConditionFalse) {
+		return workFailed
+	}
+	if applied == nil || available == nil || applied.Status != metav1.ConditionTrue || available.Status != metav1.ConditionTrue {
+		return workPending
+	}
+	for _, m := range work.Status.ResourceStatus.Manifests {
+		for _, value := range m.StatusFeedbacks.Values {
+			if value.Name != "addresses" || value.Value.JsonRaw == nil {
+				continue
+			}
+			addresses := []gatewayapiv1.GatewayAddress{}
+			if err := json.Unmarshal([]byte(*value.Value.JsonRaw), &addresses); err == nil && len(addresses) > 0 {
+				return workHealthy
+			}
+		}
+	}
+	return workPending
+}
+
+// workloadEqual compares the workloads of two ManifestWork specs, ignoring the delete options which
+// carry the namespaces being orphaned by earlier updates and the manifests kept while migrating
+// away from a namespace
+func workloadEqual(existing, desired workv1.ManifestWorkSpec) bool {
+	desired.DeleteOption = existing.DeleteOption
+	existing.Workload.Manifests, _ = migratingManifests(existing.Workload.Manifests, desired.Workload.Manifests)
+	return ManifestWorkSpecEqual(existing, desired)
+}
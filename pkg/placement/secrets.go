@@ -0,0 +1,129 @@
+package placement
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+type SecretDeliveryMode string
+
+const (
+	// SecretDeliveryInline embeds the secrets in the ManifestWorks, so their data can be read by
+	// anyone able to read the ManifestWorks in the cluster namespaces of the hub
+	SecretDeliveryInline SecretDeliveryMode = "inline"
+	// SecretDeliveryExclude leaves the secrets out of the ManifestWorks, for them to be delivered
+	// to the spokes by other means
+	SecretDeliveryExclude SecretDeliveryMode = "exclude"
+	// SecretDeliveryExternalSecret places an ExternalSecret in place of each secret, which the
+	// External Secrets Operator on the spokes syncs from a secret store
+	SecretDeliveryExternalSecret SecretDeliveryMode = "externalsecret"
+)
+
+const (
+	externalSecretGroup      = "external-secrets.io"
+	externalSecretAPIVersion = externalSecretGroup + "/v1beta1"
+	externalSecretKind       = "ExternalSecret"
+	// ClusterSecretStoreKind is the default kind of the store the ExternalSecrets read from
+	ClusterSecretStoreKind = "ClusterSecretStore"
+	// SecretStoreKind is the kind of a store in the namespace of the ExternalSecrets
+	SecretStoreKind = "SecretStore"
+)
+
+// SecretDelivery configures how the TLS secrets placed with the gateways are delivered to the
+// spokes by the OCM placers
+type SecretDelivery struct {
+	Mode SecretDeliveryMode
+	// SecretStoreName is the name of the store on the spokes the ExternalSecrets read from
+	SecretStoreName string
+	// SecretStoreKind is the kind of the store, ClusterSecretStore or SecretStore
+	SecretStoreKind string
+	// RemoteKeyPrefix is prepended to the namespace/name of a secret to get its key in the store
+	RemoteKeyPrefix string
+}
+
+// Validate returns an error if the delivery is not configured correctly
+func (d SecretDelivery) Validate() error {
+	switch d.Mode {
+	case "", SecretDeliveryInline, SecretDeliveryExclude:
+	case SecretDeliveryExternalSecret:
+		if d.SecretStoreName == "" {
+			return fmt.Errorf("a secret store name is required for the %s secret delivery", d.Mode)
+		}
+		if d.SecretStoreKind != "" && d.SecretStoreKind != ClusterSecretStoreKind && d.SecretStoreKind != SecretStoreKind {
+			return fmt.Errorf("unknown secret store kind %q: must be %s or %s", d.SecretStoreKind, ClusterSecretStoreKind, SecretStoreKind)
+		}
+	default:
+		return fmt.Errorf("unknown secret delivery %q: must be one of %s, %s or %s", d.Mode, SecretDeliveryInline, SecretDeliveryExclude, SecretDeliveryExternalSecret)
+	}
+	return nil
+}
+
+// objects returns the objects to place with the secrets among them delivered by the mode
+func (d SecretDelivery) objects(obj ...metav1.Object) []metav1.Object {
+	if d.Mode == "" || d.Mode == SecretDeliveryInline {
+		return obj
+	}
+	objects := make([]metav1.Object, 0, len(obj))
+	for _, o := range obj {
+		secret, ok := o.(*corev1.Secret)
+		if !ok {
+			objects = append(objects, o)
+			continue
+		}
+		if d.Mode == SecretDeliveryExternalSecret {
+			objects = append(objects, d.externalSecret(secret))
+		}
+	}
+	return objects
+}
+
+// remoteKey returns the key of the secret in the store. It includes the namespace the secret
+// is placed in, as secrets of the same name are placed in the namespaces of different gateways
+func (d SecretDelivery) remoteKey(secret *corev1.Secret) string {
+	return d.RemoteKeyPrefix + secret.Namespace + "/" + secret.Name
+}
+
+// externalSecret returns the ExternalSecret that syncs the secret from the store into the
+// namespace it is placed in, without its data
+func (d SecretDelivery) externalSecret(secret *corev1.Secret) *unstructured.Unstructured {
+	storeKind := d.SecretStoreKind
+	if storeKind == "" {
+		storeKind = ClusterSecretStoreKind
+	}
+	secretType := secret.Type
+	if secretType == "" {
+		secretType = corev1.SecretTypeTLS
+	}
+	externalSecret := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"secretStoreRef": map[string]interface{}{
+				"name": d.SecretStoreName,
+				"kind": storeKind,
+			},
+			"target": map[string]interface{}{
+				"name":           secret.Name,
+				"creationPolicy": "Owner",
+				"template": map[string]interface{}{
+					"type": string(secretType),
+				},
+			},
+			"dataFrom": []interface{}{
+				map[string]interface{}{
+					"extract": map[string]interface{}{
+						"key": d.remoteKey(secret),
+					},
+				},
+			},
+		},
+	}}
+	externalSecret.SetAPIVersion(externalSecretAPIVersion)
+	externalSecret.SetKind(externalSecretKind)
+	externalSecret.SetName(secret.Name)
+	externalSecret.SetNamespace(secret.Namespace)
+	externalSecret.SetLabels(secret.Labels)
+	externalSecret.SetAnnotations(secret.Annotations)
+	return externalSecret
+}
@@ -3,80 +3,196 @@ package policysync
 import (
 	"context"
 	"fmt"
+	"sync"
 
 	"github.com/go-logr/logr"
 
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	gatewayapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+	crlog "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
 )
 
+// ResourceEventHandler queues the events of the watched policies, and syncs them
+// from its own worker so the policies that fail to sync are retried with backoff
 type ResourceEventHandler struct {
 	Log           logr.Logger
 	GVR           schema.GroupVersionResource
 	Client        client.Client
 	DynamicClient dynamic.Interface
-	Gateway       *gatewayapiv1.Gateway
 
 	Syncer Syncer
+
+	init  sync.Once
+	queue workqueue.RateLimitingInterface
+	// the last known state of the queued policies, keyed as the queue, as
+	// deleted policies can't be read back
+	mu      sync.Mutex
+	pending map[string]*policyEvent
+	workers sync.WaitGroup
+}
+
+// policyEvent is the last known state of a queued policy
+type policyEvent struct {
+	obj     interface{}
+	deleted bool
 }
 
 var _ cache.ResourceEventHandler = &ResourceEventHandler{}
+var _ manager.Runnable = &ResourceEventHandler{}
+
+// PolicyWatch is an event handler registered on the informer of the policies
+// of a resource
+type PolicyWatch struct {
+	Handler      *ResourceEventHandler
+	Registration cache.ResourceEventHandlerRegistration
+}
 
 func (h *ResourceEventHandler) OnAdd(reqObj interface{}, _ bool) {
 	h.Log.Info("Got watch event for policy", "obj", reqObj)
+	h.enqueue(reqObj, false)
+}
 
-	ctx := context.Background()
+func (h *ResourceEventHandler) OnDelete(reqObj interface{}) {
+	h.Log.Info("Got watch event for policy", "obj", reqObj)
 
-	obj, ok := reqObj.(client.Object)
-	if !ok {
-		h.Log.Error(fmt.Errorf("object %v does not inplement client.Object", reqObj), "")
-		return
+	// the final state of the object may be unknown if the watch missed the
+	// delete event, in which case the last known state is used
+	if tombstone, ok := reqObj.(cache.DeletedFinalStateUnknown); ok {
+		reqObj = tombstone.Obj
 	}
+	h.enqueue(reqObj, true)
+}
 
-	if err := h.Client.Get(ctx, client.ObjectKeyFromObject(obj), obj); err != nil {
-		h.Log.Error(err, "failed to get object", "object", obj)
+func (h *ResourceEventHandler) OnUpdate(_ interface{}, reqObj interface{}) {
+	h.Log.Info("Got watch event for policy", "obj", reqObj)
+	h.enqueue(reqObj, false)
+}
+
+// Start syncs the queued policies until the context is done or the handler is stopped
+func (h *ResourceEventHandler) Start(ctx context.Context) error {
+	h.setup()
+	h.workers.Add(1)
+	defer h.workers.Done()
+
+	go func() {
+		<-ctx.Done()
+		h.queue.ShutDown()
+	}()
+
+	ctx = crlog.IntoContext(ctx, h.Log)
+	for h.processNextItem(ctx) {
 	}
+	return nil
+}
 
-	policy, err := NewPolicyFor(obj)
+// Stop stops syncing the queued policies, waiting for the policy being synced
+func (h *ResourceEventHandler) Stop() {
+	h.setup()
+	h.queue.ShutDown()
+	h.workers.Wait()
+}
+
+// Stopped returns whether the handler was stopped
+func (h *ResourceEventHandler) Stopped() bool {
+	h.setup()
+	return h.queue.ShuttingDown()
+}
+
+func (h *ResourceEventHandler) setup() {
+	h.init.Do(func() {
+		h.queue = workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+		h.pending = map[string]*policyEvent{}
+	})
+}
+
+// enqueue records the last known state of the object and queues it to be synced
+func (h *ResourceEventHandler) enqueue(reqObj interface{}, deleted bool) {
+	h.setup()
+	key, err := cache.MetaNamespaceKeyFunc(reqObj)
 	if err != nil {
-		h.Log.Error(err, "failed to build policy from watched object", "object", obj)
+		h.Log.Error(err, "failed to get key of watched object", "object", reqObj)
 		return
 	}
 
-	if err := h.Syncer.SyncPolicy(ctx, h.Client, policy); err != nil {
-		h.Log.Error(err, "failed to sync policy", "policy", policy)
-	}
+	h.mu.Lock()
+	h.pending[key] = &policyEvent{obj: reqObj, deleted: deleted}
+	h.mu.Unlock()
+	h.queue.Add(key)
 }
 
-func (h *ResourceEventHandler) OnDelete(obj interface{}) {
-	h.Log.Info("Got watch event for policy", "obj", obj)
-}
+// processNextItem syncs the next queued policy, requeueing it with backoff when
+// it fails. It returns false once the queue is shut down
+func (h *ResourceEventHandler) processNextItem(ctx context.Context) bool {
+	item, shutdown := h.queue.Get()
+	if shutdown {
+		return false
+	}
+	defer h.queue.Done(item)
+	key := item.(string)
 
-func (h *ResourceEventHandler) OnUpdate(_ interface{}, reqObj interface{}) {
-	h.Log.Info("Got watch event for policy", "obj", reqObj)
+	h.mu.Lock()
+	event, ok := h.pending[key]
+	h.mu.Unlock()
+	if !ok {
+		h.queue.Forget(key)
+		return true
+	}
 
-	ctx := context.Background()
+	if err := h.sync(ctx, event); err != nil {
+		h.Log.Error(err, "failed to sync policy, requeueing", "key", key)
+		h.queue.AddRateLimited(key)
+		return true
+	}
+
+	h.queue.Forget(key)
+	h.mu.Lock()
+	// a newer event queued while this one was synced is kept
+	if h.pending[key] == event {
+		delete(h.pending, key)
+	}
+	h.mu.Unlock()
+	return true
+}
 
-	obj, ok := reqObj.(client.Object)
+func (h *ResourceEventHandler) sync(ctx context.Context, event *policyEvent) error {
+	cached, ok := event.obj.(client.Object)
 	if !ok {
-		h.Log.Error(fmt.Errorf("object %v does not inplement client.Object", reqObj), "")
-		return
+		h.Log.Error(fmt.Errorf("object %v does not inplement client.Object", event.obj), "")
+		return nil
+	}
+	// the object is shared with the informer cache, so it's copied before the latest
+	// state is read into it
+	obj := cached.DeepCopyObject().(client.Object)
+
+	if event.deleted {
+		policy, err := NewPolicyFor(obj)
+		if err != nil {
+			h.Log.Error(err, "failed to build policy from watched object", "object", obj)
+			return nil
+		}
+		return h.Syncer.RemovePolicy(ctx, h.Client, policy)
 	}
 
 	if err := h.Client.Get(ctx, client.ObjectKeyFromObject(obj), obj); err != nil {
-		h.Log.Error(err, "failed to get object", "object", obj)
+		// a policy that is gone is removed by its delete event
+		return client.IgnoreNotFound(err)
 	}
 
 	policy, err := NewPolicyFor(obj)
 	if err != nil {
 		h.Log.Error(err, "failed to build policy from watched object", "object", obj)
-		return
+		return nil
 	}
 
-	if err := h.Syncer.SyncPolicy(ctx, h.Client, policy); err != nil {
-		h.Log.Error(err, "failed to sync policy", "policy", policy)
+	// a deleting policy is removed from the spokes before its finalizer is
+	// released, so a restart part way through the deletion will pick it up again
+	if obj.GetDeletionTimestamp() != nil {
+		return h.Syncer.RemovePolicy(ctx, h.Client, policy)
 	}
+
+	return h.Syncer.SyncPolicy(ctx, h.Client, policy)
 }
@@ -0,0 +1,147 @@
+package policysync
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	crlog "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+type recordingSyncer struct {
+	synced  []string
+	removed []string
+	// failures is the number of syncs that fail before they succeed
+	failures int
+}
+
+func (s *recordingSyncer) SyncPolicy(_ context.Context, _ client.Client, policy Policy) error {
+	if s.failures > 0 {
+		s.failures--
+		return errors.New("sync failed")
+	}
+	s.synced = append(s.synced, policy.GetName())
+	return nil
+}
+
+func (s *recordingSyncer) RemovePolicy(_ context.Context, _ client.Client, policy Policy) error {
+	s.removed = append(s.removed, policy.GetName())
+	return nil
+}
+
+func TestResourceEventHandler_OnDelete(t *testing.T) {
+	testCases := []struct {
+		name string
+		obj  interface{}
+	}{
+		{
+			name: "deleted policy is removed",
+			obj:  testPolicy("Gateway"),
+		},
+		{
+			name: "deleted policy with unknown final state is removed",
+			obj: cache.DeletedFinalStateUnknown{
+				Key: "test-ns/test-policy",
+				Obj: testPolicy("Gateway"),
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			syncer := &recordingSyncer{}
+			handler := &ResourceEventHandler{
+				Log:    crlog.Log,
+				Client: fake.NewClientBuilder().WithScheme(testScheme()).Build(),
+				Syncer: syncer,
+			}
+			handler.OnDelete(testCase.obj)
+			handler.processNextItem(context.TODO())
+			if len(syncer.removed) != 1 || syncer.removed[0] != "test-policy" {
+				t.Errorf("expected test-policy to be removed, got %v", syncer.removed)
+			}
+		})
+	}
+}
+
+func TestResourceEventHandler_OnUpdateDeleting(t *testing.T) {
+	policy := testPolicy("Gateway")
+	policy.SetFinalizers([]string{PolicySyncFinalizer})
+	c := fake.NewClientBuilder().WithScheme(testScheme()).WithObjects(policy.DeepCopy()).Build()
+	if err := c.Delete(context.TODO(), policy.DeepCopy()); err != nil {
+		t.Fatal(err)
+	}
+
+	syncer := &recordingSyncer{}
+	handler := &ResourceEventHandler{
+		Log:    crlog.Log,
+		Client: c,
+		Syncer: syncer,
+	}
+	handler.OnUpdate(nil, policy)
+	handler.processNextItem(context.TODO())
+
+	if len(syncer.synced) != 0 {
+		t.Errorf("expected deleting policy not to be synced, got %v", syncer.synced)
+	}
+	if len(syncer.removed) != 1 {
+		t.Errorf("expected deleting policy to be removed, got %v", syncer.removed)
+	}
+}
+
+func TestResourceEventHandler_OnUpdateDoesNotMutateCache(t *testing.T) {
+	policy := testPolicy("Gateway")
+	stored := policy.DeepCopy()
+	stored.SetLabels(map[string]string{"updated": "true"})
+	c := fake.NewClientBuilder().WithScheme(testScheme()).WithObjects(stored).Build()
+
+	syncer := &recordingSyncer{}
+	handler := &ResourceEventHandler{
+		Log:    crlog.Log,
+		Client: c,
+		Syncer: syncer,
+	}
+	handler.OnUpdate(nil, policy)
+	handler.processNextItem(context.TODO())
+
+	if len(syncer.synced) != 1 {
+		t.Errorf("expected policy to be synced, got %v", syncer.synced)
+	}
+	if policy.GetLabels() != nil || policy.GetResourceVersion() != "" {
+		t.Errorf("expected the cached object not to be mutated, got %v", policy)
+	}
+}
+
+func TestResourceEventHandler_RetriesFailedSync(t *testing.T) {
+	policy := testPolicy("Gateway")
+	c := fake.NewClientBuilder().WithScheme(testScheme()).WithObjects(policy.DeepCopy()).Build()
+
+	syncer := &recordingSyncer{failures: 1}
+	handler := &ResourceEventHandler{
+		Log:    crlog.Log,
+		Client: c,
+		Syncer: syncer,
+	}
+	handler.OnAdd(policy, false)
+
+	// the failed sync is requeued with backoff rather than dropped
+	handler.processNextItem(context.TODO())
+	if len(syncer.synced) != 0 {
+		t.Fatalf("expected the first sync to fail, got %v", syncer.synced)
+	}
+	handler.processNextItem(context.TODO())
+	if len(syncer.synced) != 1 || syncer.synced[0] != "test-policy" {
+		t.Fatalf("expected test-policy to be synced on retry, got %v", syncer.synced)
+	}
+	if handler.queue.Len() != 0 || len(handler.pending) != 0 {
+		t.Errorf("expected the synced policy to leave the queue, got %d queued and %v pending", handler.queue.Len(), handler.pending)
+	}
+
+	handler.Stop()
+	if !handler.Stopped() {
+		t.Errorf("expected handler to be stopped")
+	}
+}
@@ -0,0 +1,427 @@
+package policysync
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	workv1 "open-cluster-management.io/api/work/v1"
+
+	rbac "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	crlog "sigs.k8s.io/controller-runtime/pkg/log"
+	gatewayapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayapiv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/placement"
+)
+
+const (
+	PolicyWorkLabel        = "kuadrant.io/policy"
+	PolicyParentAnnotation = "kuadrant.io/policy-parent"
+	PolicyKindAnnotation   = "kuadrant.io/policy-kind"
+	PolicySyncFinalizer    = "kuadrant.io/policy-sync"
+
+	policyAcceptedFeedback = "accepted"
+	policyEnforcedFeedback = "enforced"
+	policyRBACName         = "open-cluster-management:klusterlet-work:policy:%s"
+	policyRBACWork         = "policy-rbac-%s"
+)
+
+// PlacedClustersGetter returns the clusters a gateway has been placed on
+type PlacedClustersGetter interface {
+	GetPlacedClusters(ctx context.Context, gateway *gatewayapiv1.Gateway) (sets.Set[string], error)
+}
+
+// ManifestWorkSyncer syncs policies that target a multicluster gateway into
+// each of the clusters that gateway has been placed on via OCM ManifestWork.
+// The targetRef of the synced policy is rewritten to point to the downstream
+// gateway
+type ManifestWorkSyncer struct {
+	Placer PlacedClustersGetter
+	// GVR is the resource of the policies synced, which the work agent on the
+	// spokes is allowed to manage and reports the status of
+	GVR schema.GroupVersionResource
+	// DownstreamNamespace returns the namespace the downstream gateway is
+	// placed into for the given upstream gateway
+	DownstreamNamespace func(ctx context.Context, apiclient client.Client, upstream *gatewayapiv1.Gateway) (string, error)
+	// WorkAgentSubject is the subject of the work agent on the spokes allowed to
+	// manage the policies. Defaults to the placement.DefaultWorkAgentSubject
+	WorkAgentSubject rbac.Subject
+}
+
+var _ Syncer = &ManifestWorkSyncer{}
+
+func (s *ManifestWorkSyncer) SyncPolicy(ctx context.Context, apiclient client.Client, policy Policy) error {
+	log := crlog.FromContext(ctx)
+
+	targetRef := policy.GetTargetRef()
+	if !isGatewayTargetRef(targetRef) {
+		log.V(3).Info("policy does not target a gateway, skipping sync", "policy", policy.GetName(), "targetRef", targetRef)
+		return nil
+	}
+
+	upstream := &gatewayapiv1.Gateway{}
+	if err := apiclient.Get(ctx, client.ObjectKey{Name: string(targetRef.Name), Namespace: targetNamespace(policy, targetRef)}, upstream); err != nil {
+		if !k8serrors.IsNotFound(err) {
+			return err
+		}
+		// the gateway is gone, so the policy should not be synced anywhere
+		upstream = nil
+	}
+
+	placed := sets.New[string]()
+	if upstream != nil && upstream.GetDeletionTimestamp() == nil {
+		clusters, err := s.Placer.GetPlacedClusters(ctx, upstream)
+		if err != nil {
+			return err
+		}
+		placed = clusters
+	}
+
+	source, err := toUnstructured(policy)
+	if err != nil {
+		return err
+	}
+	// the namespace of the downstream gateway is only resolved when the policy is synced
+	downstreamNS := source.GetNamespace()
+	if upstream != nil && placed.Len() > 0 {
+		if downstreamNS, err = s.DownstreamNamespace(ctx, apiclient, upstream); err != nil {
+			return err
+		}
+	}
+	downstream, err := s.downstreamPolicy(source, downstreamNS)
+	if err != nil {
+		return err
+	}
+
+	workname := placement.WorkName(source)
+	if placed.Len() > 0 {
+		// track the synced copies so they are removed before the policy is deleted
+		if err := s.ensureFinalizer(ctx, apiclient, source, true); err != nil {
+			return err
+		}
+	}
+	for _, cluster := range sets.List(placed) {
+		log.V(3).Info("syncing policy to cluster", "policy", policy.GetName(), "cluster", cluster)
+		if err := s.policyRBAC(ctx, apiclient, cluster); err != nil {
+			return err
+		}
+		if err := s.createUpdateWork(ctx, apiclient, s.buildWork(workname, cluster, policy, downstream)); err != nil {
+			return fmt.Errorf("failed to sync policy %s to cluster %s: %w", policy.GetName(), cluster, err)
+		}
+	}
+
+	// remove the policy from any cluster the gateway is no longer placed on
+	synced, err := SyncedClusters(ctx, apiclient, workname)
+	if err != nil {
+		return err
+	}
+	for _, cluster := range sets.List(synced.Difference(placed)) {
+		log.V(3).Info("removing synced policy from cluster", "policy", policy.GetName(), "cluster", cluster)
+		w := &workv1.ManifestWork{ObjectMeta: metav1.ObjectMeta{Name: workname, Namespace: cluster}}
+		if err := apiclient.Delete(ctx, w); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+		if err := s.removePolicyRBAC(ctx, apiclient, cluster, workname); err != nil {
+			return err
+		}
+	}
+
+	if placed.Len() == 0 {
+		return removeSyncedCondition(ctx, apiclient, source)
+	}
+	return nil
+}
+
+// RemovePolicy deletes the policy from every cluster it was synced to and
+// releases the policy sync finalizer once they are all gone
+func (s *ManifestWorkSyncer) RemovePolicy(ctx context.Context, apiclient client.Client, policy Policy) error {
+	log := crlog.FromContext(ctx)
+
+	source, err := toUnstructured(policy)
+	if err != nil {
+		return err
+	}
+
+	workname := placement.WorkName(source)
+	synced, err := SyncedClusters(ctx, apiclient, workname)
+	if err != nil {
+		return err
+	}
+	for _, cluster := range sets.List(synced) {
+		log.V(3).Info("removing deleted policy from cluster", "policy", policy.GetName(), "cluster", cluster)
+		w := &workv1.ManifestWork{ObjectMeta: metav1.ObjectMeta{Name: workname, Namespace: cluster}}
+		if err := apiclient.Delete(ctx, w); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+		if err := s.removePolicyRBAC(ctx, apiclient, cluster, workname); err != nil {
+			return err
+		}
+	}
+
+	return s.ensureFinalizer(ctx, apiclient, source, false)
+}
+
+// ensureFinalizer adds or removes the policy sync finalizer from the hub policy
+func (s *ManifestWorkSyncer) ensureFinalizer(ctx context.Context, apiclient client.Client, policy *unstructured.Unstructured, present bool) error {
+	if controllerutil.ContainsFinalizer(policy, PolicySyncFinalizer) == present {
+		return nil
+	}
+	if present {
+		controllerutil.AddFinalizer(policy, PolicySyncFinalizer)
+	} else {
+		controllerutil.RemoveFinalizer(policy, PolicySyncFinalizer)
+	}
+	return client.IgnoreNotFound(apiclient.Update(ctx, policy))
+}
+
+// SyncedClusters returns the clusters that have a ManifestWork with the given name
+// syncing a policy into them
+func SyncedClusters(ctx context.Context, apiclient client.Client, workname string) (sets.Set[string], error) {
+	existing := &workv1.ManifestWorkList{}
+	clusters := sets.New[string]()
+	if err := apiclient.List(ctx, existing, client.MatchingLabels{placement.WorkManifestLabel: workname}); err != nil {
+		return clusters, err
+	}
+	for _, w := range existing.Items {
+		clusters.Insert(w.Namespace)
+	}
+	return clusters, nil
+}
+
+// downstreamPolicy builds the object to be applied into the spokes, with the
+// targetRef pointing to the downstream gateway in the downstream namespace
+func (s *ManifestWorkSyncer) downstreamPolicy(source *unstructured.Unstructured, downstreamNS string) (*unstructured.Unstructured, error) {
+	if source.GetKind() == "" || source.GetAPIVersion() == "" {
+		return nil, fmt.Errorf("policy %s/%s is missing apiVersion or kind", source.GetNamespace(), source.GetName())
+	}
+
+	downstream := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	downstream.SetAPIVersion(source.GetAPIVersion())
+	downstream.SetKind(source.GetKind())
+	downstream.SetName(source.GetName())
+	downstream.SetNamespace(downstreamNS)
+	downstream.SetLabels(source.GetLabels())
+	downstream.SetAnnotations(source.GetAnnotations())
+	downstream.Object["spec"] = runtime.DeepCopyJSONValue(source.Object["spec"])
+
+	// the downstream gateway keeps the upstream name, only the namespace changes
+	if err := unstructured.SetNestedField(downstream.Object, downstreamNS, "spec", "targetRef", "namespace"); err != nil {
+		return nil, err
+	}
+
+	return downstream, nil
+}
+
+func (s *ManifestWorkSyncer) buildWork(workname, cluster string, policy Policy, downstream *unstructured.Unstructured) workv1.ManifestWork {
+	gvk := downstream.GroupVersionKind()
+	return workv1.ManifestWork{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      workname,
+			Namespace: cluster,
+			Labels: map[string]string{
+				"kuadrant.io":               "managed",
+				placement.WorkManifestLabel: workname,
+				PolicyWorkLabel:             "true",
+			},
+			Annotations: map[string]string{
+				PolicyParentAnnotation: fmt.Sprintf("%s/%s", policy.GetNamespace(), policy.GetName()),
+				PolicyKindAnnotation:   fmt.Sprintf("%s.%s.%s", gvk.Kind, gvk.Version, gvk.Group),
+			},
+		},
+		Spec: workv1.ManifestWorkSpec{
+			Workload: workv1.ManifestsTemplate{
+				Manifests: []workv1.Manifest{
+					{RawExtension: runtime.RawExtension{Object: downstream}},
+				},
+			},
+			ManifestConfigs: []workv1.ManifestConfigOption{
+				{
+					ResourceIdentifier: workv1.ResourceIdentifier{
+						Group:     gvk.Group,
+						Resource:  s.GVR.Resource,
+						Name:      downstream.GetName(),
+						Namespace: downstream.GetNamespace(),
+					},
+					FeedbackRules: []workv1.FeedbackRule{
+						{
+							Type: workv1.JSONPathsType,
+							JsonPaths: []workv1.JsonPath{
+								{
+									Name: policyAcceptedFeedback,
+									Path: `.status.conditions[?(@.type=="Accepted")].status`,
+								},
+								{
+									Name: policyEnforcedFeedback,
+									Path: `.status.conditions[?(@.type=="Enforced")].status`,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// policyRBAC ensures the work agent on the spoke is allowed to manage the
+// policy resource
+func (s *ManifestWorkSyncer) policyRBAC(ctx context.Context, apiclient client.Client, cluster string) error {
+	subject := s.WorkAgentSubject
+	if subject.Name == "" {
+		subject = placement.DefaultWorkAgentSubject
+	}
+	resource := s.GVR.GroupResource().String()
+	name := fmt.Sprintf(policyRBACName, resource)
+
+	cr := &rbac.ClusterRole{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "rbac.authorization.k8s.io/v1",
+			Kind:       "ClusterRole",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Rules: []rbac.PolicyRule{
+			{
+				Verbs:     []string{"get", "list", "watch", "create", "update", "patch", "delete"},
+				APIGroups: []string{s.GVR.Group},
+				Resources: []string{s.GVR.Resource},
+			},
+		},
+	}
+	crb := &rbac.ClusterRoleBinding{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "rbac.authorization.k8s.io/v1",
+			Kind:       "ClusterRoleBinding",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		RoleRef: rbac.RoleRef{
+			APIGroup: "rbac.authorization.k8s.io",
+			Kind:     "ClusterRole",
+			Name:     name,
+		},
+		Subjects: []rbac.Subject{subject},
+	}
+
+	return s.createUpdateWork(ctx, apiclient, workv1.ManifestWork{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf(policyRBACWork, resource),
+			Namespace: cluster,
+		},
+		Spec: workv1.ManifestWorkSpec{
+			Workload: workv1.ManifestsTemplate{
+				Manifests: []workv1.Manifest{
+					{RawExtension: runtime.RawExtension{Object: cr}},
+					{RawExtension: runtime.RawExtension{Object: crb}},
+				},
+			},
+		},
+	})
+}
+
+// removePolicyRBAC removes the RBAC of the work agent for the policy resource from the
+// cluster once no other policy of the resource is synced to it
+func (s *ManifestWorkSyncer) removePolicyRBAC(ctx context.Context, apiclient client.Client, cluster, workname string) error {
+	works := &workv1.ManifestWorkList{}
+	if err := apiclient.List(ctx, works, client.InNamespace(cluster), client.MatchingLabels{PolicyWorkLabel: "true"}); err != nil {
+		return err
+	}
+	for _, work := range works.Items {
+		if work.Name == workname || work.DeletionTimestamp != nil {
+			continue
+		}
+		for _, config := range work.Spec.ManifestConfigs {
+			if config.ResourceIdentifier.Group == s.GVR.Group && config.ResourceIdentifier.Resource == s.GVR.Resource {
+				return nil
+			}
+		}
+	}
+	rbacWork := &workv1.ManifestWork{ObjectMeta: metav1.ObjectMeta{
+		Name:      fmt.Sprintf(policyRBACWork, s.GVR.GroupResource().String()),
+		Namespace: cluster,
+	}}
+	return client.IgnoreNotFound(apiclient.Delete(ctx, rbacWork))
+}
+
+func (s *ManifestWorkSyncer) createUpdateWork(ctx context.Context, apiclient client.Client, work workv1.ManifestWork) error {
+	if err := marshalManifests(&work); err != nil {
+		return err
+	}
+
+	existing := &workv1.ManifestWork{}
+	if err := apiclient.Get(ctx, client.ObjectKeyFromObject(&work), existing); err != nil {
+		if !k8serrors.IsNotFound(err) {
+			return err
+		}
+		return apiclient.Create(ctx, &work)
+	}
+
+	if placement.ManifestWorkSpecEqual(existing.Spec, work.Spec) && equality.Semantic.DeepEqual(existing.Labels, work.Labels) {
+		return nil
+	}
+	existing.Spec = work.Spec
+	existing.Labels = work.Labels
+	existing.Annotations = work.Annotations
+	return apiclient.Update(ctx, existing)
+}
+
+// marshalManifests serialises the manifest objects into their raw form so
+// that the spec can be compared with the stored ManifestWork. Objects are
+// converted to unstructured first so the keys are always in the same order
+func marshalManifests(work *workv1.ManifestWork) error {
+	for i, m := range work.Spec.Workload.Manifests {
+		if m.Object == nil {
+			continue
+		}
+		obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(m.Object)
+		if err != nil {
+			return err
+		}
+		raw, err := json.Marshal(obj)
+		if err != nil {
+			return err
+		}
+		work.Spec.Workload.Manifests[i] = workv1.Manifest{RawExtension: runtime.RawExtension{Raw: raw}}
+	}
+	return nil
+}
+
+func toUnstructured(policy Policy) (*unstructured.Unstructured, error) {
+	switch p := policy.(type) {
+	case *UnstructuredPolicy:
+		return p.Unstructured, nil
+	case *ReflectPolicy:
+		obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(p.Object)
+		if err != nil {
+			return nil, err
+		}
+		return &unstructured.Unstructured{Object: obj}, nil
+	default:
+		return nil, fmt.Errorf("unsupported policy type %T", policy)
+	}
+}
+
+func isGatewayTargetRef(targetRef *gatewayapiv1alpha2.PolicyTargetReference) bool {
+	return targetRef != nil &&
+		targetRef.Group == gatewayapiv1.GroupName &&
+		targetRef.Kind == "Gateway"
+}
+
+func targetNamespace(policy Policy, targetRef *gatewayapiv1alpha2.PolicyTargetReference) string {
+	if targetRef.Namespace != nil && *targetRef.Namespace != "" {
+		return string(*targetRef.Namespace)
+	}
+	return policy.GetNamespace()
+}
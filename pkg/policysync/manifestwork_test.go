@@ -0,0 +1,325 @@
+package policysync
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	workv1 "open-cluster-management.io/api/work/v1"
+
+	rbac "k8s.io/api/rbac/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	gatewayapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/placement"
+)
+
+type fakePlacer struct {
+	clusters []string
+}
+
+func (p *fakePlacer) GetPlacedClusters(_ context.Context, _ *gatewayapiv1.Gateway) (sets.Set[string], error) {
+	return sets.New(p.clusters...), nil
+}
+
+func testScheme() *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	_ = gatewayapiv1.AddToScheme(scheme)
+	_ = workv1.AddToScheme(scheme)
+	return scheme
+}
+
+func testPolicy(targetKind string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "kuadrant.io/v1beta2",
+			"kind":       "AuthPolicy",
+			"metadata": map[string]interface{}{
+				"name":      "test-policy",
+				"namespace": "test-ns",
+				"uid":       "1234",
+			},
+			"spec": map[string]interface{}{
+				"targetRef": map[string]interface{}{
+					"group": gatewayapiv1.GroupName,
+					"kind":  targetKind,
+					"name":  "test-gateway",
+				},
+			},
+			"status": map[string]interface{}{
+				"conditions": []interface{}{},
+			},
+		},
+	}
+}
+
+func syncedTestPolicy() *unstructured.Unstructured {
+	policy := testPolicy("Gateway")
+	_, _ = setPolicyCondition(policy, metav1.Condition{Type: string(PolicyConditionSynced), Status: metav1.ConditionTrue, Reason: "Accepted"})
+	return policy
+}
+
+var authPolicyGVR = schema.GroupVersionResource{Group: "kuadrant.io", Version: "v1beta2", Resource: "authpolicies"}
+
+func testGateway() *gatewayapiv1.Gateway {
+	return &gatewayapiv1.Gateway{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-gateway",
+			Namespace: "test-ns",
+		},
+	}
+}
+
+func TestManifestWorkSyncer_SyncPolicy(t *testing.T) {
+	workname := "authpolicy-test-ns-test-policy"
+
+	testCases := []struct {
+		name     string
+		policy   *unstructured.Unstructured
+		clusters []string
+		objects  []client.Object
+		verify   func(t *testing.T, c client.Client, err error)
+	}{
+		{
+			name:     "policy synced to every placed cluster with rewritten targetRef",
+			policy:   testPolicy("Gateway"),
+			clusters: []string{"cluster-1", "cluster-2"},
+			objects:  []client.Object{testGateway()},
+			verify: func(t *testing.T, c client.Client, err error) {
+				if err != nil {
+					t.Fatalf("unexpected error %v", err)
+				}
+				for _, cluster := range []string{"cluster-1", "cluster-2"} {
+					work := &workv1.ManifestWork{}
+					if err := c.Get(context.TODO(), client.ObjectKey{Name: workname, Namespace: cluster}, work); err != nil {
+						t.Fatalf("expected manifest work in cluster %s: %v", cluster, err)
+					}
+					if work.Labels[placement.WorkManifestLabel] != workname {
+						t.Errorf("expected work label %s, got %v", workname, work.Labels)
+					}
+					if len(work.Spec.Workload.Manifests) != 1 {
+						t.Fatalf("expected 1 manifest, got %d", len(work.Spec.Workload.Manifests))
+					}
+					synced := &unstructured.Unstructured{}
+					if err := json.Unmarshal(work.Spec.Workload.Manifests[0].Raw, &synced.Object); err != nil {
+						t.Fatal(err)
+					}
+					if synced.GetNamespace() != "kuadrant-test-ns" {
+						t.Errorf("expected synced policy namespace kuadrant-test-ns, got %s", synced.GetNamespace())
+					}
+					if synced.GetUID() != "" {
+						t.Errorf("expected uid to be stripped, got %s", synced.GetUID())
+					}
+					if _, ok := synced.Object["status"]; ok {
+						t.Errorf("expected status to be stripped")
+					}
+					ns, _, _ := unstructured.NestedString(synced.Object, "spec", "targetRef", "namespace")
+					if ns != "kuadrant-test-ns" {
+						t.Errorf("expected targetRef namespace kuadrant-test-ns, got %s", ns)
+					}
+				}
+			},
+		},
+		{
+			name:     "policy removed from clusters the gateway is no longer placed on",
+			policy:   testPolicy("Gateway"),
+			clusters: []string{"cluster-1"},
+			objects: []client.Object{
+				testGateway(),
+				&workv1.ManifestWork{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      workname,
+						Namespace: "cluster-2",
+						Labels:    map[string]string{placement.WorkManifestLabel: workname},
+					},
+				},
+			},
+			verify: func(t *testing.T, c client.Client, err error) {
+				if err != nil {
+					t.Fatalf("unexpected error %v", err)
+				}
+				synced, err := SyncedClusters(context.TODO(), c, workname)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !synced.Equal(sets.New("cluster-1")) {
+					t.Errorf("expected policy to be synced only to cluster-1, got %v", sets.List(synced))
+				}
+			},
+		},
+		{
+			name:     "synced condition removed from policy no longer synced to any cluster",
+			policy:   syncedTestPolicy(),
+			clusters: []string{},
+			objects:  []client.Object{testGateway(), syncedTestPolicy()},
+			verify: func(t *testing.T, c client.Client, err error) {
+				if err != nil {
+					t.Fatalf("unexpected error %v", err)
+				}
+				policy := syncedTestPolicy()
+				if err := c.Get(context.TODO(), client.ObjectKeyFromObject(policy), policy); err != nil {
+					t.Fatal(err)
+				}
+				conditions, err := getPolicyConditions(policy)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(conditions) != 0 {
+					t.Errorf("expected synced condition to be removed, got %v", conditions)
+				}
+			},
+		},
+		{
+			name:     "policy not targeting a gateway is not synced",
+			policy:   testPolicy("HTTPRoute"),
+			clusters: []string{"cluster-1"},
+			objects:  []client.Object{testGateway()},
+			verify: func(t *testing.T, c client.Client, err error) {
+				if err != nil {
+					t.Fatalf("unexpected error %v", err)
+				}
+				works := &workv1.ManifestWorkList{}
+				if err := c.List(context.TODO(), works); err != nil {
+					t.Fatal(err)
+				}
+				if len(works.Items) != 0 {
+					t.Errorf("expected no manifest works, got %d", len(works.Items))
+				}
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := fake.NewClientBuilder().WithScheme(testScheme()).WithStatusSubresource(testPolicy("Gateway")).WithObjects(testCase.objects...).Build()
+			syncer := &ManifestWorkSyncer{
+				Placer: &fakePlacer{clusters: testCase.clusters},
+				GVR:    authPolicyGVR,
+				DownstreamNamespace: func(_ context.Context, _ client.Client, upstream *gatewayapiv1.Gateway) (string, error) {
+					return fmt.Sprintf("kuadrant-%s", upstream.Namespace), nil
+				},
+			}
+			policy, err := NewPolicyFor(testCase.policy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = syncer.SyncPolicy(context.TODO(), c, policy)
+			testCase.verify(t, c, err)
+		})
+	}
+}
+
+func TestManifestWorkSyncer_PolicyResource(t *testing.T) {
+	c := fake.NewClientBuilder().WithScheme(testScheme()).WithObjects(testGateway()).Build()
+	placer := &fakePlacer{clusters: []string{"cluster-1"}}
+	workAgent := rbac.Subject{Kind: "ServiceAccount", Name: "work-agent", Namespace: "custom-agent"}
+	syncer := &ManifestWorkSyncer{
+		Placer: placer,
+		GVR:    authPolicyGVR,
+		DownstreamNamespace: func(_ context.Context, _ client.Client, upstream *gatewayapiv1.Gateway) (string, error) {
+			return upstream.Namespace, nil
+		},
+		WorkAgentSubject: workAgent,
+	}
+	policy, err := NewPolicyFor(testPolicy("Gateway"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := syncer.SyncPolicy(context.TODO(), c, policy); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	// the status of the AuthPolicy is fed back from the authpolicies resource
+	work := &workv1.ManifestWork{}
+	if err := c.Get(context.TODO(), client.ObjectKey{Name: "authpolicy-test-ns-test-policy", Namespace: "cluster-1"}, work); err != nil {
+		t.Fatal(err)
+	}
+	if len(work.Spec.ManifestConfigs) != 1 || work.Spec.ManifestConfigs[0].ResourceIdentifier.Resource != "authpolicies" {
+		t.Errorf("expected status feedback for authpolicies, got %v", work.Spec.ManifestConfigs)
+	}
+
+	// the work agent is allowed to manage the authpolicies resource
+	rbacWork := &workv1.ManifestWork{}
+	if err := c.Get(context.TODO(), client.ObjectKey{Name: "policy-rbac-authpolicies.kuadrant.io", Namespace: "cluster-1"}, rbacWork); err != nil {
+		t.Fatalf("expected policy rbac work: %v", err)
+	}
+	clusterRole := &rbac.ClusterRole{}
+	if err := json.Unmarshal(rbacWork.Spec.Workload.Manifests[0].Raw, clusterRole); err != nil {
+		t.Fatal(err)
+	}
+	if len(clusterRole.Rules) != 1 || clusterRole.Rules[0].Resources[0] != "authpolicies" || clusterRole.Rules[0].APIGroups[0] != "kuadrant.io" {
+		t.Errorf("expected rule for authpolicies.kuadrant.io, got %v", clusterRole.Rules)
+	}
+	clusterRoleBinding := &rbac.ClusterRoleBinding{}
+	if err := json.Unmarshal(rbacWork.Spec.Workload.Manifests[1].Raw, clusterRoleBinding); err != nil {
+		t.Fatal(err)
+	}
+	if len(clusterRoleBinding.Subjects) != 1 || clusterRoleBinding.Subjects[0] != workAgent {
+		t.Errorf("expected binding to the configured work agent, got %v", clusterRoleBinding.Subjects)
+	}
+
+	// the rbac is removed along with the last policy of the resource synced to the cluster
+	placer.clusters = nil
+	if err := syncer.SyncPolicy(context.TODO(), c, policy); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if err := c.Get(context.TODO(), client.ObjectKeyFromObject(rbacWork), rbacWork); !k8serrors.IsNotFound(err) {
+		t.Errorf("expected policy rbac work to be removed, got %v", err)
+	}
+}
+
+func TestManifestWorkSyncer_RemovePolicy(t *testing.T) {
+	workname := "authpolicy-test-ns-test-policy"
+
+	policy := testPolicy("Gateway")
+	policy.SetFinalizers([]string{PolicySyncFinalizer})
+
+	objects := []client.Object{policy.DeepCopy()}
+	for _, cluster := range []string{"cluster-1", "cluster-2"} {
+		objects = append(objects, &workv1.ManifestWork{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      workname,
+				Namespace: cluster,
+				Labels:    map[string]string{placement.WorkManifestLabel: workname},
+			},
+		})
+	}
+	c := fake.NewClientBuilder().WithScheme(testScheme()).WithObjects(objects...).Build()
+
+	if err := c.Get(context.TODO(), client.ObjectKeyFromObject(policy), policy); err != nil {
+		t.Fatal(err)
+	}
+	p, err := NewPolicyFor(policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	syncer := &ManifestWorkSyncer{Placer: &fakePlacer{}}
+	if err := syncer.RemovePolicy(context.TODO(), c, p); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	synced, err := SyncedClusters(context.TODO(), c, workname)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if synced.Len() != 0 {
+		t.Errorf("expected policy to be removed from all clusters, still synced to %v", sets.List(synced))
+	}
+
+	updated := testPolicy("Gateway")
+	if err := c.Get(context.TODO(), client.ObjectKeyFromObject(updated), updated); err != nil {
+		t.Fatal(err)
+	}
+	if len(updated.GetFinalizers()) != 0 {
+		t.Errorf("expected policy sync finalizer to be removed, got %v", updated.GetFinalizers())
+	}
+}
@@ -41,6 +41,11 @@ func (p *PolicyInformersManager) AddInformer(informer cache.SharedIndexInformer)
 	return p.manager.Add(&InformerRunnable{Informer: informer})
 }
 
+// AddHandler starts the worker of the event handler with the manager
+func (p *PolicyInformersManager) AddHandler(handler *ResourceEventHandler) error {
+	return p.manager.Add(handler)
+}
+
 type InformerRunnable struct {
 	Informer cache.SharedIndexInformer
 }
@@ -0,0 +1,277 @@
+package policysync
+
+import (
+	"context"
+	"fmt"
+
+	workv1 "open-cluster-management.io/api/work/v1"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/cache"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	crlog "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/conditions"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/_internal/slice"
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/placement"
+)
+
+const (
+	PolicyConditionSynced conditions.ConditionType = "kuadrant.io/Synced"
+)
+
+// ClusterSyncStatus is the state of a synced policy across the spoke clusters
+type ClusterSyncStatus struct {
+	Accepted sets.Set[string]
+	Enforced sets.Set[string]
+	Failed   sets.Set[string]
+	Pending  sets.Set[string]
+}
+
+// StatusReconciler aggregates the status of synced policies reported back
+// through ManifestWork status feedback onto the hub policy. Requests are
+// keyed by the name of the ManifestWork the policy is synced with
+type StatusReconciler struct {
+	Client client.Client
+}
+
+func (r *StatusReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := crlog.FromContext(ctx)
+
+	works := &workv1.ManifestWorkList{}
+	if err := r.Client.List(ctx, works, client.MatchingLabels{placement.WorkManifestLabel: req.Name}); err != nil {
+		return ctrl.Result{}, err
+	}
+	if len(works.Items) == 0 {
+		return ctrl.Result{}, nil
+	}
+
+	policy, err := policyForWork(&works.Items[0])
+	if err != nil {
+		log.Error(err, "unable to find policy for manifest work", "work", req.Name)
+		return ctrl.Result{}, nil
+	}
+	if err := r.Client.Get(ctx, client.ObjectKeyFromObject(policy), policy); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if policy.GetDeletionTimestamp() != nil {
+		return ctrl.Result{}, nil
+	}
+
+	// the policy has no status to report once it is removed from every cluster
+	if !slice.Contains(works.Items, func(w workv1.ManifestWork) bool { return w.DeletionTimestamp == nil }) {
+		log.V(3).Info("removing policy sync status", "policy", client.ObjectKeyFromObject(policy))
+		return ctrl.Result{}, removeSyncedCondition(ctx, r.Client, policy)
+	}
+
+	status := AggregateSyncStatus(works.Items)
+	condition := BuildSyncedCondition(policy.GetGeneration(), status)
+
+	changed, err := setPolicyCondition(policy, condition)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if !changed {
+		return ctrl.Result{}, nil
+	}
+
+	log.V(3).Info("updating policy sync status", "policy", client.ObjectKeyFromObject(policy), "condition", condition)
+	return ctrl.Result{}, r.Client.Status().Update(ctx, policy)
+}
+
+// AggregateSyncStatus works out the state of the policy in each cluster from
+// the ManifestWork conditions and status feedback
+func AggregateSyncStatus(works []workv1.ManifestWork) ClusterSyncStatus {
+	status := ClusterSyncStatus{
+		Accepted: sets.New[string](),
+		Enforced: sets.New[string](),
+		Failed:   sets.New[string](),
+		Pending:  sets.New[string](),
+	}
+
+	for _, work := range works {
+		cluster := work.Namespace
+		if work.DeletionTimestamp != nil {
+			continue
+		}
+
+		applied := meta.FindStatusCondition(work.Status.Conditions, workv1.WorkApplied)
+		if applied != nil && applied.Status == metav1.ConditionFalse {
+			status.Failed.Insert(cluster)
+			continue
+		}
+
+		feedback := map[string]string{}
+		for _, m := range work.Status.ResourceStatus.Manifests {
+			for _, value := range m.StatusFeedbacks.Values {
+				if value.Value.String != nil {
+					feedback[value.Name] = *value.Value.String
+				}
+			}
+		}
+
+		switch feedback[policyAcceptedFeedback] {
+		case string(metav1.ConditionTrue):
+			status.Accepted.Insert(cluster)
+		case string(metav1.ConditionFalse):
+			status.Failed.Insert(cluster)
+			continue
+		default:
+			status.Pending.Insert(cluster)
+		}
+
+		if feedback[policyEnforcedFeedback] == string(metav1.ConditionTrue) {
+			status.Enforced.Insert(cluster)
+		}
+	}
+
+	return status
+}
+
+// BuildSyncedCondition builds the condition set on the hub policy listing the
+// clusters the policy was accepted and enforced in, and those where it failed
+func BuildSyncedCondition(generation int64, status ClusterSyncStatus) metav1.Condition {
+	condition := metav1.Condition{
+		Type:               string(PolicyConditionSynced),
+		Status:             metav1.ConditionTrue,
+		Reason:             string(conditions.PolicyReasonAccepted),
+		ObservedGeneration: generation,
+	}
+
+	switch {
+	case status.Failed.Len() > 0:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = string(conditions.PolicyReasonInvalid)
+	case status.Pending.Len() > 0:
+		condition.Status = metav1.ConditionUnknown
+		condition.Reason = string(conditions.PolicyReasonUnknown)
+	}
+
+	condition.Message = fmt.Sprintf("accepted in clusters %v, enforced in clusters %v, failed in clusters %v, pending in clusters %v",
+		sets.List(status.Accepted), sets.List(status.Enforced), sets.List(status.Failed), sets.List(status.Pending))
+
+	return condition
+}
+
+// policyForWork returns an empty policy object referenced by the ManifestWork
+// annotations
+func policyForWork(work *workv1.ManifestWork) (*unstructured.Unstructured, error) {
+	annotations := work.GetAnnotations()
+	namespace, name, err := cache.SplitMetaNamespaceKey(annotations[PolicyParentAnnotation])
+	if err != nil {
+		return nil, err
+	}
+	gvk, _ := schema.ParseKindArg(annotations[PolicyKindAnnotation])
+	if gvk == nil || name == "" {
+		return nil, fmt.Errorf("manifest work %s/%s is missing policy annotations", work.Namespace, work.Name)
+	}
+
+	policy := &unstructured.Unstructured{}
+	policy.SetGroupVersionKind(*gvk)
+	policy.SetNamespace(namespace)
+	policy.SetName(name)
+	return policy, nil
+}
+
+// setPolicyCondition sets the condition in the status of the unstructured
+// policy, returning whether the conditions changed
+func setPolicyCondition(policy *unstructured.Unstructured, condition metav1.Condition) (bool, error) {
+	policyConditions, err := getPolicyConditions(policy)
+	if err != nil {
+		return false, err
+	}
+
+	updated := make([]metav1.Condition, len(policyConditions))
+	copy(updated, policyConditions)
+	meta.SetStatusCondition(&updated, condition)
+	if equality.Semantic.DeepEqual(updated, policyConditions) {
+		return false, nil
+	}
+
+	return true, setPolicyConditions(policy, updated)
+}
+
+// removePolicyCondition removes the condition from the status of the
+// unstructured policy, returning whether the conditions changed
+func removePolicyCondition(policy *unstructured.Unstructured, conditionType conditions.ConditionType) (bool, error) {
+	policyConditions, err := getPolicyConditions(policy)
+	if err != nil {
+		return false, err
+	}
+	if meta.FindStatusCondition(policyConditions, string(conditionType)) == nil {
+		return false, nil
+	}
+
+	meta.RemoveStatusCondition(&policyConditions, string(conditionType))
+	return true, setPolicyConditions(policy, policyConditions)
+}
+
+// removeSyncedCondition removes the Synced condition from the hub policy once
+// it is no longer synced to any cluster
+func removeSyncedCondition(ctx context.Context, apiclient client.Client, policy *unstructured.Unstructured) error {
+	changed, err := removePolicyCondition(policy, PolicyConditionSynced)
+	if err != nil || !changed {
+		return err
+	}
+	return client.IgnoreNotFound(apiclient.Status().Update(ctx, policy))
+}
+
+func getPolicyConditions(policy *unstructured.Unstructured) ([]metav1.Condition, error) {
+	existing, _, err := unstructured.NestedSlice(policy.Object, "status", "conditions")
+	if err != nil {
+		return nil, err
+	}
+
+	policyConditions := []metav1.Condition{}
+	for _, c := range existing {
+		obj, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		typed := metav1.Condition{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj, &typed); err != nil {
+			return nil, err
+		}
+		policyConditions = append(policyConditions, typed)
+	}
+	return policyConditions, nil
+}
+
+func setPolicyConditions(policy *unstructured.Unstructured, policyConditions []metav1.Condition) error {
+	converted := make([]interface{}, 0, len(policyConditions))
+	for i := range policyConditions {
+		obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&policyConditions[i])
+		if err != nil {
+			return err
+		}
+		converted = append(converted, obj)
+	}
+	return unstructured.SetNestedSlice(policy.Object, converted, "status", "conditions")
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *StatusReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("policysync-status").
+		Watches(&workv1.ManifestWork{}, handler.EnqueueRequestsFromMapFunc(func(_ context.Context, o client.Object) []reconcile.Request {
+			workname := o.GetLabels()[placement.WorkManifestLabel]
+			if workname == "" {
+				return []reconcile.Request{}
+			}
+			return []reconcile.Request{{NamespacedName: client.ObjectKey{Name: workname}}}
+		}), builder.WithPredicates(predicate.NewPredicateFuncs(func(o client.Object) bool {
+			return o.GetLabels()[PolicyWorkLabel] == "true"
+		}))).
+		Complete(r)
+}
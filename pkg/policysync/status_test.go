@@ -0,0 +1,151 @@
+package policysync
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	workv1 "open-cluster-management.io/api/work/v1"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/sets"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/Kuadrant/multicluster-gateway-controller/pkg/placement"
+)
+
+func testPolicyWork(cluster, accepted, enforced string, applied metav1.ConditionStatus) *workv1.ManifestWork {
+	workname := "authpolicy-test-ns-test-policy"
+	values := []workv1.FeedbackValue{}
+	if accepted != "" {
+		values = append(values, workv1.FeedbackValue{Name: policyAcceptedFeedback, Value: workv1.FieldValue{Type: workv1.String, String: &accepted}})
+	}
+	if enforced != "" {
+		values = append(values, workv1.FeedbackValue{Name: policyEnforcedFeedback, Value: workv1.FieldValue{Type: workv1.String, String: &enforced}})
+	}
+	return &workv1.ManifestWork{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      workname,
+			Namespace: cluster,
+			Labels: map[string]string{
+				placement.WorkManifestLabel: workname,
+				PolicyWorkLabel:             "true",
+			},
+			Annotations: map[string]string{
+				PolicyParentAnnotation: "test-ns/test-policy",
+				PolicyKindAnnotation:   "AuthPolicy.v1beta2.kuadrant.io",
+			},
+		},
+		Status: workv1.ManifestWorkStatus{
+			Conditions: []metav1.Condition{
+				{Type: workv1.WorkApplied, Status: applied},
+			},
+			ResourceStatus: workv1.ManifestResourceStatus{
+				Manifests: []workv1.ManifestCondition{
+					{StatusFeedbacks: workv1.StatusFeedbackResult{Values: values}},
+				},
+			},
+		},
+	}
+}
+
+func TestAggregateSyncStatus(t *testing.T) {
+	works := []workv1.ManifestWork{
+		*testPolicyWork("cluster-1", "True", "True", metav1.ConditionTrue),
+		*testPolicyWork("cluster-2", "True", "False", metav1.ConditionTrue),
+		*testPolicyWork("cluster-3", "False", "", metav1.ConditionTrue),
+		*testPolicyWork("cluster-4", "", "", metav1.ConditionFalse),
+		*testPolicyWork("cluster-5", "", "", metav1.ConditionTrue),
+	}
+
+	status := AggregateSyncStatus(works)
+
+	if !status.Accepted.Equal(sets.New("cluster-1", "cluster-2")) {
+		t.Errorf("unexpected accepted clusters %v", sets.List(status.Accepted))
+	}
+	if !status.Enforced.Equal(sets.New("cluster-1")) {
+		t.Errorf("unexpected enforced clusters %v", sets.List(status.Enforced))
+	}
+	if !status.Failed.Equal(sets.New("cluster-3", "cluster-4")) {
+		t.Errorf("unexpected failed clusters %v", sets.List(status.Failed))
+	}
+	if !status.Pending.Equal(sets.New("cluster-5")) {
+		t.Errorf("unexpected pending clusters %v", sets.List(status.Pending))
+	}
+
+	condition := BuildSyncedCondition(1, status)
+	if condition.Status != metav1.ConditionFalse {
+		t.Errorf("expected condition to be False with failed clusters, got %s", condition.Status)
+	}
+}
+
+func TestStatusReconciler_Reconcile(t *testing.T) {
+	policy := testPolicy("Gateway")
+	c := fake.NewClientBuilder().
+		WithScheme(testScheme()).
+		WithStatusSubresource(policy).
+		WithObjects(
+			policy.DeepCopy(),
+			testPolicyWork("cluster-1", "True", "True", metav1.ConditionTrue),
+			testPolicyWork("cluster-2", "True", "True", metav1.ConditionTrue),
+		).
+		Build()
+
+	r := &StatusReconciler{Client: c}
+	if _, err := r.Reconcile(context.TODO(), ctrl.Request{NamespacedName: client.ObjectKey{Name: "authpolicy-test-ns-test-policy"}}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	updated := &unstructured.Unstructured{}
+	updated.SetGroupVersionKind(policy.GroupVersionKind())
+	if err := c.Get(context.TODO(), client.ObjectKeyFromObject(policy), updated); err != nil {
+		t.Fatal(err)
+	}
+	existing, _, _ := unstructured.NestedSlice(updated.Object, "status", "conditions")
+	conditions := []metav1.Condition{}
+	for _, e := range existing {
+		obj := e.(map[string]interface{})
+		conditions = append(conditions, metav1.Condition{
+			Type:   obj["type"].(string),
+			Status: metav1.ConditionStatus(obj["status"].(string)),
+		})
+	}
+	if !meta.IsStatusConditionTrue(conditions, string(PolicyConditionSynced)) {
+		t.Errorf("expected synced condition to be true, got %v", conditions)
+	}
+}
+
+func TestStatusReconciler_RemovedFromClusters(t *testing.T) {
+	policy := testPolicy("Gateway")
+	_, _ = setPolicyCondition(policy, metav1.Condition{Type: string(PolicyConditionSynced), Status: metav1.ConditionTrue, Reason: "Accepted"})
+	work := testPolicyWork("cluster-1", "True", "True", metav1.ConditionTrue)
+	work.Finalizers = []string{"cluster.open-cluster-management.io/manifest-work-cleanup"}
+	work.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	c := fake.NewClientBuilder().
+		WithScheme(testScheme()).
+		WithStatusSubresource(policy).
+		WithObjects(policy.DeepCopy(), work).
+		Build()
+
+	r := &StatusReconciler{Client: c}
+	if _, err := r.Reconcile(context.TODO(), ctrl.Request{NamespacedName: client.ObjectKey{Name: "authpolicy-test-ns-test-policy"}}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	updated := &unstructured.Unstructured{}
+	updated.SetGroupVersionKind(policy.GroupVersionKind())
+	if err := c.Get(context.TODO(), client.ObjectKeyFromObject(policy), updated); err != nil {
+		t.Fatal(err)
+	}
+	conditions, err := getPolicyConditions(updated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.FindStatusCondition(conditions, string(PolicyConditionSynced)) != nil {
+		t.Errorf("expected synced condition to be removed, got %v", conditions)
+	}
+}
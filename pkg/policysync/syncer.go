@@ -9,6 +9,8 @@ import (
 
 type Syncer interface {
 	SyncPolicy(ctx context.Context, apiclient client.Client, policy Policy) error
+	// RemovePolicy removes the policy from every cluster it was synced to
+	RemovePolicy(ctx context.Context, apiclient client.Client, policy Policy) error
 }
 
 type FakeSyncer struct {
@@ -24,3 +26,11 @@ func (*FakeSyncer) SyncPolicy(ctx context.Context, _ client.Client, policy Polic
 
 	return nil
 }
+
+func (*FakeSyncer) RemovePolicy(ctx context.Context, _ client.Client, policy Policy) error {
+	log := crlog.FromContext(ctx)
+
+	log.Info("Removing policy", "policy", policy)
+
+	return nil
+}
@@ -206,6 +206,55 @@ var _ = Describe("Gateway single target cluster", func() {
 			}).WithContext(ctx).WithTimeout(180 * time.Second).WithPolling(10 * time.Second).ShouldNot(HaveOccurred())
 		})
 
+		When("an HTTPRoute is attached to the Gateway in the hub", func() {
+			var hubRoute *gatewayapiv1.HTTPRoute
+
+			BeforeEach(func(ctx SpecContext) {
+				By("attaching an HTTPRoute to the Gateway in the hub")
+
+				hubRoute = &gatewayapiv1.HTTPRoute{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      testID,
+						Namespace: tconfig.HubNamespace(),
+					},
+					Spec: gatewayapiv1.HTTPRouteSpec{
+						CommonRouteSpec: gatewayapiv1.CommonRouteSpec{
+							ParentRefs: []gatewayapiv1.ParentReference{{
+								Name: gatewayapiv1.ObjectName(testID),
+								Kind: Pointer(gatewayapiv1.Kind("Gateway")),
+							}},
+						},
+						Hostnames: []gatewayapiv1.Hostname{testHostname},
+					},
+				}
+
+				err := tconfig.HubClient().Create(ctx, hubRoute)
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			AfterEach(func(ctx SpecContext) {
+				err := tconfig.HubClient().Delete(ctx, hubRoute)
+				Expect(client.IgnoreNotFound(err)).ToNot(HaveOccurred())
+			})
+
+			It("is synced next to the downstream gateway and accepted by it", func(ctx SpecContext) {
+				Eventually(func(g Gomega, ctx context.Context) {
+					spokeRoute := &gatewayapiv1.HTTPRoute{}
+					err := tconfig.SpokeClient(0).Get(ctx, client.ObjectKey{Name: testID, Namespace: tconfig.SpokeNamespace()}, spokeRoute)
+					g.Expect(err).ToNot(HaveOccurred())
+					g.Expect(spokeRoute.Status.Parents).To(HaveLen(1))
+					g.Expect(meta.IsStatusConditionTrue(spokeRoute.Status.Parents[0].Conditions, string(gatewayapiv1.RouteConditionAccepted))).To(BeTrue())
+				}, 180*time.Second, 10*time.Second, ctx).Should(Succeed())
+
+				Eventually(func(g Gomega, ctx context.Context) {
+					err := tconfig.HubClient().Get(ctx, client.ObjectKeyFromObject(hubRoute), hubRoute)
+					g.Expect(err).ToNot(HaveOccurred())
+					g.Expect(hubRoute.Status.Parents).ToNot(BeEmpty())
+					g.Expect(meta.IsStatusConditionTrue(hubRoute.Status.Parents[0].Conditions, string(gatewayapiv1.RouteConditionAccepted))).To(BeTrue())
+				}, 60*time.Second, 5*time.Second, ctx).Should(Succeed())
+			})
+		})
+
 		When("an HTTPRoute is attached to the Gateway", func() {
 			var httproute *gatewayapiv1.HTTPRoute
 
@@ -123,7 +123,7 @@ var _ = Describe("GatewayClassController", func() {
 			}, TestTimeoutMedium, TestRetryIntervalMedium).Should(BeTrue())
 		})
 
-		It("should NOT accept a gatewayclass that is 'unsupported'", func() {
+		It("should accept a gatewayclass with any name for this controller", func() {
 			gatewayclass.Name = "test-class-name-1"
 			Expect(k8sClient.Create(ctx, gatewayclass)).To(BeNil())
 			createdGatewayclass := &gatewayapiv1.GatewayClass{}
@@ -134,7 +134,7 @@ var _ = Describe("GatewayClassController", func() {
 				return k8sClient.Get(ctx, gatewayclassType, createdGatewayclass)
 			}, TestTimeoutMedium, TestRetryIntervalMedium).Should(BeNil())
 
-			// Status is false
+			// Status is true
 			var condition metav1.Condition
 			Eventually(func() bool {
 				err := k8sClient.Get(ctx, gatewayclassType, createdGatewayclass)
@@ -144,10 +144,10 @@ var _ = Describe("GatewayClassController", func() {
 					Fail("No errors expected")
 				}
 				condition = createdGatewayclass.Status.Conditions[0]
-				return condition.Type == string(gatewayapiv1.GatewayClassConditionStatusAccepted) && condition.Status == metav1.ConditionFalse
+				return condition.Type == string(gatewayapiv1.GatewayClassConditionStatusAccepted) && condition.Status == metav1.ConditionTrue
 			}, TestTimeoutMedium, TestRetryIntervalMedium).Should(BeTrue())
-			Expect(condition.Reason).To(BeEquivalentTo(gatewayapiv1.GatewayClassReasonInvalidParameters))
-			Expect(condition.Message).To(BeEquivalentTo("Invalid Parameters - Unsupported class name test-class-name-1. Must be one of [kuadrant-multi-cluster-gateway-instance-per-cluster]"))
+			Expect(condition.Reason).To(BeEquivalentTo(gatewayapiv1.GatewayClassConditionStatusAccepted))
+			Expect(condition.Message).To(BeEquivalentTo("Handled by kuadrant.io/mgc-gw-controller"))
 		})
 	})
 })
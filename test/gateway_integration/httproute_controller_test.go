@@ -0,0 +1,201 @@
+//go:build integration
+
+package gateway_integration
+
+import (
+	"encoding/json"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	ocmclusterv1beta1 "open-cluster-management.io/api/cluster/v1beta1"
+	ocmworkv1 "open-cluster-management.io/api/work/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	gatewayapiv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	mgcgateway "github.com/Kuadrant/multicluster-gateway-controller/pkg/controllers/gateway"
+	. "github.com/Kuadrant/multicluster-gateway-controller/test/util"
+)
+
+var _ = Describe("HTTPRouteController", func() {
+	Context("testing httproute controller", func() {
+		var gatewayClass *gatewayapiv1.GatewayClass
+		var gateway *gatewayapiv1.Gateway
+		var placementDecision *ocmclusterv1beta1.PlacementDecision
+		var route *gatewayapiv1.HTTPRoute
+
+		BeforeEach(func() {
+			gatewayClass = &gatewayapiv1.GatewayClass{
+				ObjectMeta: metav1.ObjectMeta{Name: "mgc-httproute-test"},
+				Spec:       gatewayapiv1.GatewayClassSpec{ControllerName: mgcgateway.ControllerName},
+			}
+			Expect(k8sClient.Create(ctx, gatewayClass)).To(BeNil())
+
+			placementDecision = &ocmclusterv1beta1.PlacementDecision{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-httproute-placement",
+					Namespace: defaultNS,
+					Labels:    map[string]string{PlacementLabel: "HTTPRouteControllerTest"},
+				},
+			}
+			Expect(k8sClient.Create(ctx, placementDecision)).To(BeNil())
+			placementDecision.Status = ocmclusterv1beta1.PlacementDecisionStatus{
+				Decisions: []ocmclusterv1beta1.ClusterDecision{{ClusterName: nsSpoke1Name, Reason: "test"}},
+			}
+			Expect(k8sClient.Status().Update(ctx, placementDecision)).To(BeNil())
+
+			err := k8sClient.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: nsSpoke1Name}})
+			if err != nil && !k8serrors.IsAlreadyExists(err) {
+				Expect(err).ToNot(HaveOccurred())
+			}
+
+			hostname := gatewayapiv1.Hostname("route.example.com")
+			gateway = &gatewayapiv1.Gateway{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-route-gw",
+					Namespace: defaultNS,
+					Labels:    map[string]string{PlacementLabel: "HTTPRouteControllerTest"},
+				},
+				Spec: gatewayapiv1.GatewaySpec{
+					GatewayClassName: gatewayapiv1.ObjectName(gatewayClass.Name),
+					Listeners: []gatewayapiv1.Listener{{
+						Name:     "api",
+						Port:     80,
+						Protocol: gatewayapiv1.HTTPProtocolType,
+						Hostname: &hostname,
+					}},
+				},
+			}
+			route = &gatewayapiv1.HTTPRoute{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-route",
+					Namespace: defaultNS,
+				},
+				Spec: gatewayapiv1.HTTPRouteSpec{
+					CommonRouteSpec: gatewayapiv1.CommonRouteSpec{
+						ParentRefs: []gatewayapiv1.ParentReference{{Name: gatewayapiv1.ObjectName(gateway.Name)}},
+					},
+					Hostnames: []gatewayapiv1.Hostname{hostname},
+				},
+			}
+		})
+
+		AfterEach(func() {
+			Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, route))).To(Succeed())
+			Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, gateway))).To(Succeed())
+			Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, placementDecision))).To(Succeed())
+			Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, gatewayClass))).To(Succeed())
+		})
+
+		It("should sync the route to the namespace of the downstream gateway and report it accepted", func() {
+			Expect(k8sClient.Create(ctx, gateway)).To(BeNil())
+
+			// the namespace the downstream gateway is placed into on the spoke
+			gatewayWork := &ocmworkv1.ManifestWork{}
+			downstreamNS := ""
+			Eventually(func() error {
+				if err := k8sClient.Get(ctx, types.NamespacedName{Namespace: nsSpoke1Name, Name: "gateway-default-test-route-gw"}, gatewayWork); err != nil {
+					return err
+				}
+				for _, manifest := range gatewayWork.Spec.Workload.Manifests {
+					obj := &unstructured.Unstructured{}
+					if err := json.Unmarshal(manifest.Raw, &obj.Object); err != nil {
+						return err
+					}
+					if obj.GetKind() == "Gateway" {
+						downstreamNS = obj.GetNamespace()
+						return nil
+					}
+				}
+				return fmt.Errorf("expected a downstream gateway in work %s", gatewayWork.Name)
+			}, TestTimeoutMedium, TestRetryIntervalMedium).Should(BeNil())
+			Expect(downstreamNS).ToNot(BeEmpty())
+
+			// Mock: the work agent applies the gateway on the spoke
+			gatewayWork.Status.Conditions = []metav1.Condition{{
+				Type:               ocmworkv1.WorkApplied,
+				Status:             metav1.ConditionTrue,
+				LastTransitionTime: metav1.Now(),
+				Reason:             "AppliedManifestComplete",
+			}}
+			Expect(k8sClient.Status().Update(ctx, gatewayWork)).To(BeNil())
+
+			Expect(k8sClient.Create(ctx, route)).To(BeNil())
+
+			// the route is placed next to the downstream gateway, attached to it
+			routeWork := &ocmworkv1.ManifestWork{}
+			downstreamRoute := &gatewayapiv1.HTTPRoute{}
+			Eventually(func() error {
+				if err := k8sClient.Get(ctx, types.NamespacedName{Namespace: nsSpoke1Name, Name: "httproute-default-test-route"}, routeWork); err != nil {
+					return err
+				}
+				return json.Unmarshal(routeWork.Spec.Workload.Manifests[0].Raw, downstreamRoute)
+			}, TestTimeoutMedium, TestRetryIntervalMedium).Should(BeNil())
+			Expect(downstreamRoute.Namespace).To(Equal(downstreamNS))
+			Expect(downstreamRoute.Spec.ParentRefs).To(HaveLen(1))
+			parentRef := downstreamRoute.Spec.ParentRefs[0]
+			Expect(string(parentRef.Name)).To(Equal(gateway.Name))
+			Expect(parentRef.Namespace).ToNot(BeNil())
+			Expect(string(*parentRef.Namespace)).To(Equal(downstreamNS))
+
+			// Mock: the downstream gateway accepts the route, as the route is in its namespace
+			reported, err := json.Marshal([]gatewayapiv1.RouteParentStatus{{
+				ParentRef:      parentRef,
+				ControllerName: "istio.io/gateway-controller",
+				Conditions: []metav1.Condition{
+					{Type: string(gatewayapiv1.RouteConditionAccepted), Status: metav1.ConditionTrue, Reason: "Accepted", LastTransitionTime: metav1.Now()},
+					{Type: string(gatewayapiv1.RouteConditionResolvedRefs), Status: metav1.ConditionTrue, Reason: "ResolvedRefs", LastTransitionTime: metav1.Now()},
+				},
+			}})
+			Expect(err).NotTo(HaveOccurred())
+			reportedString := string(reported)
+			routeWork.Status = ocmworkv1.ManifestWorkStatus{
+				Conditions: []metav1.Condition{{
+					Type:               ocmworkv1.WorkApplied,
+					Status:             metav1.ConditionTrue,
+					LastTransitionTime: metav1.Now(),
+					Reason:             "AppliedManifestComplete",
+				}},
+				ResourceStatus: ocmworkv1.ManifestResourceStatus{
+					Manifests: []ocmworkv1.ManifestCondition{{
+						ResourceMeta: ocmworkv1.ManifestResourceMeta{
+							Group:     gatewayapiv1.GroupName,
+							Resource:  "httproutes",
+							Name:      downstreamRoute.Name,
+							Namespace: downstreamRoute.Namespace,
+						},
+						StatusFeedbacks: ocmworkv1.StatusFeedbackResult{
+							Values: []ocmworkv1.FeedbackValue{{
+								Name:  "parents",
+								Value: ocmworkv1.FieldValue{Type: ocmworkv1.JsonRaw, JsonRaw: &reportedString},
+							}},
+						},
+						Conditions: []metav1.Condition{},
+					}},
+				},
+			}
+			Expect(k8sClient.Status().Update(ctx, routeWork)).To(BeNil())
+
+			// the acceptance by the downstream gateway is reported on the hub route
+			Eventually(func() error {
+				hubRoute := &gatewayapiv1.HTTPRoute{}
+				if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(route), hubRoute); err != nil {
+					return err
+				}
+				for _, parent := range hubRoute.Status.Parents {
+					if parent.ControllerName == mgcgateway.ControllerName && meta.IsStatusConditionTrue(parent.Conditions, string(gatewayapiv1.RouteConditionAccepted)) {
+						return nil
+					}
+				}
+				return fmt.Errorf("expected route to be accepted got %v", hubRoute.Status.Parents)
+			}, TestTimeoutMedium, TestRetryIntervalMedium).Should(BeNil())
+		})
+	})
+})